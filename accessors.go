@@ -0,0 +1,82 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// AccessorSpec describes a logical field of a type that is read and written through methods
+// rather than directly, for use with RegisterAccessors.
+type AccessorSpec struct {
+	// Name is the name of the logical field, used in error messages.
+	Name string
+	// Getter names a method that takes no arguments and returns the field's value.
+	Getter string
+	// Setter names a method that takes a value of the type Getter returns, and sets the field to it.
+	Setter string
+	// Strategy selects how the field is merged, in the syntax of a `crdt` struct tag, such as "set" or "fww".
+	// Empty means the package's usual rules for the field's type. "lww" is supported without a sibling field.
+	Strategy string
+}
+
+// accessor is an AccessorSpec resolved against the pointer type it is registered for.
+type accessor struct {
+	AccessorSpec
+	getter, setter reflect.Method
+	tag            tagOptions
+}
+
+// RegisterAccessors arranges for values of type t, which may keep its fields private,
+// to be merged field by field through the getters and setters described by fields.
+// For each field, the values returned by a's and b's getters are merged according to the field's strategy,
+// and if the result differs from a's value, it is passed to a's setter. The merged value is a copy,
+// so a getter may return the type's internal storage. Getters may have value or pointer receivers;
+// setters are called on a pointer to a. RegisterAccessors panics if a method is missing or has the wrong type.
+func RegisterAccessors(t reflect.Type, fields []AccessorSpec) {
+	ptr := reflect.PointerTo(t)
+	accessors := make([]accessor, len(fields))
+	for i, spec := range fields {
+		getter, ok := ptr.MethodByName(spec.Getter)
+		if !ok || getter.Type.NumIn() != 1 || getter.Type.NumOut() != 1 {
+			panic(fmt.Sprintf("crdt: %s has no getter %s for field %s", t, spec.Getter, spec.Name))
+		}
+		setter, ok := ptr.MethodByName(spec.Setter)
+		if !ok || setter.Type.NumIn() != 2 || setter.Type.In(1) != getter.Type.Out(0) {
+			panic(fmt.Sprintf("crdt: %s has no setter %s for field %s", t, spec.Setter, spec.Name))
+		}
+		accessors[i] = accessor{spec, getter, setter, parseTag(spec.Strategy)}
+	}
+	registerValue(t, func(a, b reflect.Value) bool {
+		bPtr := reflect.New(t)
+		bPtr.Elem().Set(b)
+		var changed bool
+		for _, acc := range accessors {
+			if acc.merge(a.Addr(), bPtr) {
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+// merge merges the field of *b into the field of *a. It returns true if *a was modified.
+func (acc *accessor) merge(a, b reflect.Value) bool {
+	m := &merger{tag: acc.tag}
+	m.path.pushField(acc.Name)
+	aValue := acc.getter.Func.Call([]reflect.Value{a})[0]
+	bValue := acc.getter.Func.Call([]reflect.Value{b})[0]
+	value := deepCopy(aValue)
+	var changed bool
+	switch {
+	case acc.tag.has("lww"):
+		changed = m.mergeLWW(value, bValue, 0)
+	case acc.tag.has("fww"):
+		changed = m.mergeFWW(value, bValue)
+	default:
+		changed = m.merge(value, bValue)
+	}
+	if changed {
+		acc.setter.Func.Call([]reflect.Value{a, value})
+	}
+	return changed
+}
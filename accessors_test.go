@@ -0,0 +1,59 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// account keeps its fields private, exposing them only through getters and setters.
+type account struct {
+	owner   string
+	balance int
+	tags    []string
+}
+
+func (a account) Owner() string          { return a.owner }
+func (a *account) SetOwner(owner string) { a.owner = owner }
+func (a account) Balance() int           { return a.balance }
+func (a *account) SetBalance(b int)      { a.balance = b }
+func (a *account) Tags() []string        { return a.tags }
+func (a *account) SetTags(tags []string) { a.tags = tags }
+
+func init() {
+	RegisterAccessors(reflect.TypeOf(account{}), []AccessorSpec{
+		{Name: "Owner", Getter: "Owner", Setter: "SetOwner", Strategy: "fww"},
+		{Name: "Balance", Getter: "Balance", Setter: "SetBalance"},
+		{Name: "Tags", Getter: "Tags", Setter: "SetTags", Strategy: "set"},
+	})
+}
+
+func TestRegisterAccessors(t *testing.T) {
+	a := account{owner: "alice", balance: 5, tags: []string{"b"}}
+	b := account{owner: "bob", balance: 8, tags: []string{"a", "b"}}
+	expected := account{owner: "alice", balance: 8, tags: []string{"a", "b"}}
+	if !Merge(&a, b) || !reflect.DeepEqual(a, expected) {
+		t.Errorf("Merge gave %+v, expected %+v", a, expected)
+	}
+	if Merge(&a, b) {
+		t.Errorf("merging b again reported a change")
+	}
+	b.tags[0] = "z"
+	if a.tags[0] != "a" {
+		t.Errorf("merged field shares storage with b")
+	}
+
+	accounts := Join(map[string]account{"x": {balance: 1}}, map[string]account{"x": {owner: "carol"}}).(map[string]account)
+	if got := accounts["x"]; got.owner != "carol" || got.balance != 1 {
+		t.Errorf("Join of maps of accounts gave %+v", got)
+	}
+}
+
+func TestRegisterAccessorsMissingMethod(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterAccessors with a missing setter didn't panic")
+		}
+	}()
+	type opaque struct{ n int }
+	RegisterAccessors(reflect.TypeOf(opaque{}), []AccessorSpec{{Name: "N", Getter: "N", Setter: "SetN"}})
+}
@@ -0,0 +1,133 @@
+package crdt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// MaxFrameSize is the size, in bytes, of the largest encoded state that Sync accepts from a peer.
+// It bounds the memory that a faulty or malicious peer can make Sync allocate.
+var MaxFrameSize = 64 << 20
+
+// ErrFrameTooLarge is returned by Sync when a peer sends a frame larger than MaxFrameSize.
+var ErrFrameTooLarge = errors.New("crdt: sync frame too large")
+
+// writeFrame writes data to w, prefixed with its length.
+func writeFrame(w io.Writer, data []byte) error {
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(data)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readFrame reads a length-prefixed frame written by writeFrame from r.
+// It fails with ErrFrameTooLarge, without reading the frame, if it is larger than MaxFrameSize.
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if uint64(size) > uint64(MaxFrameSize) {
+		return nil, ErrFrameTooLarge
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// exchange writes a frame of data to conn while reading the peer's frame from it,
+// so that two peers exchanging frames over an unbuffered connection don't deadlock.
+func exchange(conn io.ReadWriter, data []byte) ([]byte, error) {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- writeFrame(conn, data)
+	}()
+	peer, err := readFrame(conn)
+	if writeErr := <-errc; err == nil {
+		err = writeErr
+	}
+	return peer, err
+}
+
+// Sync runs one round of anti-entropy with a peer over conn, merging the peer's state into
+// the value pointed to by local. The peer must be running Sync over the other end of conn
+// at the same time, with the same codec and a state of the same type.
+//
+// The peers first exchange fingerprints (see Fingerprint). If they match, the states are
+// taken to be equal and nothing more is sent. Otherwise they exchange their full states,
+// encoded with codec, and each merges the other's into its own, after which both hold the join.
+// Sync returns true if the value pointed to by local was modified, or an error if the exchange fails
+// or the peer's state can't be merged into local's, in which case local may have been partially merged.
+func Sync(local interface{}, conn io.ReadWriter, codec Codec) (bool, error) {
+	localVal := reflect.ValueOf(local)
+	if localVal.Kind() != reflect.Ptr {
+		panic("local must be a pointer")
+	}
+	var fingerprint [8]byte
+	binary.BigEndian.PutUint64(fingerprint[:], Fingerprint(localVal.Elem().Interface()))
+	peerFingerprint, err := exchange(conn, fingerprint[:])
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(fingerprint[:], peerFingerprint) {
+		return false, nil
+	}
+	data, err := codec.Marshal(local)
+	if err != nil {
+		return false, err
+	}
+	peerData, err := exchange(conn, data)
+	if err != nil {
+		return false, err
+	}
+	peer := reflect.New(localVal.Elem().Type())
+	if err := codec.Unmarshal(peerData, peer.Interface()); err != nil {
+		return false, err
+	}
+	return newMerger(nil).run(localVal.Elem(), peer.Elem())
+}
+
+// MapDigest returns the fingerprint (see Fingerprint) of the value of each key of the map m,
+// for a peer to pass to MapDelta. Exchanging digests lets two peers find the keys at which
+// their maps differ, and transfer only those, rather than whole maps whose fingerprints differ.
+func MapDigest(m interface{}) map[interface{}]uint64 {
+	mVal := reflect.ValueOf(m)
+	if mVal.Kind() != reflect.Map {
+		panic("m must be a map")
+	}
+	digest := make(map[interface{}]uint64, mVal.Len())
+	iter := mVal.MapRange()
+	for iter.Next() {
+		digest[iter.Key().Interface()] = Fingerprint(iter.Value().Interface())
+	}
+	return digest
+}
+
+// MapDelta returns a map of the same type as m holding copies of the entries of m that a peer,
+// whose map has the digest remoteDigest (see MapDigest), lacks or holds a different value for.
+// Joining the returned map into the peer's map gives the same result as joining m into it.
+func MapDelta(m interface{}, remoteDigest map[interface{}]uint64) interface{} {
+	mVal := reflect.ValueOf(m)
+	if mVal.Kind() != reflect.Map {
+		panic("m must be a map")
+	}
+	delta := reflect.MakeMap(mVal.Type())
+	iter := mVal.MapRange()
+	for iter.Next() {
+		remote, ok := remoteDigest[iter.Key().Interface()]
+		if ok && remote == Fingerprint(iter.Value().Interface()) {
+			continue
+		}
+		delta.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+	}
+	return delta.Interface()
+}
@@ -0,0 +1,109 @@
+package crdt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestSync(t *testing.T) {
+	type A struct {
+		Name   string
+		Counts map[string]int
+	}
+	for _, codec := range []Codec{JSONCodec, GobCodec} {
+		x := A{"x", map[string]int{"a": 1, "b": 5}}
+		y := A{"y", map[string]int{"a": 3, "c": 1}}
+		expected := A{"y", map[string]int{"a": 3, "b": 5, "c": 1}}
+
+		syncBoth := func() (bool, bool) {
+			xConn, yConn := net.Pipe()
+			defer xConn.Close()
+			defer yConn.Close()
+			type result struct {
+				changed bool
+				err     error
+			}
+			done := make(chan result)
+			go func() {
+				changed, err := Sync(&y, yConn, codec)
+				done <- result{changed, err}
+			}()
+			xChanged, err := Sync(&x, xConn, codec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			yResult := <-done
+			if yResult.err != nil {
+				t.Fatal(yResult.err)
+			}
+			return xChanged, yResult.changed
+		}
+
+		if xChanged, yChanged := syncBoth(); !xChanged || !yChanged {
+			t.Errorf("%T: first Sync changed (%v, %v), expected (true, true)", codec, xChanged, yChanged)
+		}
+		if !reflect.DeepEqual(x, expected) || !reflect.DeepEqual(y, expected) {
+			t.Errorf("%T: after Sync states were %v and %v, expected %v", codec, x, y, expected)
+		}
+		if xChanged, yChanged := syncBoth(); xChanged || yChanged {
+			t.Errorf("%T: second Sync changed (%v, %v), expected (false, false)", codec, xChanged, yChanged)
+		}
+	}
+}
+
+// scriptedPeer is a connection to a peer that sends the frames in its Reader and ignores what it is sent.
+type scriptedPeer struct {
+	io.Reader
+	io.Writer
+}
+
+func TestSyncBadPeer(t *testing.T) {
+	var huge bytes.Buffer
+	binary.Write(&huge, binary.BigEndian, uint32(1<<31))
+	local := map[string]interface{}{"k": "s"}
+	if _, err := Sync(&local, scriptedPeer{&huge, io.Discard}, JSONCodec); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("Sync with a peer sending a huge frame = %v, expected ErrFrameTooLarge", err)
+	}
+
+	var conflicting bytes.Buffer
+	writeFrame(&conflicting, make([]byte, 8))
+	writeFrame(&conflicting, []byte(`{"k":1}`))
+	var mergeErr *MergeError
+	if _, err := Sync(&local, scriptedPeer{&conflicting, io.Discard}, JSONCodec); !errors.As(err, &mergeErr) {
+		t.Errorf("Sync with a peer sending a conflicting state = %v, expected a *MergeError", err)
+	}
+}
+
+func TestMapDelta(t *testing.T) {
+	type entry struct {
+		Count int
+		Tags  []string `crdt:"set"`
+	}
+	local := map[string]entry{
+		"same":   {1, []string{"x"}},
+		"ahead":  {5, []string{"x", "y"}},
+		"behind": {1, nil},
+		"new":    {2, nil},
+	}
+	remote := map[string]entry{
+		"same":   {1, []string{"x"}},
+		"ahead":  {3, []string{"x"}},
+		"behind": {4, nil},
+		"old":    {1, nil},
+	}
+	delta := MapDelta(local, MapDigest(remote)).(map[string]entry)
+	if _, ok := delta["same"]; ok || len(delta) != 3 {
+		t.Errorf("MapDelta returned keys %v, expected ahead, behind and new", reflect.ValueOf(delta).MapKeys())
+	}
+	if got, expected := Join(remote, delta), Join(remote, local); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Join(remote, delta) = %v, expected %v", got, expected)
+	}
+	if delta := MapDelta(local, MapDigest(local)).(map[string]entry); len(delta) != 0 {
+		t.Errorf("MapDelta against its own digest returned %v", delta)
+	}
+}
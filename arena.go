@@ -0,0 +1,57 @@
+package crdt
+
+import "reflect"
+
+// An Arena holds the storage for the results of a batch of joins made by JoinArena, which is reused
+// by the next batch once Free is called, rather than left for the garbage collector.
+// It suits high-frequency joins whose results are only needed briefly, such as within a request.
+// The zero value is an empty arena. An Arena is not safe for concurrent use.
+type Arena struct {
+	// used holds the result buffers handed out since the last Free, and free those available for reuse,
+	// each as a pointer to a value of its type.
+	used, free map[reflect.Type][]reflect.Value
+}
+
+// NewArena returns an empty Arena.
+func NewArena() *Arena {
+	return new(Arena)
+}
+
+// JoinArena returns the least upper bound of (a, b), like Join, but draws the storage for the result
+// from arena. Both a and b must be mergeable values of the same type.
+//
+// The result is valid until arena.Free is called, after which it (and any map reachable from it)
+// must no longer be used. Because map storage is reused, an empty map in the result may be non-nil
+// where Join would return nil.
+func JoinArena(arena *Arena, a, b interface{}) interface{} {
+	return joinBuffered(a, b, arena.get).Elem().Interface()
+}
+
+// get returns a pointer to an empty value of type t, reusing a freed buffer if there is one.
+func (arena *Arena) get(t reflect.Type) reflect.Value {
+	if arena.used == nil {
+		arena.used = make(map[reflect.Type][]reflect.Value)
+		arena.free = make(map[reflect.Type][]reflect.Value)
+	}
+	var buf reflect.Value
+	if free := arena.free[t]; len(free) > 0 {
+		buf = free[len(free)-1]
+		arena.free[t] = free[:len(free)-1]
+	} else {
+		buf = reflect.New(t)
+	}
+	arena.used[t] = append(arena.used[t], buf)
+	return buf
+}
+
+// Free releases the storage of every result returned by JoinArena with this arena since the last Free,
+// for reuse by later joins. The results must no longer be used.
+func (arena *Arena) Free() {
+	for t, used := range arena.used {
+		for _, buf := range used {
+			reset(buf.Elem())
+		}
+		arena.free[t] = append(arena.free[t], used...)
+		arena.used[t] = used[:0]
+	}
+}
@@ -0,0 +1,75 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJoinArena(t *testing.T) {
+	type A struct {
+		I int
+		M map[string]int
+	}
+	arena := NewArena()
+	for batch := 0; batch < 3; batch++ {
+		x := JoinArena(arena, A{1, map[string]int{"a": 1}}, A{2, map[string]int{"b": batch}}).(A)
+		y := JoinArena(arena, A{3, map[string]int{"a": 3}}, A{0, map[string]int{"a": 2}}).(A)
+		z := JoinArena(arena, A{}, A{}).(A)
+		if expected := (A{2, map[string]int{"a": 1, "b": batch}}); !reflect.DeepEqual(x, expected) {
+			t.Errorf("batch %d: JoinArena gave %#v, expected %#v", batch, x, expected)
+		}
+		if expected := (A{3, map[string]int{"a": 3}}); !reflect.DeepEqual(y, expected) {
+			t.Errorf("batch %d: JoinArena gave %#v, expected %#v", batch, y, expected)
+		}
+		if z.I != 0 || len(z.M) != 0 {
+			t.Errorf("batch %d: JoinArena of zero values gave %#v, expected an empty value", batch, z)
+		}
+		x.M["c"] = 1
+		if _, ok := y.M["c"]; ok {
+			t.Errorf("batch %d: results of one batch share storage", batch)
+		}
+		arena.Free()
+	}
+}
+
+func TestJoinArenaReset(t *testing.T) {
+	type A struct {
+		When time.Time
+		List sharingList
+	}
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	arena := NewArena()
+	for batch := 0; batch < 3; batch++ {
+		a, b := A{When: when}, A{List: sharingList{1, 2}}
+		result := JoinArena(arena, a, b).(A)
+		if expected := (A{when, sharingList{1, 2}}); !reflect.DeepEqual(result, expected) {
+			t.Fatalf("batch %d: JoinArena gave %#v, expected %#v", batch, result, expected)
+		}
+		result.List[0] = 10
+		if b.List[0] != 1 {
+			t.Errorf("batch %d: modifying the result of JoinArena modified its operand: %#v", batch, b)
+		}
+		arena.Free()
+	}
+}
+
+func BenchmarkJoinArena(b *testing.B) {
+	x, y := benchmarkMap(64), benchmarkMap(64)
+	arena := NewArena()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		JoinArena(arena, x, y)
+		if i%100 == 99 {
+			arena.Free()
+		}
+	}
+}
+
+func BenchmarkJoinBatch(b *testing.B) {
+	x, y := benchmarkMap(64), benchmarkMap(64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Join(x, y)
+	}
+}
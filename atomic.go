@@ -0,0 +1,133 @@
+package crdt
+
+import (
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// The typed atomics in sync/atomic are structs with unexported fields. They are merged by loading
+// both values and storing the greater into a with CompareAndSwap, so merges are safe to run
+// concurrently with other atomic operations on either value. As usual, zero is the bottom value:
+// any non-zero value is greater than it, even a negative one. atomic.Bool merges as logical or,
+// and atomic.Value joins the dynamic values it holds, with an empty atomic.Value as bottom.
+//
+// To read b atomically, b must be addressable, which it is when merged with MergePtr.
+// Merge takes b by value, so b is copied non-atomically before the merge ever sees it.
+func init() {
+	registerAtomicInt[int32, atomic.Int32]()
+	registerAtomicInt[int64, atomic.Int64]()
+	registerAtomicInt[uint32, atomic.Uint32]()
+	registerAtomicInt[uint64, atomic.Uint64]()
+	registerValue(reflect.TypeOf(atomic.Bool{}), mergeAtomicBool)
+	registerValue(reflect.TypeOf(atomic.Value{}), mergeAtomicValue)
+}
+
+// addr returns a pointer to v's value, or to a copy of it if v isn't addressable.
+func addr(v reflect.Value) interface{} {
+	if v.CanAddr() {
+		return v.Addr().Interface()
+	}
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+	return c.Interface()
+}
+
+// registerAtomicInt registers a merge function for the atomic integer type A, whose values are of type T.
+func registerAtomicInt[T int32 | int64 | uint32 | uint64, A any, P interface {
+	*A
+	Load() T
+	CompareAndSwap(old, new T) bool
+}]() {
+	registerValue(reflect.TypeOf((*A)(nil)).Elem(), func(a, b reflect.Value) bool {
+		value := P(a.Addr().Interface().(*A))
+		other := P(addr(b).(*A)).Load()
+		for {
+			current := value.Load()
+			if other == 0 || (current != 0 && other <= current) {
+				return false
+			}
+			if value.CompareAndSwap(current, other) {
+				return true
+			}
+		}
+	})
+}
+
+func mergeAtomicBool(a, b reflect.Value) bool {
+	value := a.Addr().Interface().(*atomic.Bool)
+	return addr(b).(*atomic.Bool).Load() && value.CompareAndSwap(false, true)
+}
+
+func mergeAtomicValue(a, b reflect.Value) bool {
+	value := a.Addr().Interface().(*atomic.Value)
+	other := addr(b).(*atomic.Value).Load()
+	if other == nil {
+		return false
+	}
+	for {
+		current := value.Load()
+		joined := joinInterface(current, other)
+		if reflect.DeepEqual(joined, current) {
+			return false
+		}
+		if value.CompareAndSwap(current, joined) {
+			return true
+		}
+	}
+}
+
+// A sync/atomic.Pointer[T] is merged like a *T, with a nil pointer as the bottom value, but copy-on-write:
+// both pointers are loaded atomically, the value a points to is copied and b's value merged into the copy,
+// and the copy is stored into a by compare-and-swap, retrying if a was stored to in the meantime.
+// The values the pointers point to are never modified, so goroutines that load a concurrently with
+// a merge see either its old value or its merged value, never a partly merged one.
+
+// isAtomicPointer returns true if t is an instantiation of sync/atomic.Pointer.
+func isAtomicPointer(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == "sync/atomic" && strings.HasPrefix(t.Name(), "Pointer[")
+}
+
+// atomicElem returns T, for the atomic.Pointer[T] type t.
+func atomicElem(t reflect.Type) reflect.Type {
+	load, _ := reflect.PointerTo(t).MethodByName("Load")
+	return load.Type.Out(0).Elem()
+}
+
+// atomicLoad returns the pointer held by the atomic.Pointer v, loaded atomically if v is addressable.
+func atomicLoad(v reflect.Value) reflect.Value {
+	return reflect.ValueOf(addr(v)).MethodByName("Load").Call(nil)[0]
+}
+
+// atomicStore atomically stores the pointer ptr into the addressable atomic.Pointer v.
+func atomicStore(v, ptr reflect.Value) {
+	v.Addr().MethodByName("Store").Call([]reflect.Value{ptr})
+}
+
+// mergeAtomicPointer merges the atomic.Pointer b into the atomic.Pointer a, copy-on-write.
+// It returns true if the value of a was modified.
+func (m *merger) mergeAtomicPointer(a, b reflect.Value) bool {
+	bPtr := atomicLoad(b)
+	compareAndSwap := a.Addr().MethodByName("CompareAndSwap")
+	for {
+		aPtr := atomicLoad(a)
+		if bPtr.IsNil() || aPtr.Pointer() == bPtr.Pointer() {
+			m.decide(false, a)
+			return false
+		}
+		merged := reflect.New(bPtr.Type().Elem())
+		if aPtr.IsNil() {
+			m.chargeCopy(bPtr)
+			copyInto(merged.Elem(), bPtr.Elem())
+			m.decide(true, a)
+		} else {
+			copyInto(merged.Elem(), aPtr.Elem())
+			if !m.merge(merged.Elem(), bPtr.Elem()) {
+				return false
+			}
+		}
+		if compareAndSwap.Call([]reflect.Value{aPtr, merged})[0].Bool() {
+			return true
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type atomicState struct {
+	Hits    atomic.Int64
+	Bytes   atomic.Uint32
+	Enabled atomic.Bool
+	Name    atomic.Value
+}
+
+func newAtomicState(hits int64, bytes uint32, enabled bool, name string) *atomicState {
+	state := new(atomicState)
+	state.Hits.Store(hits)
+	state.Bytes.Store(bytes)
+	state.Enabled.Store(enabled)
+	if name != "" {
+		state.Name.Store(name)
+	}
+	return state
+}
+
+func TestMergeAtomic(t *testing.T) {
+	testMerge := func(a, b *atomicState, hits int64, bytes uint32, enabled bool, name interface{}) {
+		MergePtr(a, b)
+		if a.Hits.Load() != hits || a.Bytes.Load() != bytes || a.Enabled.Load() != enabled || a.Name.Load() != name {
+			t.Errorf("After merge was {%d %d %v %v}, expected {%d %d %v %v}",
+				a.Hits.Load(), a.Bytes.Load(), a.Enabled.Load(), a.Name.Load(), hits, bytes, enabled, name)
+		}
+	}
+	testMerge(newAtomicState(1, 5, false, ""), newAtomicState(2, 3, true, "b"), 2, 5, true, "b")
+	testMerge(newAtomicState(2, 3, true, "b"), newAtomicState(1, 5, false, ""), 2, 5, true, "b")
+	testMerge(newAtomicState(0, 0, false, "a"), newAtomicState(-1, 0, false, "b"), -1, 0, false, "b")
+	testMerge(newAtomicState(-1, 0, false, "b"), newAtomicState(0, 0, false, "a"), -1, 0, false, "b")
+}
+
+func TestMergeAtomicConcurrent(t *testing.T) {
+	x := newAtomicState(1, 10, false, "x")
+	y := newAtomicState(5, 2, true, "y")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			MergePtr(x, y)
+		}()
+		go func() {
+			defer wg.Done()
+			MergePtr(y, x)
+		}()
+	}
+	wg.Wait()
+	// One more round in each direction guarantees convergence regardless of interleaving.
+	MergePtr(x, y)
+	MergePtr(y, x)
+	for _, state := range []*atomicState{x, y} {
+		if state.Hits.Load() != 5 || state.Bytes.Load() != 10 || !state.Enabled.Load() || state.Name.Load() != "y" {
+			t.Errorf("After concurrent merges was {%d %d %v %v}, expected {5 10 true y}",
+				state.Hits.Load(), state.Bytes.Load(), state.Enabled.Load(), state.Name.Load())
+		}
+	}
+}
+
+type atomicSubState struct {
+	Count map[string]int
+	Tags  []string `crdt:"set"`
+}
+
+type atomicPointerState struct {
+	Sub atomic.Pointer[atomicSubState]
+}
+
+func TestMergeAtomicPointer(t *testing.T) {
+	var a, b atomicPointerState
+	if Merge(&a, &b) {
+		t.Errorf("Merge of nil pointers changed a")
+	}
+	b.Sub.Store(&atomicSubState{Count: map[string]int{"x": 1}, Tags: []string{"b"}})
+	if !Merge(&a, &b) {
+		t.Errorf("Merge into nil pointer changed = false, expected true")
+	}
+	if a.Sub.Load() == b.Sub.Load() || !reflect.DeepEqual(a.Sub.Load(), b.Sub.Load()) {
+		t.Errorf("After merge into nil pointer a is %+v, expected a copy of %+v", a.Sub.Load(), b.Sub.Load())
+	}
+
+	before := a.Sub.Load()
+	b.Sub.Store(&atomicSubState{Count: map[string]int{"x": 0, "y": 2}, Tags: []string{"a"}})
+	if !Merge(&a, &b) {
+		t.Errorf("Merge changed = false, expected true")
+	}
+	expected := &atomicSubState{Count: map[string]int{"x": 1, "y": 2}, Tags: []string{"a", "b"}}
+	if got := a.Sub.Load(); got == before || !reflect.DeepEqual(got, expected) {
+		t.Errorf("After merge a is %+v, expected a new %+v", got, expected)
+	}
+	if want := (&atomicSubState{Count: map[string]int{"x": 1}, Tags: []string{"b"}}); !reflect.DeepEqual(before, want) {
+		t.Errorf("Merge modified the old value to %+v", before)
+	}
+	if Merge(&a, &b) {
+		t.Errorf("Repeated merge changed a")
+	}
+	if err := Validate(reflect.TypeOf(&a).Elem()); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestMergeAtomicPointerConcurrent(t *testing.T) {
+	const writers = 8
+	var a atomicPointerState
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			// Every loaded value is fully merged: its set holds a tag for each of its counts.
+			if sub := a.Sub.Load(); sub != nil && len(sub.Count) != len(sub.Tags) {
+				t.Errorf("Torn read: %+v", sub)
+				return
+			}
+		}
+	}()
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var b atomicPointerState
+			name := fmt.Sprint(i)
+			b.Sub.Store(&atomicSubState{Count: map[string]int{name: i + 1}, Tags: []string{name}})
+			Merge(&a, &b)
+		}(i)
+	}
+	wg.Wait()
+	close(done)
+	readers.Wait()
+	got := a.Sub.Load()
+	if len(got.Count) != writers || len(got.Tags) != writers {
+		t.Errorf("After concurrent merges a is %+v, expected all %d writers' values", got, writers)
+	}
+	for i := 0; i < writers; i++ {
+		if got.Count[fmt.Sprint(i)] != i+1 {
+			t.Errorf("Count[%d] = %d, expected %d", i, got.Count[fmt.Sprint(i)], i+1)
+		}
+	}
+}
+
+func TestCloneAtomicPointer(t *testing.T) {
+	var a atomicPointerState
+	a.Sub.Store(&atomicSubState{Count: map[string]int{"x": 1}})
+	c := Clone(&a).(*atomicPointerState)
+	if c.Sub.Load() == a.Sub.Load() || !reflect.DeepEqual(c.Sub.Load(), a.Sub.Load()) {
+		t.Errorf("Clone shares or differs from the original's value")
+	}
+}
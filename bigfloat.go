@@ -0,0 +1,48 @@
+package crdt
+
+import (
+	"math/big"
+	"reflect"
+)
+
+// *big.Float values are merged to the greater of (a, b) per Cmp, with nil as the bottom value.
+// A big.Float's precision affects the results of arithmetic on it, so rather than keep whichever
+// operand's precision happens to win, the result is always a fresh big.Float with a precision
+// chosen by RegisterBigFloat: by default, the greater of the operands' precisions.
+func init() {
+	RegisterBigFloat(0)
+}
+
+// RegisterBigFloat sets the precision of the *big.Float values produced by merges to prec.
+// If prec is 0, the result has the greater of the precisions of the values being merged,
+// which represents either value exactly. Otherwise, b's value is rounded to prec bits
+// (to nearest even) before it is compared with a's, and a's value is rounded too if its precision differs,
+// so that merging the same value again changes nothing.
+//
+// Like Register, it replaces the merge function of *big.Float for every merge in the process,
+// so it should be called once, during initialization, rather than to configure a particular merge.
+func RegisterBigFloat(prec uint) {
+	registerValue(reflect.TypeOf((*big.Float)(nil)), func(a, b reflect.Value) bool {
+		value, other := a.Addr().Interface().(**big.Float), b.Interface().(*big.Float)
+		if other == nil {
+			return false
+		}
+		target := prec
+		if target == 0 {
+			target = other.Prec()
+			if *value != nil && (*value).Prec() > target {
+				target = (*value).Prec()
+			}
+		}
+		rounded := new(big.Float).SetPrec(target).Set(other)
+		switch {
+		case *value == nil || (*value).Cmp(rounded) < 0:
+			*value = rounded
+		case (*value).Prec() != target:
+			*value = new(big.Float).SetPrec(target).Set(*value)
+		default:
+			return false
+		}
+		return true
+	})
+}
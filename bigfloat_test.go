@@ -0,0 +1,49 @@
+package crdt
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestMergeBigFloat(t *testing.T) {
+	type accumulator struct {
+		Max *big.Float
+	}
+	third := new(big.Float).SetPrec(200).Quo(big.NewFloat(1), big.NewFloat(3))
+	for _, test := range []struct {
+		name     string
+		prec     uint
+		a, b     *big.Float
+		expected *big.Float
+	}{
+		{"greater wins", 0, big.NewFloat(0.25), third, third},
+		{"equal values", 0, big.NewFloat(0.5), new(big.Float).SetPrec(100).SetFloat64(0.5), new(big.Float).SetPrec(100).SetFloat64(0.5)},
+		{"nil is bottom", 0, nil, big.NewFloat(-1), big.NewFloat(-1)},
+		{"fixed precision", 64, big.NewFloat(0.25), third, new(big.Float).SetPrec(64).Set(third)},
+		{"fixed precision rounds a", 24, big.NewFloat(0.5), big.NewFloat(0.25), new(big.Float).SetPrec(24).SetFloat64(0.5)},
+	} {
+		RegisterBigFloat(test.prec)
+		ab := Join(accumulator{test.a}, accumulator{test.b}).(accumulator).Max
+		ba := Join(accumulator{test.b}, accumulator{test.a}).(accumulator).Max
+		for _, result := range []*big.Float{ab, ba} {
+			if result.Cmp(test.expected) != 0 || result.Prec() != test.expected.Prec() {
+				t.Errorf("%s: Join = %s (prec %d), expected %s (prec %d)", test.name,
+					result.Text('g', 20), result.Prec(), test.expected.Text('g', 20), test.expected.Prec())
+			}
+			if result == test.a || result == test.b {
+				t.Errorf("%s: Join shares a *big.Float with its inputs", test.name)
+			}
+		}
+	}
+
+	// Merging a value again changes nothing, even once it has been rounded.
+	RegisterBigFloat(24)
+	value := accumulator{third}
+	if !Merge(&value, accumulator{third}) {
+		t.Errorf("Merge of a value with a different precision didn't round it")
+	}
+	if changed, err := MergeWith(&value, accumulator{third}, WithSelfCheck()); changed || err != nil {
+		t.Errorf("Merging the same value again = %v, %v, expected false, nil", changed, err)
+	}
+	RegisterBigFloat(0)
+}
@@ -0,0 +1,44 @@
+package crdt
+
+import (
+	"math/big"
+	"reflect"
+)
+
+// *big.Int values are numbers by default, merged to the greater of (a, b) per Cmp, with nil as the bottom value.
+// A field tagged `crdt:"bitset"` holds a *big.Int used as a set of bits instead, merged by bitwise OR,
+// so that the result has every bit set in either value; its values should not be negative.
+// As with other tags, the tag of a map or slice field applies to its values.
+// Either way, the result never shares storage with b.
+
+var bigIntType = reflect.TypeOf((*big.Int)(nil))
+
+func init() {
+	registerValue(bigIntType, func(a, b reflect.Value) bool {
+		value, other := a.Addr().Interface().(**big.Int), b.Interface().(*big.Int)
+		if other == nil || (*value != nil && (*value).Cmp(other) >= 0) {
+			return false
+		}
+		*value = new(big.Int).Set(other)
+		return true
+	})
+}
+
+// mergeBitset sets the *big.Int a to the bitwise OR of the *big.Int values (a, b).
+// It returns true if the value of a was modified.
+func mergeBitset(a, b reflect.Value) bool {
+	value, other := a.Addr().Interface().(**big.Int), b.Interface().(*big.Int)
+	if other == nil {
+		return false
+	}
+	if *value == nil {
+		*value = new(big.Int).Set(other)
+		return true
+	}
+	union := new(big.Int).Or(*value, other)
+	if union.Cmp(*value) == 0 {
+		return false
+	}
+	*value = union
+	return true
+}
@@ -0,0 +1,60 @@
+package crdt
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestMergeBigInt(t *testing.T) {
+	a := big.NewInt(5)
+	b := big.NewInt(12)
+	if !Merge(&a, b) || a.Int64() != 12 {
+		t.Errorf("Merge(5, 12) gave %v, expected 12", a)
+	}
+	if a == b {
+		t.Errorf("Merge shared b's storage")
+	}
+	if Merge(&a, big.NewInt(7)) || a.Int64() != 12 {
+		t.Errorf("Merge(12, 7) gave %v, expected 12 unchanged", a)
+	}
+	var n *big.Int
+	if Merge(&a, n) || a.Int64() != 12 {
+		t.Errorf("Merge(12, nil) gave %v, expected 12 unchanged", a)
+	}
+}
+
+func TestMergeBitset(t *testing.T) {
+	type A struct {
+		Max  *big.Int
+		Bits *big.Int            `crdt:"bitset"`
+		Sets map[string]*big.Int `crdt:"bitset"`
+	}
+	bits := func(i ...int) *big.Int {
+		v := new(big.Int)
+		for _, bit := range i {
+			v.SetBit(v, bit, 1)
+		}
+		return v
+	}
+	a := A{big.NewInt(5), bits(0, 2), map[string]*big.Int{"x": bits(1), "y": bits(70)}}
+	b := A{big.NewInt(6), bits(1, 2, 100), map[string]*big.Int{"x": bits(3), "z": bits(4)}}
+	expected := A{big.NewInt(6), bits(0, 1, 2, 100), map[string]*big.Int{"x": bits(1, 3), "y": bits(70), "z": bits(4)}}
+	for _, joined := range []A{Join(a, b).(A), Join(b, a).(A)} {
+		if !reflect.DeepEqual(joined, expected) {
+			t.Errorf("Join gave %v, expected %v", joined, expected)
+		}
+	}
+	if Merge(&expected, A{nil, bits(1), map[string]*big.Int{"x": bits(3)}}) {
+		t.Errorf("merging a subset of the bits reported a change")
+	}
+	if err := Validate(reflect.TypeOf(a)); err != nil {
+		t.Errorf("Validate(%T) = %v", a, err)
+	}
+	type bad struct {
+		Bits int `crdt:"bitset"`
+	}
+	if err := Validate(reflect.TypeOf(bad{})); err == nil {
+		t.Errorf("Validate of a bitset int gave no error")
+	}
+}
@@ -0,0 +1,37 @@
+package crdt
+
+import "reflect"
+
+// Bitmap is the subset of the method set of a compressed bitmap type,
+// such as *roaring.Bitmap, that RegisterBitmap needs. B is the bitmap's struct type.
+type Bitmap[B any] interface {
+	*B
+	// Or sets the bitmap to its union with other.
+	Or(other *B)
+	// Clone returns a copy of the bitmap that shares no storage with it.
+	Clone() *B
+	// GetCardinality returns the number of values in the bitmap.
+	GetCardinality() uint64
+}
+
+// RegisterBitmap registers a merge function for the bitmap type P, a pointer to B,
+// that merges bitmaps as grow-only sets by calling Or. A nil bitmap is the empty set,
+// and a nil a is set to a clone of b, so that a never shares b's storage.
+// For example, to merge fields of type *roaring.Bitmap:
+//
+//	crdt.RegisterBitmap[roaring.Bitmap]()
+func RegisterBitmap[B any, P Bitmap[B]]() {
+	registerValue(reflect.TypeOf(P(nil)), func(a, b reflect.Value) bool {
+		value, other := a.Addr().Interface().(*P), b.Interface().(P)
+		if other == nil || other.GetCardinality() == 0 || *value == other {
+			return false
+		}
+		if *value == nil {
+			*value = P(other.Clone())
+			return true
+		}
+		before := (*value).GetCardinality()
+		(*value).Or((*B)(other))
+		return (*value).GetCardinality() != before
+	})
+}
@@ -0,0 +1,76 @@
+package crdt
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// stubBitmap stands in for a compressed bitmap type from another package.
+type stubBitmap struct {
+	values map[uint32]bool
+	ors    int
+}
+
+func newStubBitmap(values ...uint32) *stubBitmap {
+	b := &stubBitmap{values: make(map[uint32]bool)}
+	for _, v := range values {
+		b.values[v] = true
+	}
+	return b
+}
+
+func (b *stubBitmap) Or(other *stubBitmap) {
+	b.ors++
+	for v := range other.values {
+		b.values[v] = true
+	}
+}
+
+func (b *stubBitmap) Clone() *stubBitmap {
+	clone := newStubBitmap()
+	for v := range b.values {
+		clone.values[v] = true
+	}
+	return clone
+}
+
+func (b *stubBitmap) GetCardinality() uint64 {
+	return uint64(len(b.values))
+}
+
+func (b *stubBitmap) slice() []uint32 {
+	var values []uint32
+	for v := range b.values {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values
+}
+
+func init() {
+	RegisterBitmap[stubBitmap]()
+}
+
+func TestRegisterBitmap(t *testing.T) {
+	type sets struct {
+		Seen map[string]*stubBitmap
+	}
+	a := sets{map[string]*stubBitmap{"x": newStubBitmap(1, 2)}}
+	b := sets{map[string]*stubBitmap{"x": newStubBitmap(2, 3), "y": newStubBitmap(4)}}
+	if !Merge(&a, b) {
+		t.Errorf("Merge reported no change")
+	}
+	if got := a.Seen["x"].slice(); !reflect.DeepEqual(got, []uint32{1, 2, 3}) {
+		t.Errorf("a.Seen[x] = %v, expected [1 2 3]", got)
+	}
+	if a.Seen["x"].ors != 1 {
+		t.Errorf("Or was called %d times, expected once", a.Seen["x"].ors)
+	}
+	if got := a.Seen["y"].slice(); !reflect.DeepEqual(got, []uint32{4}) || a.Seen["y"] == b.Seen["y"] {
+		t.Errorf("a.Seen[y] = %v, expected a copy of [4]", got)
+	}
+	if Merge(&a, b) {
+		t.Errorf("merging b again reported a change")
+	}
+}
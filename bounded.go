@@ -0,0 +1,58 @@
+package crdt
+
+import (
+	"reflect"
+	"sort"
+)
+
+// WithMaxMapSize bounds the size of the maps whose values are structs, or pointers to structs,
+// with a field named timestampField: once merging into such a map leaves it with more than n entries,
+// the entries with the least timestamps are evicted until n remain. Entries with equal timestamps
+// are evicted in the order of their keys, as Walk visits them. Maps of other types are not bounded.
+//
+// This is a bounded approximation of a CRDT, for caches and the like, and not a CRDT: eviction discards
+// data, so the result is no longer an upper bound of the values merged, and an evicted entry returns
+// if it is merged in again. Replicas converge only on the entries that are new enough to survive
+// every replica's evictions.
+func WithMaxMapSize(n int, timestampField string) Option {
+	return func(c *config) {
+		c.maxMapSize = n
+		c.mapTimestamp = timestampField
+	}
+}
+
+// evict evicts the entries of the map a with the least timestamps until it is no larger
+// than the size set by WithMaxMapSize, if a's values have the timestamp field.
+// It returns the number of entries evicted.
+func (m *merger) evict(a reflect.Value) int {
+	if m.maxMapSize <= 0 || a.Len() <= m.maxMapSize {
+		return 0
+	}
+	elem := a.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return 0
+	}
+	if _, ok := elem.FieldByName(m.mapTimestamp); !ok {
+		return 0
+	}
+	timestamp := func(key reflect.Value) reflect.Value {
+		value := reflect.Indirect(a.MapIndex(key))
+		if !value.IsValid() {
+			return reflect.Zero(elem).FieldByName(m.mapTimestamp)
+		}
+		return value.FieldByName(m.mapTimestamp)
+	}
+	keys := a.MapKeys()
+	m.sortKeys(keys)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return compare(timestamp(keys[i]), timestamp(keys[j])) < 0
+	})
+	evicted := keys[:len(keys)-m.maxMapSize]
+	for _, key := range evicted {
+		a.SetMapIndex(key, reflect.Value{})
+	}
+	return len(evicted)
+}
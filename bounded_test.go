@@ -0,0 +1,76 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithMaxMapSize(t *testing.T) {
+	type entry struct {
+		Value   string
+		Updated int64
+	}
+	type cache struct {
+		Entries map[string]entry
+		Ptrs    map[string]*entry
+		Other   map[string]int
+	}
+	a := cache{
+		Entries: map[string]entry{"a": {"a", 1}, "b": {"b", 5}},
+		Ptrs:    map[string]*entry{"a": {"a", 1}, "b": {"b", 5}},
+		Other:   map[string]int{"a": 1, "b": 2},
+	}
+	b := cache{
+		Entries: map[string]entry{"c": {"c", 3}, "d": {"d", 4}, "a": {"a2", 6}},
+		Ptrs:    map[string]*entry{"c": {"c", 3}, "d": {"d", 4}},
+		Other:   map[string]int{"c": 3, "d": 4},
+	}
+	changed, err := MergeWith(&a, b, WithMaxMapSize(2, "Updated"))
+	if !changed || err != nil {
+		t.Fatalf("MergeWith = (%v, %v), expected (true, nil)", changed, err)
+	}
+	if expected := (map[string]entry{"a": {"a2", 6}, "b": {"b", 5}}); !reflect.DeepEqual(a.Entries, expected) {
+		t.Errorf("bounded map merged to %v, expected %v", a.Entries, expected)
+	}
+	if expected := (map[string]*entry{"b": {"b", 5}, "d": {"d", 4}}); !reflect.DeepEqual(a.Ptrs, expected) {
+		t.Errorf("bounded map of pointers merged to %v, expected %v", a.Ptrs, expected)
+	}
+	if len(a.Other) != 4 {
+		t.Errorf("map without timestamps was bounded to %v", a.Other)
+	}
+
+	// Entries with equal timestamps are evicted in key order.
+	ties := map[string]entry{"x": {"x", 1}, "y": {"y", 1}}
+	MergeWith(&ties, map[string]entry{"w": {"w", 1}}, WithMaxMapSize(2, "Updated"))
+	if expected := (map[string]entry{"x": {"x", 1}, "y": {"y", 1}}); !reflect.DeepEqual(ties, expected) {
+		t.Errorf("bounded map with tied timestamps merged to %v, expected %v", ties, expected)
+	}
+}
+
+func TestWithMaxMapSizeEvictIncoming(t *testing.T) {
+	type entry struct{ TS int }
+	a := map[string]entry{"x": {5}, "y": {6}}
+	changed, err := MergeWith(&a, map[string]entry{"z": {1}}, WithMaxMapSize(2, "TS"))
+	if changed || err != nil {
+		t.Errorf("MergeWith of an entry evicted at once = (%v, %v), expected (false, nil)", changed, err)
+	}
+	if expected := (map[string]entry{"x": {5}, "y": {6}}); !reflect.DeepEqual(a, expected) {
+		t.Errorf("bounded map merged to %v, expected %v", a, expected)
+	}
+	changed, _ = MergeWith(&a, map[string]entry{"z": {9}}, WithMaxMapSize(2, "TS"))
+	if !changed {
+		t.Errorf("MergeWith of an entry that evicted another = false, expected true")
+	}
+}
+
+func TestWithMaxMapSizeSelfCheck(t *testing.T) {
+	type entry struct{ TS int }
+	a := map[string]entry{"x": {5}, "y": {6}}
+	b := map[string]entry{"z": {1}, "w": {7}}
+	if _, err := MergeWith(&a, b, WithMaxMapSize(2, "TS"), WithSelfCheck()); err != nil {
+		t.Errorf("MergeWith with WithMaxMapSize and WithSelfCheck = %v, expected no error", err)
+	}
+	if expected := (map[string]entry{"y": {6}, "w": {7}}); !reflect.DeepEqual(a, expected) {
+		t.Errorf("bounded map merged to %v, expected %v", a, expected)
+	}
+}
@@ -0,0 +1,86 @@
+package crdt
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrAllocBudget is the error wrapped by the *MergeError that a merge with WithAllocBudget fails with
+// once merging would exceed its budget.
+var ErrAllocBudget = errors.New("allocation budget exceeded")
+
+// allocBudget tracks the allocations of a merge with WithAllocBudget. It is shared by the mergers
+// that copy values for the merge.
+type allocBudget struct {
+	limit, used int
+}
+
+// WithAllocBudget limits the storage that the merge may add to a, such as to protect a replica from
+// a peer that sends it a huge state. The budget is counted in units of map entries, slice elements,
+// and pointers: each map key new to a costs one, plus the cost of its value, and so on. Once merging
+// a part of b would exceed n units, the merge fails with a *MergeError wrapping ErrAllocBudget,
+// leaving a partially merged. Since a merge only ever moves a up, a is still a valid state, holding
+// the parts of b merged before the budget ran out. Slices merged as whole values, such as sets,
+// are charged the cost of b's slice whenever they are merged, since they may be rebuilt from it.
+func WithAllocBudget(n int) Option {
+	return func(c *config) {
+		c.budget = &allocBudget{limit: n}
+	}
+}
+
+// charge spends cost units of the merge's allocation budget, if it has one,
+// and fails the merge if that would exceed it. Merges that copy values aren't charged.
+func (m *merger) charge(cost int) {
+	if m.budget == nil || m.copying || cost <= 0 {
+		return
+	}
+	if m.budget.used+cost > m.budget.limit {
+		panic(&MergeError{Err: ErrAllocBudget})
+	}
+	m.budget.used += cost
+}
+
+// chargeCopy charges the merge's allocation budget, if it has one, for a copy of v.
+func (m *merger) chargeCopy(v reflect.Value) {
+	if m.budget != nil {
+		m.charge(allocSize(v, make(map[copiedPointer]bool)))
+	}
+}
+
+// allocSize returns the number of map entries, slice elements, and pointers in v, for WithAllocBudget.
+// seen holds the pointers already counted, to count shared pointers and cycles once.
+func allocSize(v reflect.Value, seen map[copiedPointer]bool) int {
+	var size int
+	switch v.Kind() {
+	case reflect.Map:
+		size = v.Len()
+		iter := v.MapRange()
+		for iter.Next() {
+			size += allocSize(iter.Key(), seen) + allocSize(iter.Value(), seen)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			size = v.Len()
+		}
+		if !isOrdered(v.Type().Elem().Kind()) {
+			for i := 0; i < v.Len(); i++ {
+				size += allocSize(v.Index(i), seen)
+			}
+		}
+	case reflect.Ptr:
+		key := copiedPointer{v.Type(), v.Pointer()}
+		if !v.IsNil() && !seen[key] {
+			seen[key] = true
+			size = 1 + allocSize(v.Elem(), seen)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			size = allocSize(v.Elem(), seen)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			size += allocSize(v.Field(i), seen)
+		}
+	}
+	return size
+}
@@ -0,0 +1,49 @@
+package crdt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWithAllocBudget(t *testing.T) {
+	type state struct {
+		Items map[string][]int
+		Tags  []string `crdt:"set"`
+	}
+	b := state{Items: map[string][]int{}}
+	for i := 0; i < 100; i++ {
+		b.Items[fmt.Sprint(i)] = []int{i, i}
+	}
+	a := state{Items: map[string][]int{"0": {0, 0}}}
+	_, err := MergeWith(&a, b, WithAllocBudget(30))
+	if !errors.Is(err, ErrAllocBudget) {
+		t.Fatalf("MergeWith over budget = %v, expected ErrAllocBudget", err)
+	}
+	// Each new key costs 1, plus 2 for its slice's elements.
+	if n := len(a.Items); n != 11 {
+		t.Errorf("MergeWith over budget merged %d keys, expected 11", n)
+	}
+	for key, value := range a.Items {
+		if !reflect.DeepEqual(value, b.Items[key]) {
+			t.Errorf("partially merged key %s = %v, expected %v", key, value, b.Items[key])
+		}
+	}
+
+	// Merging again, with a budget large enough for the rest, completes the merge.
+	if _, err := MergeWith(&a, b, WithAllocBudget(300)); err != nil {
+		t.Fatalf("MergeWith within budget = %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("MergeWith within budget gave %v, expected %v", a, b)
+	}
+	if _, err := MergeWith(&a, b, WithAllocBudget(0)); err != nil {
+		t.Errorf("merging an already merged state with a zero budget = %v", err)
+	}
+
+	tags := state{Tags: []string{"a", "b", "c"}}
+	if _, err := MergeWith(&state{}, tags, WithAllocBudget(2)); !errors.Is(err, ErrAllocBudget) {
+		t.Errorf("merging a set over budget = %v, expected ErrAllocBudget", err)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build crdtcbor
+
+// CBOR support depends on github.com/fxamacker/cbor/v2, so it is only built with the crdtcbor build tag.
+
+package crdt
+
+import (
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// cborEncMode encodes in the core deterministic encoding of RFC 8949, section 4.2.1:
+// integers, lengths and floats take their shortest forms, and map keys are sorted bytewise,
+// so that equal states always encode to the same bytes.
+var cborEncMode = func() cbor.EncMode {
+	mode, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return mode
+}()
+
+// MarshalCBOR returns the canonical CBOR encoding of v, in the form expected by UnmarshalMergeCBOR.
+// Equal values encode to equal bytes, whatever the order in which their maps were built,
+// so the encoding can be compared, hashed or signed directly. Struct fields are encoded as maps
+// keyed by field name, following the `cbor` struct tags of package cbor.
+func MarshalCBOR(v interface{}) ([]byte, error) {
+	return cborEncMode.Marshal(v)
+}
+
+// UnmarshalMergeCBOR decodes the CBOR-encoded state in data and merges it into the value pointed to by a.
+// It returns true if the value of a was modified.
+func UnmarshalMergeCBOR(data []byte, a interface{}) (bool, error) {
+	aVal := reflect.ValueOf(a)
+	if aVal.Kind() != reflect.Ptr {
+		panic("a must be a pointer")
+	}
+	b := reflect.New(aVal.Elem().Type())
+	if err := cbor.Unmarshal(data, b.Interface()); err != nil {
+		return false, err
+	}
+	return new(merger).run(aVal.Elem(), b.Elem())
+}
+
+// CBORCodec is a Codec using the canonical CBOR encoding of MarshalCBOR.
+var CBORCodec Codec = cborCodec{}
+
+type cborCodec struct{}
+
+func (cborCodec) Marshal(v interface{}) ([]byte, error) {
+	return MarshalCBOR(v)
+}
+
+func (cborCodec) Unmarshal(data []byte, v interface{}) error {
+	return cbor.Unmarshal(data, v)
+}
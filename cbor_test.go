@@ -0,0 +1,76 @@
+//go:build crdtcbor
+
+package crdt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+type cborState struct {
+	Counts map[string]uint64
+	Tags   []string `crdt:"set"`
+	Owner  string
+}
+
+func TestMarshalCBORRoundTrip(t *testing.T) {
+	state := cborState{Counts: map[string]uint64{"a": 1, "b": 1 << 40}, Tags: []string{"x", "y"}, Owner: "alice"}
+	data, err := MarshalCBOR(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded cborState
+	if err := CBORCodec.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, state) {
+		t.Errorf("round trip gave %+v, expected %+v", decoded, state)
+	}
+}
+
+func TestMarshalCBORCanonical(t *testing.T) {
+	a := map[string]int{}
+	b := map[string]int{}
+	for i, key := range []string{"bb", "a", "c"} {
+		a[key] = i
+	}
+	for _, key := range []string{"c", "a", "bb"} {
+		b[key] = a[key]
+	}
+	aData, err := MarshalCBOR(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bData, err := MarshalCBOR(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(aData, bData) {
+		t.Errorf("equal maps encoded differently: %x and %x", aData, bData)
+	}
+	// Keys sort bytewise by their encodings, so shorter keys come first: {"a": 1, "c": 2, "bb": 0}.
+	if expected := "a361610161630262626200"; hex.EncodeToString(aData) != expected {
+		t.Errorf("MarshalCBOR gave %x, expected %s", aData, expected)
+	}
+}
+
+func TestUnmarshalMergeCBOR(t *testing.T) {
+	a := cborState{Counts: map[string]uint64{"a": 3}, Tags: []string{"x"}, Owner: "alice"}
+	data, err := MarshalCBOR(cborState{Counts: map[string]uint64{"a": 1, "b": 2}, Tags: []string{"y"}, Owner: "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := UnmarshalMergeCBOR(data, &a)
+	if err != nil || !changed {
+		t.Fatalf("UnmarshalMergeCBOR returned %v, %v", changed, err)
+	}
+	expected := cborState{Counts: map[string]uint64{"a": 3, "b": 2}, Tags: []string{"x", "y"}, Owner: "bob"}
+	if !reflect.DeepEqual(a, expected) {
+		t.Errorf("UnmarshalMergeCBOR gave %+v, expected %+v", a, expected)
+	}
+	if _, err := UnmarshalMergeCBOR([]byte{0xff}, &a); err == nil {
+		t.Errorf("UnmarshalMergeCBOR of invalid data didn't fail")
+	}
+}
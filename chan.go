@@ -0,0 +1,78 @@
+package crdt
+
+import "reflect"
+
+// Channels can't be merged by default. With WithChannels, a channel field tagged `crdt:"chan=Buffer"`,
+// where Buffer is a sibling field of type []T tagged `crdt:"set"` for a channel of T, is treated as
+// a snapshot of the values buffered in it, which are merged into Buffer: Buffer becomes the sorted,
+// deduplicated union of both sides' Buffers and of the values buffered in both sides' channels.
+// The channels themselves are left as they are, holding the same values in the same order,
+// and T must have a total ordering.
+//
+// This suits states that record pending work as a buffered channel, but it has sharp edges:
+//   - A channel's buffered values can only be read by receiving them, so the merge receives each value
+//     and sends it back. Nothing else may send on or receive from the channels during the merge:
+//     a concurrent receiver can take values before they are sent back, and a concurrent sender
+//     can fill the channel so that sending them back blocks until there is room.
+//   - The channels must be bidirectional, to be read and refilled.
+//   - Buffer only grows, like any set merged by the package: values received from a channel
+//     after a merge remain in Buffer, and must be removed from it by other means, such as a tombstone.
+//   - Values sent on a channel after the merge aren't in Buffer until the next merge.
+
+// WithChannels enables merging channel fields tagged `crdt:"chan=Buffer"`, as described above.
+// Without it, merging a channel is an error, as it is for any other type the package can't merge.
+func WithChannels() Option {
+	return func(c *config) {
+		c.channels = true
+	}
+}
+
+// mergeChan merges the values buffered in the channels a and b into the field named buffer of the struct
+// aStruct, whose field a is. b's buffer is merged into it as a field of its own. It returns true if
+// the buffer was modified.
+func (m *merger) mergeChan(aStruct, a, b reflect.Value, buffer string) bool {
+	if !m.channels {
+		panic(mergeErrorf("channels are only merged with WithChannels"))
+	}
+	if a.Type().ChanDir() != reflect.BothDir {
+		panic(mergeErrorf("channel of type %s is not bidirectional", a.Type()))
+	}
+	field, ok := aStruct.Type().FieldByName(buffer)
+	if ok && len(field.Index) == 1 {
+		opts := fieldTags(aStruct.Type())[field.Index[0]]
+		ok = field.Type == reflect.SliceOf(a.Type().Elem()) && opts.has("set") && opts["set"] == ""
+	}
+	if !ok {
+		panic(mergeErrorf("chan buffer %s is not a field of type %s tagged set", buffer, reflect.SliceOf(a.Type().Elem())))
+	}
+	aBuffer := aStruct.Field(field.Index[0])
+	if !isOrdered(a.Type().Elem().Kind()) {
+		panic(mergeErrorf("chan elements of type %s have no total ordering", a.Type().Elem()))
+	}
+	values := reflect.MakeSlice(aBuffer.Type(), 0, 0)
+	values = reflect.Append(values, snapshotChan(a)...)
+	values = reflect.Append(values, snapshotChan(b)...)
+	changed := mergeSetUnion(aBuffer, values)
+	m.decide(changed, aBuffer)
+	return changed
+}
+
+// snapshotChan returns the values buffered in the channel ch, which it receives and sends back in order.
+func snapshotChan(ch reflect.Value) []reflect.Value {
+	if ch.IsNil() {
+		return nil
+	}
+	n := ch.Len()
+	values := make([]reflect.Value, 0, n)
+	for i := 0; i < n; i++ {
+		value, ok := ch.TryRecv()
+		if !ok {
+			break
+		}
+		values = append(values, value)
+	}
+	for _, value := range values {
+		ch.Send(value)
+	}
+	return values
+}
@@ -0,0 +1,60 @@
+package crdt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type pending struct {
+	Work   chan int `crdt:"chan=Buffer"`
+	Buffer []int    `crdt:"set"`
+}
+
+// drain receives every value buffered in ch.
+func drain(ch chan int) []int {
+	var values []int
+	for len(ch) > 0 {
+		values = append(values, <-ch)
+	}
+	return values
+}
+
+func TestMergeChan(t *testing.T) {
+	a := pending{make(chan int, 4), []int{1}}
+	b := pending{make(chan int, 4), []int{5}}
+	a.Work <- 3
+	a.Work <- 2
+	b.Work <- 2
+	b.Work <- 4
+	changed, err := MergeWith(&a, b, WithChannels())
+	if !changed || err != nil {
+		t.Fatalf("MergeWith = (%v, %v), expected (true, nil)", changed, err)
+	}
+	if expected := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(a.Buffer, expected) {
+		t.Errorf("merged Buffer = %v, expected %v", a.Buffer, expected)
+	}
+	if changed, _ := MergeWith(&a, b, WithChannels()); changed {
+		t.Errorf("merging b again reported a change")
+	}
+	if values := drain(a.Work); !reflect.DeepEqual(values, []int{3, 2}) {
+		t.Errorf("after merging, a's channel held %v, expected [3 2]", values)
+	}
+	if values := drain(b.Work); !reflect.DeepEqual(values, []int{2, 4}) {
+		t.Errorf("after merging, b's channel held %v, expected [2 4]", values)
+	}
+}
+
+func TestMergeChanDisabled(t *testing.T) {
+	a, b := pending{make(chan int, 1), nil}, pending{make(chan int, 1), nil}
+	if _, err := MergeWith(&a, b); err == nil || !strings.Contains(err.Error(), "WithChannels") {
+		t.Errorf("MergeWith without WithChannels = %v, expected an error", err)
+	}
+	if err := Validate(reflect.TypeOf(a)); err == nil {
+		t.Errorf("Validate(%T) gave no error", a)
+	}
+	var untagged struct{ C chan int }
+	if _, err := MergeWith(&untagged, untagged, WithChannels()); err == nil {
+		t.Errorf("merging an untagged channel gave no error")
+	}
+}
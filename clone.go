@@ -0,0 +1,150 @@
+package crdt
+
+import (
+	"reflect"
+	"sync"
+)
+
+// deepCopy returns a copy of v that shares no maps, slices, or pointers with it.
+// Unexported struct fields are copied shallowly. Pointers that are shared within v, including cycles,
+// are shared the same way within the copy.
+func deepCopy(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	copyInto(c, v)
+	return c
+}
+
+// copyInto sets dst, which must be settable and zero, to a deep copy of src.
+func copyInto(dst, src reflect.Value) {
+	new(copier).copyInto(dst, src)
+}
+
+// copier carries the pointers copied so far through a deep copy.
+type copier struct {
+	// copies maps each pointer already copied to its copy.
+	copies map[copiedPointer]reflect.Value
+}
+
+// copiedPointer identifies a pointer by its type as well as its address,
+// since a struct and its first field have the same address.
+type copiedPointer struct {
+	t reflect.Type
+	p uintptr
+}
+
+func (c *copier) copyInto(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		iter := src.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(iter.Key(), c.copy(iter.Value()))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			c.copyInto(dst.Index(i), src.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copyInto(dst.Index(i), src.Index(i))
+		}
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		key := copiedPointer{src.Type(), src.Pointer()}
+		if copied, ok := c.copies[key]; ok {
+			dst.Set(copied)
+			return
+		}
+		if c.copies == nil {
+			c.copies = make(map[copiedPointer]reflect.Value)
+		}
+		ptr := reflect.New(src.Type().Elem())
+		c.copies[key] = ptr
+		dst.Set(ptr)
+		c.copyInto(ptr.Elem(), src.Elem())
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(c.copy(src.Elem()))
+	case reflect.Struct:
+		if isAtomicPointer(src.Type()) {
+			if ptr := atomicLoad(src); !ptr.IsNil() {
+				atomicStore(dst, c.copy(ptr))
+			}
+			return
+		}
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				dst.Field(i).Set(reflect.Zero(dst.Field(i).Type()))
+				c.copyInto(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// copy returns a deep copy of v, sharing the pointers already copied.
+func (c *copier) copy(v reflect.Value) reflect.Value {
+	copied := reflect.New(v.Type()).Elem()
+	c.copyInto(copied, v)
+	return copied
+}
+
+// Clone returns a deep copy of a that shares no maps, slices, or pointers with it,
+// so that the copy can be merged into without affecting a. Unexported struct fields are copied shallowly.
+func Clone(a interface{}) interface{} {
+	return deepCopy(reflect.ValueOf(a)).Interface()
+}
+
+// delegatingTypes caches the result of delegates for each type it has classified.
+var delegatingTypes sync.Map // map[reflect.Type]bool
+
+// delegates returns true if values of type t may contain values whose merges are delegated to code
+// outside the package's own rules, a Merger or registered MergeFunc, or interfaces that may hold them.
+// Such merges may keep references to the values they are given rather than copying them.
+func delegates(t reflect.Type) bool {
+	if d, ok := delegatingTypes.Load(t); ok {
+		return d.(bool)
+	}
+	d := typeDelegates(t, make(map[reflect.Type]bool))
+	delegatingTypes.Store(t, d)
+	return d
+}
+
+// typeDelegates computes delegates(t). seen holds the types already being checked, to stop at recursive types.
+func typeDelegates(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if reflect.PointerTo(t).Implements(mergerType) || registered(t) != nil {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if typeDelegates(t.Field(i).Type, seen) {
+				return true
+			}
+		}
+	case reflect.Map:
+		return typeDelegates(t.Key(), seen) || typeDelegates(t.Elem(), seen)
+	case reflect.Slice, reflect.Array, reflect.Ptr:
+		return typeDelegates(t.Elem(), seen)
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopy(t *testing.T) {
+	type Inner struct {
+		M map[string][]int
+	}
+	type A struct {
+		P     *Inner
+		S     []Inner
+		Arr   [1]map[int]int
+		Iface interface{}
+	}
+	value := A{
+		P:     &Inner{map[string][]int{"a": {1}}},
+		S:     []Inner{{map[string][]int{"b": {2}}}},
+		Arr:   [1]map[int]int{{1: 1}},
+		Iface: map[int]int{2: 2},
+	}
+	c := deepCopy(reflect.ValueOf(value)).Interface().(A)
+	if !reflect.DeepEqual(c, value) {
+		t.Fatalf("deepCopy(%#v) = %#v", value, c)
+	}
+	c.P.M["a"][0] = 10
+	c.S[0].M["b"] = nil
+	c.Arr[0][1] = 10
+	c.Iface.(map[int]int)[2] = 10
+	if value.P.M["a"][0] != 1 || value.S[0].M["b"] == nil || value.Arr[0][1] != 1 || value.Iface.(map[int]int)[2] != 2 {
+		t.Errorf("mutating the copy changed the original: %#v", value)
+	}
+}
+
+func TestClone(t *testing.T) {
+	a := map[string][]int{"a": {1, 2}}
+	c := Clone(a).(map[string][]int)
+	if !reflect.DeepEqual(c, a) {
+		t.Fatalf("Clone(%#v) = %#v", a, c)
+	}
+	Merge(&c, map[string][]int{"b": {3}})
+	c["a"][0] = 10
+	if len(a) != 1 || a["a"][0] != 1 {
+		t.Errorf("merging into Clone(a) modified a: %#v", a)
+	}
+}
+
+// sharingList is a Merger that takes the other value's slice rather than copying it.
+type sharingList []int
+
+func (l *sharingList) Merge(other interface{}) bool {
+	o := other.(sharingList)
+	if len(o) <= len(*l) {
+		return false
+	}
+	*l = o
+	return true
+}
+
+// comparableList is a Comparable ordered by length.
+type comparableList struct {
+	Items []int
+}
+
+func (l *comparableList) Compare(other interface{}) int {
+	return len(l.Items) - len(other.(comparableList).Items)
+}
+
+func TestJoinIndependent(t *testing.T) {
+	type item struct {
+		ID   string
+		Tags []string
+	}
+	type A struct {
+		Nested  map[string]map[string]int
+		Lists   map[string][]int
+		Items   []item `crdt:"set=ID"`
+		Ptr     *map[string]int
+		Any     interface{}
+		Shared  sharingList
+		Ordered comparableList
+	}
+	newA := func() A {
+		m := map[string]int{"p": 1}
+		return A{
+			Nested:  map[string]map[string]int{"n": {"k": 1}},
+			Lists:   map[string][]int{"l": {1}},
+			Items:   []item{{"i", []string{"t"}}},
+			Ptr:     &m,
+			Any:     map[string]int{"x": 1},
+			Shared:  sharingList{1},
+			Ordered: comparableList{[]int{1}},
+		}
+	}
+	for _, swap := range []bool{false, true} {
+		a, b := newA(), A{}
+		if swap {
+			a, b = b, a
+		}
+		joined := Join(a, b).(A)
+		joined.Nested["n"]["k"] = 2
+		joined.Lists["l"][0] = 2
+		joined.Items[0].Tags[0] = "u"
+		(*joined.Ptr)["p"] = 2
+		joined.Any.(map[string]int)["x"] = 2
+		joined.Shared[0] = 2
+		joined.Ordered.Items[0] = 2
+		if input := Join(a, b).(A); !reflect.DeepEqual(input, newA()) {
+			t.Errorf("modifying Join's result changed its operands to %#v", input)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package crdt
+
+import "reflect"
+
+// MergeCollectErrors sets the value of a to the least upper bound of (a, b), like Merge,
+// but rather than stopping at the first part of the values that can't be merged, it leaves a's value
+// for that part, records a *MergeError for it, and carries on with the rest of the merge.
+// It returns true if the value of a was modified, and the errors in the order they were found.
+// This salvages as much as possible from values that are partially incompatible,
+// such as imports of data written by other versions of a program.
+func MergeCollectErrors(a, b interface{}) (bool, []error) {
+	var errs []error
+	changed, err := MergeWith(a, b, func(c *config) {
+		c.errs = &errs
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return changed, errs
+}
+
+// fail records err at the current path if the merge collects errors, and panics with it otherwise.
+// When it returns, the caller must skip the part of the value that failed.
+// Errors are not recorded by merges that only copy a value, since the merge that follows reports them.
+func (m *merger) fail(err *MergeError) {
+	if m.errs == nil {
+		panic(err)
+	}
+	if !m.copying {
+		err.Path = m.path.String()
+		*m.errs = append(*m.errs, err)
+	}
+}
+
+// collect is deferred by merge when the merge collects errors. It recovers a MergeError raised
+// while merging b into a, records it, and restores the path and tag the merge started with,
+// so that the merge can carry on with a's siblings. a is reported as unchanged;
+// a merge that only copies b into a sets a to b instead, so that the copy is faithful.
+func (m *merger) collect(changed *bool, a, b reflect.Value, depth int, tag tagOptions) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	mergeErr, ok := r.(*MergeError)
+	if !ok {
+		panic(r)
+	}
+	if mergeErr.Path == "" {
+		mergeErr.Path = m.path.String()
+	}
+	m.path = m.path[:depth]
+	m.tag = tag
+	if m.copying {
+		a.Set(b)
+		*changed = true
+		return
+	}
+	*m.errs = append(*m.errs, mergeErr)
+	*changed = false
+}
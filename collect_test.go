@@ -0,0 +1,60 @@
+package crdt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMergeCollectErrors(t *testing.T) {
+	type inner struct {
+		Callback func()
+		Count    int
+	}
+	type partial struct {
+		Name    string
+		Handler func()
+		Items   map[string]inner
+		Events  chan int
+		Total   int
+		private int
+	}
+	callback := func() {}
+	events := make(chan int)
+	a := partial{Name: "a", Events: events, Items: map[string]inner{"x": {Callback: callback, Count: 1}}, Total: 1}
+	b := partial{Name: "b", Handler: func() {}, Events: make(chan int), Items: map[string]inner{"x": {Count: 2}}, Total: 2, private: 2}
+	changed, errs := MergeCollectErrors(&a, b)
+	if !changed {
+		t.Errorf("MergeCollectErrors reported no change")
+	}
+	if a.Name != "b" || a.Total != 2 || a.Items["x"].Count != 2 {
+		t.Errorf("MergeCollectErrors didn't merge the good fields: %+v", a)
+	}
+	if a.Handler != nil || a.Events != events || a.private != 0 || a.Items["x"].Callback == nil {
+		t.Errorf("MergeCollectErrors didn't leave a's value for the bad fields: %+v", a)
+	}
+	var paths []string
+	for _, err := range errs {
+		var mergeErr *MergeError
+		if !errors.As(err, &mergeErr) {
+			t.Fatalf("error %v is not a *MergeError", err)
+		}
+		paths = append(paths, mergeErr.Path)
+	}
+	expected := []string{"Handler", "Items[x].Callback", "Events", ""}
+	if len(paths) != len(expected) {
+		t.Fatalf("MergeCollectErrors reported errors at %q, expected %q", paths, expected)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("error %d is at %q, expected %q", i, paths[i], expected[i])
+		}
+	}
+}
+
+func TestMergeCollectErrorsNone(t *testing.T) {
+	a := map[string]int{"x": 1}
+	changed, errs := MergeCollectErrors(&a, map[string]int{"x": 2, "y": 1})
+	if !changed || errs != nil || a["x"] != 2 || a["y"] != 1 {
+		t.Errorf("MergeCollectErrors gave %v, %v, %v", a, changed, errs)
+	}
+}
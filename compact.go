@@ -0,0 +1,73 @@
+package crdt
+
+import "reflect"
+
+// compacter carries the pointers compacted so far through a Compact.
+type compacter struct {
+	// seen holds the pointers already compacted, so that shared and cyclic pointers are compacted once.
+	seen map[copiedPointer]bool
+}
+
+// compact removes the zero-valued and empty map entries from the maps in v, which must be addressable,
+// where tag holds the `crdt` tag options in effect for v. It returns true if any were removed.
+func (c *compacter) compact(v reflect.Value, tag tagOptions) bool {
+	t := v.Type()
+	if reflect.PointerTo(t).Implements(mergerType) || registered(t) != nil || reflect.PointerTo(t).Implements(comparableType) {
+		return false
+	}
+	var changed bool
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			break
+		}
+		key := copiedPointer{t, v.Pointer()}
+		if c.seen[key] {
+			break
+		}
+		if c.seen == nil {
+			c.seen = make(map[copiedPointer]bool)
+		}
+		c.seen[key] = true
+		changed = c.compact(v.Elem(), tag)
+	case reflect.Struct:
+		tags := fieldTags(t)
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath == "" && c.compact(v.Field(i), tags[i]) {
+				changed = true
+			}
+		}
+	case reflect.Map:
+		if tag.has("2pset") {
+			break
+		}
+		value := reflect.New(t.Elem()).Elem()
+		for _, key := range v.MapKeys() {
+			value.Set(v.MapIndex(key))
+			if c.compact(value, tag) {
+				v.SetMapIndex(key, value)
+				changed = true
+			}
+			if isZero(value) || value.Kind() == reflect.Map && value.Len() == 0 {
+				v.SetMapIndex(key, reflect.Value{})
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// Compact removes the entries whose values are zero or empty maps from the maps in the value pointed to by a,
+// including maps nested in structs, maps, and pointers, and returns true if it removed any.
+// Zero is the bottom value, so a missing entry means the same as a zero one, and Compact leaves a
+// Equal to what it was; but entries whose values are all zero, such as counts that were never raised
+// or nested maps that were emptied, otherwise accumulate over many merges.
+// Values merged by a Merger, registered MergeFunc, or Comparable are left as they are.
+// Cyclic values are compacted safely: each pointer is followed once.
+func Compact(a interface{}) bool {
+	v := reflect.ValueOf(a)
+	if v.Kind() != reflect.Ptr {
+		panic("a must be a pointer")
+	}
+	return new(compacter).compact(v.Elem(), nil)
+}
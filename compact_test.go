@@ -0,0 +1,58 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// compactCode is an enum-like named integer, used as a map key.
+type compactCode int
+
+const (
+	codeOK compactCode = iota
+	codeNotFound
+	codeTimeout
+)
+
+func TestCompact(t *testing.T) {
+	a := map[compactCode]int{codeOK: 3, codeNotFound: 0}
+	b := map[compactCode]int{codeNotFound: 0, codeTimeout: 2}
+	merged := Join(a, b).(map[compactCode]int)
+	uncompacted := Clone(merged).(map[compactCode]int)
+	if !Compact(&merged) {
+		t.Errorf("Compact reported no change")
+	}
+	expected := map[compactCode]int{codeOK: 3, codeTimeout: 2}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Compact gave %v, expected %v", merged, expected)
+	}
+	if !Equal(merged, uncompacted) {
+		t.Errorf("Compact(%v) = %v, which isn't Equal to it", uncompacted, merged)
+	}
+	if Compact(&merged) {
+		t.Errorf("compacting again reported a change")
+	}
+	// Merging after compaction gives the same result as merging before.
+	Merge(&merged, map[compactCode]int{codeNotFound: 1})
+	Merge(&uncompacted, map[compactCode]int{codeNotFound: 1})
+	if !Equal(merged, uncompacted) || merged[codeNotFound] != 1 {
+		t.Errorf("merging after Compact gave %v, expected a value Equal to %v", merged, uncompacted)
+	}
+}
+
+func TestCompactNested(t *testing.T) {
+	type state struct {
+		Counts map[compactCode]map[string]int
+		Ptr    *map[compactCode]int
+	}
+	ptr := map[compactCode]int{codeOK: 0}
+	a := state{
+		Counts: map[compactCode]map[string]int{codeOK: {"x": 0}, codeTimeout: {"x": 1, "y": 0}},
+		Ptr:    &ptr,
+	}
+	Compact(&a)
+	expected := state{Counts: map[compactCode]map[string]int{codeTimeout: {"x": 1}}, Ptr: &map[compactCode]int{}}
+	if !reflect.DeepEqual(a, expected) {
+		t.Errorf("Compact gave %#v, expected %#v", a, expected)
+	}
+}
@@ -0,0 +1,50 @@
+package crdt
+
+import "reflect"
+
+// MergeCompatible merges the struct b into the struct pointed to by a, where a and b may be
+// of different struct types, such as two versions of a type during a schema migration.
+// Fields are matched by name, and fields with the same name and type in both are merged
+// according to a's field tags; fields that only one of the types has, or that have different types,
+// are ignored. It returns true if the value of a was modified, or an error if some part of the values
+// can't be merged, in which case a may have been partially merged.
+// b may be a struct or a pointer to one, which is read in place and not modified.
+//
+// Only structs that are merged fieldwise can be merged with a different type. If a's type is merged
+// as a whole, as a record (with a `crdt:"primary"` field) or by a Merger, registered MergeFunc, or Comparable,
+// the fields b lacks would take part in the merge as zero values, so MergeCompatible returns an error instead.
+func MergeCompatible(a, b interface{}) (bool, error) {
+	aVal := reflect.ValueOf(a)
+	bVal := reflect.Indirect(reflect.ValueOf(b))
+	if aVal.Kind() != reflect.Ptr || aVal.Elem().Kind() != reflect.Struct {
+		panic("a must be a pointer to a struct")
+	}
+	if bVal.Kind() != reflect.Struct {
+		panic("b must be a struct")
+	}
+	aVal = aVal.Elem()
+	if aVal.Type() == bVal.Type() {
+		return new(merger).run(aVal, bVal)
+	}
+	if t := aVal.Type(); recordFields(t) != nil || registered(t) != nil ||
+		reflect.PointerTo(t).Implements(mergerType) || reflect.PointerTo(t).Implements(comparableType) {
+		return false, mergeErrorf("%s is merged as a whole, so it can't be merged with a %s", t, bVal.Type())
+	}
+	return new(merger).run(aVal, project(bVal, aVal.Type()))
+}
+
+// project returns a value of the struct type t holding the fields of the struct v that have the same
+// names and types in t. t's other fields hold zero values, which merging leaves unchanged.
+func project(v reflect.Value, t reflect.Type) reflect.Value {
+	projected := reflect.New(t).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if vField, ok := v.Type().FieldByName(field.Name); ok && len(vField.Index) == 1 && vField.Type == field.Type {
+			projected.Field(i).Set(v.Field(vField.Index[0]))
+		}
+	}
+	return projected
+}
@@ -0,0 +1,66 @@
+package crdt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type profileV1 struct {
+	Name  string
+	Score int
+	Tags  []string `crdt:"set"`
+	Email string
+}
+
+type profileV2 struct {
+	Name    string
+	Score   int
+	Tags    []string `crdt:"set"`
+	Email   []string
+	Country string
+}
+
+func TestMergeCompatible(t *testing.T) {
+	older := profileV1{Name: "a", Score: 5, Tags: []string{"x"}, Email: "a@example.com"}
+	newer := profileV2{Name: "b", Score: 3, Tags: []string{"y"}, Email: []string{"b@example.com"}, Country: "NZ"}
+
+	v1 := older
+	changed, err := MergeCompatible(&v1, newer)
+	expected1 := profileV1{Name: "b", Score: 5, Tags: []string{"x", "y"}, Email: "a@example.com"}
+	if err != nil || !changed || !reflect.DeepEqual(v1, expected1) {
+		t.Errorf("MergeCompatible into the older type gave %+v, %v, %v; expected %+v", v1, changed, err, expected1)
+	}
+
+	v2 := newer
+	changed, err = MergeCompatible(&v2, &older)
+	expected2 := profileV2{Name: "b", Score: 5, Tags: []string{"x", "y"}, Email: []string{"b@example.com"}, Country: "NZ"}
+	if err != nil || !changed || !reflect.DeepEqual(v2, expected2) {
+		t.Errorf("MergeCompatible into the newer type gave %+v, %v, %v; expected %+v", v2, changed, err, expected2)
+	}
+
+	if changed, err := MergeCompatible(&v2, older); changed || err != nil {
+		t.Errorf("merging the older value again gave %v, %v", changed, err)
+	}
+}
+
+func TestMergeCompatibleRecord(t *testing.T) {
+	type recordV1 struct {
+		Score int `crdt:"primary"`
+	}
+	type recordV2 struct {
+		Score int `crdt:"primary"`
+		Note  string
+	}
+	a := recordV2{Score: 1, Note: "keep me"}
+	var mergeErr *MergeError
+	if _, err := MergeCompatible(&a, recordV1{Score: 5}); !errors.As(err, &mergeErr) {
+		t.Errorf("MergeCompatible of records of different types = %v, expected a MergeError", err)
+	}
+	if expected := (recordV2{Score: 1, Note: "keep me"}); a != expected {
+		t.Errorf("MergeCompatible modified a to %+v", a)
+	}
+	if changed, err := MergeCompatible(&a, recordV2{Score: 5}); !changed || err != nil || a != (recordV2{Score: 5}) {
+		t.Errorf("MergeCompatible of records of the same type = %v, %v, %+v", changed, err, a)
+	}
+}
@@ -0,0 +1,171 @@
+package crdt
+
+import (
+	"math"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// compiledMerge merges the value at b into the value at a, both of the type it was compiled for.
+// It returns true if the value at a was modified.
+type compiledMerge func(a, b unsafe.Pointer) bool
+
+// compiled caches the compiled merge of each type compiled by Compile.
+var compiled sync.Map // map[reflect.Type]compiledMerge
+
+// compileGeneration counts the registrations that have cleared compiled,
+// so that functions returned by Compile can tell when the merge they captured is out of date.
+var compileGeneration atomic.Uint64
+
+// Compile returns a function that merges b into the value pointed to by a, like Merge,
+// specialized for values of type T. It analyzes T once, when first compiled, and the function it returns
+// merges ordered values and structs made of them directly, at precomputed field offsets, without reflection;
+// it only falls back to reflection for the parts of T that need it, such as maps, slices, pointers,
+// types with custom merge behavior, and structs with `crdt` tags. Its results are the same as Merge's.
+// Compile panics if T can't be merged (see Validate).
+// Registering a merge function for a type within T later (see Register) recompiles T on its next use.
+func Compile[T any]() func(a *T, b T) bool {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if err := Validate(t); err != nil {
+		panic(err)
+	}
+	generation := compileGeneration.Load()
+	merge := compiledFor(t)
+	return func(a *T, b T) bool {
+		fn := merge
+		if compileGeneration.Load() != generation {
+			fn = compiledFor(t)
+		}
+		return fn(unsafe.Pointer(a), unsafe.Pointer(&b))
+	}
+}
+
+// compiledFor returns the compiled merge of type t, compiling it if it isn't cached.
+func compiledFor(t reflect.Type) compiledMerge {
+	fn, ok := compiled.Load(t)
+	if !ok {
+		fn, _ = compiled.LoadOrStore(t, compileType(t))
+	}
+	return fn.(compiledMerge)
+}
+
+// compileType returns a compiledMerge for values of type t.
+func compileType(t reflect.Type) compiledMerge {
+	if isScalar(t) {
+		switch t.Kind() {
+		case reflect.Bool:
+			return mergeBoolAt
+		case reflect.Int:
+			return compileOrdered[int]()
+		case reflect.Int8:
+			return compileOrdered[int8]()
+		case reflect.Int16:
+			return compileOrdered[int16]()
+		case reflect.Int32:
+			return compileOrdered[int32]()
+		case reflect.Int64:
+			return compileOrdered[int64]()
+		case reflect.Uint:
+			return compileOrdered[uint]()
+		case reflect.Uint8:
+			return compileOrdered[uint8]()
+		case reflect.Uint16:
+			return compileOrdered[uint16]()
+		case reflect.Uint32:
+			return compileOrdered[uint32]()
+		case reflect.Uint64:
+			return compileOrdered[uint64]()
+		case reflect.Float32:
+			return compileFloat(func(p unsafe.Pointer) float64 { return float64(*(*float32)(p)) },
+				func(a, b unsafe.Pointer) { *(*float32)(a) = *(*float32)(b) })
+		case reflect.Float64:
+			return compileFloat(func(p unsafe.Pointer) float64 { return *(*float64)(p) },
+				func(a, b unsafe.Pointer) { *(*float64)(a) = *(*float64)(b) })
+		case reflect.String:
+			return compileOrdered[string]()
+		}
+	}
+	if t.Kind() == reflect.Struct && compilableStruct(t) {
+		return compileStruct(t)
+	}
+	return func(a, b unsafe.Pointer) bool {
+		return merge(reflect.NewAt(t, a).Elem(), reflect.NewAt(t, b).Elem())
+	}
+}
+
+// compilableStruct returns true if the struct type t is merged fieldwise with no options,
+// so that its fields can be merged independently by compiled merges.
+func compilableStruct(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	if ptr.Implements(mergerType) || ptr.Implements(comparableType) || registered(t) != nil || isSQLNull(t) {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.PkgPath != "" || field.Tag.Get("crdt") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// compileStruct returns a compiledMerge for the struct type t, which must satisfy compilableStruct.
+func compileStruct(t reflect.Type) compiledMerge {
+	type compiledField struct {
+		offset uintptr
+		merge  compiledMerge
+	}
+	fields := make([]compiledField, t.NumField())
+	for i := range fields {
+		field := t.Field(i)
+		fields[i] = compiledField{field.Offset, compileType(field.Type)}
+	}
+	return func(a, b unsafe.Pointer) bool {
+		var changed bool
+		for _, field := range fields {
+			if field.merge(unsafe.Add(a, field.offset), unsafe.Add(b, field.offset)) {
+				changed = true
+			}
+		}
+		return changed
+	}
+}
+
+// compileOrdered returns a compiledMerge for an ordered type with underlying type E,
+// which keeps the greater of (a, b), with the zero value as the bottom value.
+func compileOrdered[E interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~string
+}]() compiledMerge {
+	return func(a, b unsafe.Pointer) bool {
+		x, y := (*E)(a), *(*E)(b)
+		var zero E
+		if y == zero || (*x != zero && *x >= y) {
+			return false
+		}
+		*x = y
+		return true
+	}
+}
+
+// compileFloat returns a compiledMerge for a float type, read by get and copied by set.
+// As for other merges, only positive zero is the zero value, and floats are ordered by floatLess.
+func compileFloat(get func(unsafe.Pointer) float64, set func(a, b unsafe.Pointer)) compiledMerge {
+	return func(a, b unsafe.Pointer) bool {
+		x, y := get(a), get(b)
+		if math.Float64bits(y) == 0 || (math.Float64bits(x) != 0 && !floatLess(x, y)) {
+			return false
+		}
+		set(a, b)
+		return true
+	}
+}
+
+// mergeBoolAt merges the bools at a and b, with true winning.
+func mergeBoolAt(a, b unsafe.Pointer) bool {
+	if *(*bool)(b) && !*(*bool)(a) {
+		*(*bool)(a) = true
+		return true
+	}
+	return false
+}
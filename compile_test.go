@@ -0,0 +1,105 @@
+package crdt
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// compiledState mixes fields that compiled merges handle directly with ones they hand to reflection.
+type compiledState struct {
+	Flat   flatStruct
+	Float  float64
+	Counts map[string]int
+	Tags   []string `crdt:"set"`
+	Max    decreasingInt
+	Ptr    *flatStruct
+}
+
+func randomCompiledState(r *rand.Rand) compiledState {
+	s := compiledState{
+		Flat:   randomFlatStruct(r),
+		Float:  []float64{0, math.Copysign(0, -1), math.NaN(), 1.5, -2}[r.Intn(5)],
+		Counts: map[string]int{string(rune('a' + r.Intn(3))): r.Intn(5)},
+		Max:    decreasingInt(r.Intn(5)),
+	}
+	if r.Intn(2) == 0 {
+		s.Tags = []string{string(rune('a' + r.Intn(3)))}
+	}
+	if r.Intn(2) == 0 {
+		flat := randomFlatStruct(r)
+		s.Ptr = &flat
+	}
+	return s
+}
+
+func TestCompile(t *testing.T) {
+	mergeCompiled := Compile[compiledState]()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a, b := randomCompiledState(r), randomCompiledState(r)
+		compiled, generic := Clone(a).(compiledState), Clone(a).(compiledState)
+		compiledChanged := mergeCompiled(&compiled, b)
+		genericChanged := Merge(&generic, b)
+		if compiledChanged != genericChanged || !reflect.DeepEqual(Normalize(compiled), Normalize(generic)) &&
+			!(math.IsNaN(compiled.Float) && math.IsNaN(generic.Float)) {
+			t.Fatalf("merging %+v into %+v: compiled merge gave (%+v, %v), Merge gave (%+v, %v)",
+				b, a, compiled, compiledChanged, generic, genericChanged)
+		}
+	}
+
+	mergeInt := Compile[int]()
+	x := 1
+	if !mergeInt(&x, 3) || x != 3 || mergeInt(&x, 2) {
+		t.Errorf("compiled int merge gave %d", x)
+	}
+}
+
+// compileRegistered is registered to merge as its minimum partway through TestCompileRegister.
+type compileRegistered int
+
+func TestCompileRegister(t *testing.T) {
+	type state struct{ A, B compileRegistered }
+	mergeCompiled := Compile[state]()
+	x := state{A: 1}
+	if !mergeCompiled(&x, state{A: 2, B: 1}) || x != (state{A: 2, B: 1}) {
+		t.Fatalf("compiled merge before Register gave %+v", x)
+	}
+	Register(reflect.TypeOf(compileRegistered(0)), func(a, b interface{}) bool {
+		value, other := a.(*compileRegistered), b.(compileRegistered)
+		if other < *value {
+			*value = other
+			return true
+		}
+		return false
+	})
+	for _, merge := range []func(*state, state) bool{mergeCompiled, Compile[state]()} {
+		compiled, generic := state{A: 2, B: 2}, state{A: 2, B: 2}
+		compiledChanged := merge(&compiled, state{A: 1, B: 3})
+		genericChanged := Merge(&generic, state{A: 1, B: 3})
+		if compiledChanged != genericChanged || compiled != generic {
+			t.Errorf("compiled merge after Register gave (%+v, %v), Merge gave (%+v, %v)",
+				compiled, compiledChanged, generic, genericChanged)
+		}
+	}
+}
+
+func TestCompileUnmergeable(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Compile of an unmergeable type didn't panic")
+		}
+	}()
+	Compile[struct{ F func() }]()
+}
+
+func BenchmarkMergeCompiled(b *testing.B) {
+	mergeCompiled := Compile[largeState]()
+	var x, y largeState
+	y.F15.B = 1
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mergeCompiled(&x, y)
+	}
+}
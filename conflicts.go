@@ -0,0 +1,85 @@
+package crdt
+
+import "reflect"
+
+// Concurrent is implemented by values that track causality, such as registers carrying a version vector,
+// and so can tell updates that happened concurrently from those that superseded one another.
+type Concurrent interface {
+	// Concurrent returns true if this value and other were updated concurrently:
+	// neither has observed all the updates of the other. Other must be the same type as this.
+	Concurrent(other interface{}) bool
+}
+
+var concurrentType = reflect.TypeOf((*Concurrent)(nil)).Elem()
+
+// conflicts appends to paths the paths at which a and b conflict, where tag holds the `crdt` tag options in effect.
+func (w *walker) conflicts(a, b reflect.Value, tag tagOptions, paths []string) []string {
+	switch {
+	case reflect.PointerTo(a.Type()).Implements(concurrentType):
+		aPtr := reflect.New(a.Type())
+		aPtr.Elem().Set(a)
+		if aPtr.Interface().(Concurrent).Concurrent(b.Interface()) {
+			paths = append(paths, w.path.String())
+		}
+	case isLeaf(a.Type()):
+		if neitherDominates(a, b, tag) {
+			paths = append(paths, w.path.String())
+		}
+	case a.Kind() == reflect.Struct:
+		tags := fieldTags(a.Type())
+		for i := 0; i < a.NumField(); i++ {
+			if field := a.Type().Field(i); field.PkgPath == "" {
+				w.path.pushField(field.Name)
+				paths = w.conflicts(a.Field(i), b.Field(i), tags[i], paths)
+				w.path.pop()
+			}
+		}
+	case a.Kind() == reflect.Map:
+		// A key missing from one side is dominated by the other side's value, so only shared keys can conflict.
+		var keys []reflect.Value
+		for _, key := range a.MapKeys() {
+			if b.MapIndex(key).IsValid() {
+				keys = append(keys, key)
+			}
+		}
+		w.sortKeys(keys)
+		for _, key := range keys {
+			w.path.pushKey(key)
+			paths = w.conflicts(a.MapIndex(key), b.MapIndex(key), tag, paths)
+			w.path.pop()
+		}
+	}
+	return paths
+}
+
+// neitherDominates returns true if neither of the leaves a and b, merged according to tag,
+// dominates the other: that is, if their join is different from both.
+func neitherDominates(a, b reflect.Value, tag tagOptions) bool {
+	if isOrdered(a.Kind()) && tag == nil {
+		return false
+	}
+	m := &merger{tag: tag}
+	value := reflect.New(a.Type()).Elem()
+	m.merge(value, a)
+	m.merge(value, b)
+	joined := value.Interface()
+	return !reflect.DeepEqual(joined, a.Interface()) && !reflect.DeepEqual(joined, b.Interface())
+}
+
+// Conflicts returns the paths (in the format used by WithProvenance) of the leaves at which a and b conflict,
+// in the order Walk would visit them. Values implementing Concurrent conflict if they say they were updated
+// concurrently, which lets types like version-vector registers, whose merge resolves conflicts by picking
+// a winner, report them. Other leaves conflict if neither dominates the other: their join equals neither.
+// Map keys present on only one side never conflict. a and b must be values of the same type.
+func Conflicts(a, b interface{}, opts ...Option) []string {
+	aVal := reflect.ValueOf(a)
+	bVal := reflect.ValueOf(b)
+	if aVal.Type() != bVal.Type() {
+		panic("a and b must be the same type")
+	}
+	w := new(walker)
+	for _, opt := range opts {
+		opt(&w.config)
+	}
+	return w.conflicts(aVal, bVal, nil, nil)
+}
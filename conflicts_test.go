@@ -0,0 +1,85 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// vvRegister is a register that tracks causality with a version vector,
+// resolving concurrent writes by keeping the greater value.
+type vvRegister struct {
+	Clock map[string]uint64
+	Value string
+}
+
+func (r *vvRegister) set(replica, value string) {
+	clock := make(map[string]uint64, len(r.Clock)+1)
+	for id, n := range r.Clock {
+		clock[id] = n
+	}
+	clock[replica]++
+	r.Clock, r.Value = clock, value
+}
+
+// dominates returns true if r's clock has observed every update in other's.
+func (r *vvRegister) dominates(other vvRegister) bool {
+	for id, n := range other.Clock {
+		if r.Clock[id] < n {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *vvRegister) Concurrent(other interface{}) bool {
+	o := other.(vvRegister)
+	return !r.dominates(o) && !o.dominates(*r)
+}
+
+func (r *vvRegister) Merge(other interface{}) bool {
+	o := other.(vvRegister)
+	value := r.Value
+	if o.dominates(*r) || !r.dominates(o) && o.Value > r.Value {
+		value = o.Value
+	}
+	clock := Join(r.Clock, o.Clock).(map[string]uint64)
+	if value == r.Value && reflect.DeepEqual(clock, r.Clock) {
+		return false
+	}
+	r.Clock, r.Value = clock, value
+	return true
+}
+
+func TestConflicts(t *testing.T) {
+	var base vvRegister
+	base.set("a", "v0")
+	a := map[string]vvRegister{"clean": base, "concurrent": base, "onlyA": base}
+	b := map[string]vvRegister{"clean": base, "concurrent": base, "onlyB": base}
+
+	clean := a["clean"]
+	clean.set("a", "v1")
+	a["clean"] = clean
+	aReg, bReg := a["concurrent"], b["concurrent"]
+	aReg.set("a", "from a")
+	bReg.set("b", "from b")
+	a["concurrent"], b["concurrent"] = aReg, bReg
+
+	if conflicts := Conflicts(a, b); !reflect.DeepEqual(conflicts, []string{"[concurrent]"}) {
+		t.Errorf("Conflicts(a, b) = %v, expected [[concurrent]]", conflicts)
+	}
+	if conflicts := Conflicts(b, a); !reflect.DeepEqual(conflicts, []string{"[concurrent]"}) {
+		t.Errorf("Conflicts(b, a) = %v, expected [[concurrent]]", conflicts)
+	}
+}
+
+func TestConflictsByDominance(t *testing.T) {
+	type state struct {
+		Count int
+		Tags  map[string][]string `crdt:"set"`
+	}
+	a := state{Count: 1, Tags: map[string][]string{"clean": {"x"}, "concurrent": {"x"}}}
+	b := state{Count: 2, Tags: map[string][]string{"clean": {"x", "y"}, "concurrent": {"y"}}}
+	if conflicts := Conflicts(a, b); !reflect.DeepEqual(conflicts, []string{"Tags[concurrent]"}) {
+		t.Errorf("Conflicts(a, b) = %v, expected [Tags[concurrent]]", conflicts)
+	}
+}
@@ -0,0 +1,58 @@
+package crdt
+
+import "context"
+
+// replicaIDKey is the context key under which WithReplicaID stores a replica ID.
+type replicaIDKey struct{}
+
+// WithReplicaID returns a copy of ctx carrying id as the ID of the local replica,
+// for the context-aware mutation methods of the package's CRDT types.
+func WithReplicaID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, replicaIDKey{}, id)
+}
+
+// ReplicaIDFromContext returns the replica ID carried by ctx, and whether it carries one.
+func ReplicaIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(replicaIDKey{}).(string)
+	return id, ok
+}
+
+// replicaID returns the replica ID carried by ctx, and panics if it doesn't carry one,
+// rather than attribute a mutation to the wrong replica.
+func replicaID(ctx context.Context) string {
+	id, ok := ReplicaIDFromContext(ctx)
+	if !ok {
+		panic("crdt: context has no replica ID")
+	}
+	return id
+}
+
+// IncrementCtx adds n to the counter on behalf of the replica whose ID ctx carries.
+func (c *GCounter) IncrementCtx(ctx context.Context, n uint64) {
+	c.Increment(replicaID(ctx), n)
+}
+
+// AddCtx adds elem to the set on behalf of the replica whose ID ctx carries.
+func (s *ORSet[T]) AddCtx(ctx context.Context, elem T) {
+	s.Add(replicaID(ctx), elem)
+}
+
+// RemoveCtx removes elem from the set on behalf of the replica whose ID ctx carries.
+func (s *ORSet[T]) RemoveCtx(ctx context.Context, elem T) {
+	s.Remove(replicaID(ctx), elem)
+}
+
+// AddCtx adds key to the map on behalf of the replica whose ID ctx carries, merging value into its value.
+func (m *TombstoneMap[K, V]) AddCtx(ctx context.Context, key K, value V) {
+	m.Add(replicaID(ctx), key, value)
+}
+
+// RemoveCtx removes key from the map on behalf of the replica whose ID ctx carries.
+func (m *TombstoneMap[K, V]) RemoveCtx(ctx context.Context, key K) {
+	m.Remove(replicaID(ctx), key)
+}
+
+// SetCtx writes value to the register at the given timestamp, on behalf of the replica whose ID ctx carries.
+func (r *LWWRegister[T]) SetCtx(ctx context.Context, value T, timestamp int64) {
+	r.Set(value, timestamp, replicaID(ctx))
+}
@@ -0,0 +1,55 @@
+package crdt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReplicaIDContext(t *testing.T) {
+	ctx := WithReplicaID(context.Background(), "r1")
+	if id, ok := ReplicaIDFromContext(ctx); !ok || id != "r1" {
+		t.Errorf("ReplicaIDFromContext = %q, %v, expected r1, true", id, ok)
+	}
+	if _, ok := ReplicaIDFromContext(context.Background()); ok {
+		t.Errorf("ReplicaIDFromContext found an ID in an empty context")
+	}
+
+	var counter GCounter
+	counter.IncrementCtx(ctx, 3)
+	if counter.Counts["r1"] != 3 || len(counter.Counts) != 1 {
+		t.Errorf("IncrementCtx counted %v, expected r1 = 3", counter.Counts)
+	}
+
+	var set ORSet[string]
+	set.AddCtx(ctx, "x")
+	if _, ok := set.Map.Adds["x"][Dot{"r1", 1}]; !ok || len(set.Map.Adds["x"]) != 1 {
+		t.Errorf("AddCtx recorded dots %v, expected one from r1", set.Map.Adds["x"])
+	}
+	set.RemoveCtx(ctx, "x")
+	if set.Contains("x") || set.Map.Seqs["r1"] != 2 {
+		t.Errorf("RemoveCtx didn't remove x on behalf of r1: %#v", set)
+	}
+
+	var tombstones TombstoneMap[string, int]
+	tombstones.AddCtx(ctx, "k", 1)
+	tombstones.RemoveCtx(ctx, "k")
+	if tombstones.Contains("k") || tombstones.Seqs["r1"] != 2 {
+		t.Errorf("TombstoneMap ctx methods didn't act on behalf of r1: %#v", tombstones)
+	}
+
+	var register LWWRegister[string]
+	register.SetCtx(ctx, "v", 10)
+	if register.Replica != "r1" || register.Value != "v" {
+		t.Errorf("SetCtx wrote %#v, expected v from r1", register)
+	}
+}
+
+func TestReplicaIDContextMissing(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("IncrementCtx without a replica ID didn't panic")
+		}
+	}()
+	var counter GCounter
+	counter.IncrementCtx(context.Background(), 1)
+}
@@ -0,0 +1,136 @@
+package crdt
+
+import "unsafe"
+
+// GCounter is a grow-only counter: each replica counts its own increments,
+// and the counter's value is the sum of every replica's count.
+// Counts merge keywise by max, so merging a replica's state again never counts its increments twice.
+// The zero value is a counter at zero.
+type GCounter struct {
+	// Counts holds each replica's total increments.
+	Counts map[string]uint64
+}
+
+// Increment adds n to the counter on behalf of replica.
+func (c *GCounter) Increment(replica string, n uint64) {
+	if c.Counts == nil {
+		c.Counts = make(map[string]uint64)
+	}
+	c.Counts[replica] += n
+}
+
+// Value returns the value of the counter.
+func (c *GCounter) Value() uint64 {
+	var value uint64
+	for _, count := range c.Counts {
+		value += count
+	}
+	return value
+}
+
+// Merge merges another GCounter into this one.
+func (c *GCounter) Merge(other interface{}) bool {
+	return Merge(&c.Counts, other.(GCounter).Counts)
+}
+
+// Replicas returns the IDs of the replicas that have incremented the counter.
+func (c *GCounter) Replicas() []string {
+	return mapKeys(c.Counts)
+}
+
+// PruneReplica removes the count of replica, lowering the counter's value by it.
+// See the package's PruneReplica for when this is safe.
+func (c *GCounter) PruneReplica(replica string) bool {
+	_, ok := c.Counts[replica]
+	delete(c.Counts, replica)
+	return ok
+}
+
+// IncrementKey adds n to the counter at key in m on behalf of replica, allocating the counter if m has none.
+// m must not be nil. A map[K]*GCounter merges keywise like any other map: a key new to the map being merged into
+// gets a deep copy of the other's counter, never sharing it, and counters at the same key merge by GCounter.Merge.
+func IncrementKey[K comparable](m map[K]*GCounter, key K, replica string, n uint64) {
+	c := m[key]
+	if c == nil {
+		c = new(GCounter)
+		m[key] = c
+	}
+	c.Increment(replica, n)
+}
+
+// Integer is a constraint permitting any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Counter is a grow-only counter like GCounter, counting in T, which lets a counter that never
+// gets large use a narrower type to save memory. Each replica's count saturates at T's maximum
+// rather than wrapping around, so that it never decreases, which merging by max relies on;
+// the sum returned by Value still wraps around on overflow, like T's arithmetic.
+// The zero value is a counter at zero.
+type Counter[T Integer] struct {
+	// Counts holds each replica's total increments.
+	Counts map[string]T
+}
+
+// Increment adds n to the counter on behalf of replica, saturating at T's maximum.
+// n must not be negative.
+func (c *Counter[T]) Increment(replica string, n T) {
+	if n < 0 {
+		panic("crdt: Counter can't be decremented")
+	}
+	if c.Counts == nil {
+		c.Counts = make(map[string]T)
+	}
+	count := c.Counts[replica] + n
+	if count < c.Counts[replica] {
+		count = maxInteger[T]()
+	}
+	c.Counts[replica] = count
+}
+
+// maxInteger returns the greatest value of T.
+func maxInteger[T Integer]() T {
+	max := ^T(0)
+	if max < 0 {
+		// T is signed, so its greatest value has every bit but the sign bit set.
+		max = T(uint64(1)<<(unsafe.Sizeof(max)*8-1) - 1)
+	}
+	return max
+}
+
+// Value returns the value of the counter.
+func (c *Counter[T]) Value() T {
+	var value T
+	for _, count := range c.Counts {
+		value += count
+	}
+	return value
+}
+
+// Merge merges another Counter into this one.
+func (c *Counter[T]) Merge(other interface{}) bool {
+	return Merge(&c.Counts, other.(Counter[T]).Counts)
+}
+
+// Replicas returns the IDs of the replicas that have incremented the counter.
+func (c *Counter[T]) Replicas() []string {
+	return mapKeys(c.Counts)
+}
+
+// PruneReplica removes the count of replica, lowering the counter's value by it.
+// See the package's PruneReplica for when this is safe.
+func (c *Counter[T]) PruneReplica(replica string) bool {
+	_, ok := c.Counts[replica]
+	delete(c.Counts, replica)
+	return ok
+}
+
+// mapKeys returns the keys of m, in any order.
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
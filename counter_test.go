@@ -0,0 +1,104 @@
+package crdt
+
+import "testing"
+
+func TestGCounter(t *testing.T) {
+	var a, b GCounter
+	a.Increment("a", 2)
+	a.Increment("a", 1)
+	b.Increment("b", 4)
+	joined := Join(a, b).(GCounter)
+	if value := joined.Value(); value != 7 {
+		t.Errorf("Join(a, b).Value() = %d, expected 7", value)
+	}
+	if Merge(&joined, a) {
+		t.Errorf("merging a again reported a change")
+	}
+	if value := joined.Value(); value != 7 {
+		t.Errorf("after merging a again, Value() = %d, expected 7", value)
+	}
+}
+
+func testCounter[T Integer](t *testing.T) {
+	var a, b Counter[T]
+	a.Increment("a", 2)
+	a.Increment("a", 1)
+	b.Increment("b", 4)
+	b.Increment("a", 1)
+	ab, ba := Join(a, b).(Counter[T]), Join(b, a).(Counter[T])
+	if ab.Value() != 7 || ba.Value() != 7 {
+		t.Errorf("Join(a, b).Value() = %d and Join(b, a).Value() = %d, expected 7", ab.Value(), ba.Value())
+	}
+	if Merge(&ab, b) || Merge(&ab, a) || ab.Value() != 7 {
+		t.Errorf("merging a or b again changed the counter, to %d", ab.Value())
+	}
+}
+
+func TestCounter(t *testing.T) {
+	t.Run("uint32", testCounter[uint32])
+	t.Run("int64", testCounter[int64])
+}
+
+func TestCounterOverflow(t *testing.T) {
+	var c Counter[int8]
+	c.Increment("a", 100)
+	c.Increment("a", 100)
+	if c.Counts["a"] != 127 {
+		t.Errorf("int8 count after overflowing = %d, expected 127", c.Counts["a"])
+	}
+	earlier := Counter[int8]{Counts: map[string]int8{"a": 100}}
+	if Merge(&c, earlier) || c.Counts["a"] != 127 {
+		t.Errorf("merging an earlier state lowered the saturated count to %d", c.Counts["a"])
+	}
+	var u Counter[uint16]
+	u.Increment("a", 60000)
+	u.Increment("a", 60000)
+	if u.Counts["a"] != 65535 {
+		t.Errorf("uint16 count after overflowing = %d, expected 65535", u.Counts["a"])
+	}
+}
+
+func TestCounterDecrement(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Increment with a negative n didn't panic")
+		}
+	}()
+	var c Counter[int64]
+	c.Increment("a", -1)
+}
+
+func TestIncrementKey(t *testing.T) {
+	a, b := map[string]*GCounter{}, map[string]*GCounter{}
+	IncrementKey(a, "x", "a", 2)
+	IncrementKey(a, "y", "a", 1)
+	IncrementKey(b, "x", "b", 3)
+	IncrementKey(b, "z", "b", 4)
+	ab, ba := Join(a, b).(map[string]*GCounter), Join(b, a).(map[string]*GCounter)
+	expected := map[string]uint64{"x": 5, "y": 1, "z": 4}
+	for key, value := range expected {
+		if ab[key].Value() != value || ba[key].Value() != value {
+			t.Errorf("counters at %s are %d and %d, expected %d", key, ab[key].Value(), ba[key].Value(), value)
+		}
+	}
+	if len(ab) != len(expected) || len(ba) != len(expected) {
+		t.Errorf("joined maps have %d and %d keys, expected %d", len(ab), len(ba), len(expected))
+	}
+
+	if !Merge(&a, b) {
+		t.Errorf("merging b into a reported no change")
+	}
+	if a["z"] == b["z"] {
+		t.Errorf("merging b into a shared b's counter at z")
+	}
+	IncrementKey(a, "z", "a", 1)
+	if b["z"].Value() != 4 {
+		t.Errorf("incrementing a's counter at z changed b's to %d", b["z"].Value())
+	}
+	if Merge(&b, a); b["z"].Value() != 5 || b["x"].Value() != 5 {
+		t.Errorf("after merging a into b, b's counters at z and x are %d and %d, expected 5 and 5", b["z"].Value(), b["x"].Value())
+	}
+	if Merge(&a, b) || Merge(&b, a) {
+		t.Errorf("merging converged maps reported a change")
+	}
+}
@@ -8,19 +8,64 @@
 // Join(a, b) is equivalent to the value of result after Merge(&result, a); Merge(&result, b).
 //
 // Merges are done as follows:
-//   * If the type implements Merger, Merge(&a, b) simply calls (&a).Merge(b).
-//   * If the type is a struct, merges are done recursively fieldwise.
-//   * If the type is a map, merges are done recursively keywise.
-//   * If the type has a total ordering (bool, string, u?int{,8,16,32,64}, float{32,64}),
-//     Merge(&a, b) sets a to the greater of (a, b).
-//   * Otherwise, Merge panics.
+//   - If the type implements Merger, Merge(&a, b) simply calls (&a).Merge(b).
+//   - If a MergeFunc has been registered for the type, Merge(&a, b) calls it.
+//   - If the type implements Comparable, Merge(&a, b) sets a to the greater of (a, b) per Compare.
+//   - If the type is a struct, merges are done recursively fieldwise.
+//     The empty struct, struct{}, has only its zero value, the bottom value, so merging it never changes it,
+//     and a map[K]struct{} merges as a grow-only set of its keys.
+//   - If the type is a map, merges are done recursively keywise.
+//   - If the type is a slice, merges are done recursively elementwise, like a map keyed by index;
+//     the longer slice's extra elements are kept (see WithSliceLenPolicy).
+//   - If the type is a pointer, merges are done recursively on the values pointed to.
+//     A nil pointer is merged with a non-nil one by pointing it at a deep copy of the other's value.
+//     A sync/atomic.Pointer is merged the same way, but by storing a merged copy, atomically.
+//   - If the type is a database/sql.Null[T], a valid value beats an invalid one, whatever its V,
+//     and two valid values are merged by merging their Vs.
+//   - If the type has a total ordering (bool, string, u?int{,8,16,32,64}, float{32,64}),
+//     Merge(&a, b) sets a to the greater of (a, b). []byte and []rune are ordered lexicographically,
+//     like strings, and merged as whole values. Floats are ordered so that merges are deterministic
+//     to the bit, whatever their order: NaNs are less than every number, but greater than the zero value,
+//     and ordered among themselves by their bits; and only +0 is the zero value, so -0 beats it.
+//     Values that tie thus have the same bits.
+//   - If the type is an interface type, Merge merges the dynamic values, which must be of the same type
+//     unless one is nil, the bottom value. Each value of a map[K]interface{} is thus merged by the rules
+//     for its own dynamic type, and only values of different types under the same key conflict.
+//   - Otherwise, Merge panics with a *MergeError. MergeWith returns the error instead.
+//
+// A struct field's `crdt` tag can select a different strategy for merging it:
+//   - `crdt:"lww"` or `crdt:"lww=Field"` makes the field last-writer-wins: the whole value is taken
+//     from one side, chosen by its sibling timestamp Field or, for slices, by comparing the slices.
+//   - `crdt:"fww"` makes the field first-writer-wins: once it is non-zero, it never changes.
+//   - `crdt:"set"` merges slices as sets: the result is the sorted, deduplicated union of both sides.
+//     With `crdt:"set=Field"`, elements are structs identified by their Field, and elements with
+//     the same identity are merged with each other.
+//   - `crdt:"sortedmax"` merges sorted slices to their sorted pointwise maximum (see MergeSortedMax).
+//   - `crdt:"chan=Buffer"`, with WithChannels, merges the values buffered in a channel into Buffer, a set.
+//   - `crdt:"2pset"` merges a map[K]bool as a two-phase set, where false marks a removed key.
+//   - `crdt:"log"` merges slices of structs as append-only logs, ordered by each element's origin.
+//   - `crdt:"primary"`, with optional `crdt:"tiebreak"` or `crdt:"tiebreak=min"` fields, makes the struct
+//     containing the field merge as a single record, taken whole from the side whose primary field wins.
+//   - `crdt:"enum=name"` merges strings by the order of the values registered with RegisterEnum(name, ...).
+//   - `crdt:"bitset"` merges *big.Int values as sets of bits, by bitwise OR, rather than as numbers.
+//   - `crdt:"since=N"` ignores the field when merging from a state whose field tagged `crdt:"version"`
+//     is below N, so that replicas that predate the field can't clobber it.
+//   - `crdt:"sum=Counts"` makes an integer field a counter: it is set to the sum of its sibling
+//     map[string]T Counts of per-replica increments, which merges keywise by max (see AddSum).
+//   - `crdt:"was=OldName"` decodes a JSON member named OldName, written before the field was renamed,
+//     into the field when UnmarshalMergeJSON decodes a state to merge.
+//
+// Tags on a map field apply to the map's values, so a `crdt:"set"` tag on a map[K][]V merges
+// each key's slice as a set.
 //
 // The zero value of any type is special: any non-zero value is considered to be greater than it.
-// As a result, Join(a, zero) == a for any value a.
+// As a result, Join(a, zero) == a for any value a. A type can define which of its values count as zero
+// by implementing an IsZero() bool method, following the convention of time.Time; its IsZero must be
+// true for Go's zero value of the type, which is where Join starts.
 package crdt
 
 import (
-	"fmt"
+	"math"
 	"reflect"
 )
 
@@ -32,6 +77,24 @@ type Merger interface {
 	Merge(other interface{}) bool
 }
 
+// PartialMerger is implemented by struct types whose Merge method merges only some of their fields,
+// leaving the rest to be merged fieldwise by the package's rules once it returns.
+type PartialMerger interface {
+	Merger
+	// MergeHandled returns the names of the fields that Merge merges. It must return the same names
+	// for every value of the type.
+	MergeHandled() []string
+}
+
+// Comparable is an interface to a totally ordered value.
+// It is a lighter-weight alternative to Merger for types whose join is simply the greater of two values.
+type Comparable interface {
+	// Compare returns a negative number if this value is less than other,
+	// a positive number if it is greater, and zero if they are equal.
+	// Other must be the same type as this.
+	Compare(other interface{}) int
+}
+
 // isOrdered returns true if the given kind of value has a total ordering.
 func isOrdered(kind reflect.Kind) bool {
 	switch kind {
@@ -50,118 +113,527 @@ func isOrdered(kind reflect.Kind) bool {
 	}
 }
 
-// greater returns true if a > b.
-func greater(a, b interface{}) bool {
-	switch a.(type) {
-	case bool:
-		return a.(bool) && !b.(bool)
-	case int:
-		return a.(int) > b.(int)
-	case int8:
-		return a.(int8) > b.(int8)
-	case int16:
-		return a.(int16) > b.(int16)
-	case int32:
-		return a.(int32) > b.(int32)
-	case int64:
-		return a.(int64) > b.(int64)
-	case uint:
-		return a.(uint) > b.(uint)
-	case uint8:
-		return a.(uint8) > b.(uint8)
-	case uint16:
-		return a.(uint16) > b.(uint16)
-	case uint32:
-		return a.(uint32) > b.(uint32)
-	case uint64:
-		return a.(uint64) > b.(uint64)
-	case float32:
-		return a.(float32) > b.(float32)
-	case float64:
-		return a.(float64) > b.(float64)
-	case string:
-		return a.(string) > b.(string)
+// less returns true if a < b. Both a and b must be of the same ordered kind.
+func less(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return floatLess(a.Float(), b.Float())
+	case reflect.String:
+		return a.String() < b.String()
 	default:
-		panic("don't know how to handle type: " + reflect.TypeOf(a).String())
+		panic(mergeErrorf("don't know how to handle type: %s", a.Type()))
 	}
 }
 
-// merge sets the value of a to the least upper bound of (a, b).
+// floatLess returns true if x < y in the total ordering of floats used by merges: NaNs are less than
+// every number and ordered among themselves by their bits, and numbers are ordered as usual,
+// so that -0 and +0 are equal.
+func floatLess(x, y float64) bool {
+	xNaN, yNaN := math.IsNaN(x), math.IsNaN(y)
+	switch {
+	case xNaN && yNaN:
+		return math.Float64bits(x) < math.Float64bits(y)
+	case xNaN || yNaN:
+		return xNaN
+	}
+	return x < y
+}
+
+// zeroer is implemented by types that define which of their values are zero, like time.Time.
+type zeroer interface {
+	IsZero() bool
+}
+
+var zeroerType = reflect.TypeOf((*zeroer)(nil)).Elem()
+
+// isZero returns true if v is a zero value, and therefore the bottom value.
+// A nil pointer or interface is always zero. Otherwise, if v's type has an IsZero method, it decides;
+// otherwise v must be Go's zero value for its type.
+func isZero(v reflect.Value) bool {
+	if kind := v.Kind(); (kind == reflect.Ptr || kind == reflect.Interface) && v.IsNil() {
+		return true
+	}
+	if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(zeroerType) {
+		return v.Addr().Interface().(zeroer).IsZero()
+	}
+	if v.Type().Implements(zeroerType) {
+		return v.Interface().(zeroer).IsZero()
+	}
+	return isScalarZero(v)
+}
+
+// isScalarZero returns true if v is Go's zero value for its type, with -0 a non-zero float,
+// so that it is distinct from the bottom value +0. It is isZero for types that satisfy isScalar.
+func isScalarZero(v reflect.Value) bool {
+	if kind := v.Kind(); kind == reflect.Float32 || kind == reflect.Float64 {
+		return math.Float64bits(v.Float()) == 0
+	}
+	return v.IsZero()
+}
+
+// merger carries the options and current path of a merge through its recursion.
+type merger struct {
+	config
+	path path
+	// tag holds the `crdt` tag options of the struct field being merged.
+	// They apply to the field's value and, through maps, to the map's values.
+	tag tagOptions
+	// merging holds the pairs of pointers (a, b) being merged, to detect cycles.
+	merging map[[2]uintptr]bool
+	// copying is set for merges that copy a value into a zero value, rather than make decisions.
+	copying bool
+	// progressed is set once the merge has merged a map entry, and expired once the merge's deadline
+	// has passed and it has stopped merging map keys.
+	progressed, expired bool
+}
+
+// merge sets the value of a to the least upper bound of (a, b), with no options.
 // It returns true if the value of a was modified.
 // Both a and b must be mergeable values, and a must be addressable.
 func merge(a, b reflect.Value) bool {
-	var changed bool
+	return new(merger).merge(a, b)
+}
+
+// merge sets the value of a to the least upper bound of (a, b).
+// It returns true if the value of a was modified.
+// Both a and b must be mergeable values, and a must be addressable.
+func (m *merger) merge(a, b reflect.Value) (changed bool) {
+	if m.errs != nil {
+		defer m.collect(&changed, a, b, len(m.path), m.tag)
+	}
 	if merger, ok := a.Addr().Interface().(Merger); ok {
+		var before reflect.Value
+		if m.verifyLaws {
+			before = deepCopy(a)
+		}
 		changed = merger.Merge(b.Interface())
-	} else if a.Kind() == reflect.Struct {
-		for i := 0; i < a.NumField(); i++ {
-			field := a.Type().Field(i)
-			if field.PkgPath != "" {
-				panic(fmt.Errorf("field %s (%s) is unexported", field.Name, field.PkgPath))
-			}
-			if merge(a.Field(i), b.Field(i)) {
+		if m.verifyLaws {
+			m.checkLaws(a, b, before)
+		}
+		m.decide(changed, a)
+		if partial, ok := merger.(PartialMerger); ok && a.Kind() == reflect.Struct {
+			if m.mergeFieldsExcept(a, b, handledFields(partial)) {
 				changed = true
 			}
 		}
-	} else if a.Kind() == reflect.Map {
-		if a.IsNil() && !b.IsNil() {
-			a.Set(reflect.MakeMap(a.Type()))
-		}
-		for _, key := range b.MapKeys() {
-			aValue := a.MapIndex(key)
-			bValue := b.MapIndex(key)
-			if aValue.IsValid() {
-				newValue := reflect.New(aValue.Type()).Elem()
-				merge(newValue, aValue)
-				if merge(newValue, bValue) {
-					a.SetMapIndex(key, newValue)
-					changed = true
-				}
+	} else if a.Type() == bigIntType && m.tag.has("bitset") {
+		changed = mergeBitset(a, b)
+		m.decide(changed, a)
+	} else if fn := registered(a.Type()); fn != nil {
+		changed = fn(a, b)
+		m.decide(changed, a)
+	} else if comparable, ok := a.Addr().Interface().(Comparable); ok {
+		// The zero value is the bottom value, regardless of what Compare says about it.
+		if !isZero(b) && (isZero(a) || comparable.Compare(b.Interface()) < 0) {
+			if isOrdered(a.Kind()) {
+				a.Set(b)
 			} else {
-				a.SetMapIndex(key, bValue)
-				changed = true
+				a.Set(deepCopy(b))
 			}
+			changed = true
 		}
+		m.decide(changed, a)
+	} else if top, ok := m.tag["top"]; ok && isOrdered(a.Kind()) {
+		changed = m.mergeTop(top, a, b)
+		m.decide(changed, a)
+	} else if name, ok := m.tag["enum"]; ok && a.Kind() == reflect.String {
+		changed = m.mergeEnum(name, a, b)
+		m.decide(changed, a)
+	} else if m.textScalars && isTextScalar(a.Type()) {
+		changed = mergeText(a, b)
+		m.decide(changed, a)
+	} else if isAtomicPointer(a.Type()) {
+		changed = m.mergeAtomicPointer(a, b)
+	} else if isSQLNull(a.Type()) {
+		changed = m.mergeNull(a, b)
+	} else if a.Kind() == reflect.Struct && m.tag.has("fixedpoint") {
+		changed = m.mergeFixedPoint(a, b)
+	} else if a.Kind() == reflect.Struct && m.tag.has("union") {
+		changed = m.mergeUnion(a, b)
+	} else if a.Kind() == reflect.Struct {
+		if m.leafHooks() || m.textScalars || !isFlat(a.Type()) {
+			changed = m.mergeStruct(a, b)
+		} else {
+			changed = mergeFlat(a, b)
+		}
+	} else if a.Kind() == reflect.Map {
+		changed = m.mergeMap(a, b)
+	} else if a.Kind() == reflect.Slice {
+		changed = m.mergeSlice(a, b)
+	} else if a.Kind() == reflect.Ptr {
+		changed = m.mergePtr(a, b)
+	} else if a.Kind() == reflect.Interface {
+		changed = m.mergeInterface(a, b)
 	} else if isOrdered(a.Kind()) {
-		if greater(b.Interface(), a.Interface()) {
-			a.Set(b)
+		changed = m.mergeOrdered(a, b)
+		m.decide(changed, a)
+	} else {
+		panic(mergeErrorf("don't know how to merge type %s", a.Type()))
+	}
+	return changed
+}
+
+// mergeOrdered sets the value of a, which is of an ordered kind, to the greater of (a, b),
+// with the zero value as the bottom value. It returns true if the value of a was modified.
+func (m *merger) mergeOrdered(a, b reflect.Value) bool {
+	if isZero(b) || (!isZero(a) && !less(a, b)) {
+		return false
+	}
+	if a.Kind() == reflect.String && m.intern != nil {
+		a.SetString(m.intern(b.String()))
+	} else {
+		a.Set(b)
+	}
+	return true
+}
+
+// mergeStruct merges the struct b into the struct a fieldwise, or as a single record if it has a primary field.
+// It returns true if the value of a was modified.
+func (m *merger) mergeStruct(a, b reflect.Value) bool {
+	if fields := recordFields(a.Type()); fields != nil {
+		return m.mergeRecord(a, b, fields)
+	}
+	return m.mergeFields(a, b)
+}
+
+// mergeFields merges the struct b into the struct a fieldwise, according to the fields' tags.
+// It returns true if the value of a was modified.
+func (m *merger) mergeFields(a, b reflect.Value) bool {
+	return m.mergeFieldsExcept(a, b, nil)
+}
+
+// mergeFieldsExcept merges the struct b into the struct a fieldwise, like mergeFields,
+// except for the fields named in skip. It returns true if the value of a was modified.
+func (m *merger) mergeFieldsExcept(a, b reflect.Value, skip map[string]bool) bool {
+	var changed bool
+	tags := fieldTags(a.Type())
+	siblings := lwwSiblings(a, b, tags)
+	version, versioned, err := sinceVersion(b, tags)
+	if err != nil {
+		panic(mergeErrorf("%v", err))
+	}
+	if m.unexported && !b.CanAddr() {
+		b = addressable(b)
+	}
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		aField, bField := a.Field(i), b.Field(i)
+		if skip[field.Name] {
+			continue
+		}
+		if field.PkgPath != "" {
+			if !m.unexported {
+				m.fail(mergeErrorf("field %s (%s) is unexported", field.Name, field.PkgPath))
+				continue
+			}
+			aField, bField = exposeField(aField), exposeField(bField)
+		}
+		if tags[i].has("sum") {
+			// The field is set from its merged counts by mergeSums, below.
+			continue
+		}
+		if versioned && sinceSkips(tags[i], version) {
+			continue
+		}
+		m.path.pushField(field.Name)
+		m.tag = tags[i]
+		var fieldChanged bool
+		if aField.Kind() == reflect.Chan && tags[i]["chan"] != "" {
+			fieldChanged = m.mergeChan(a, aField, bField, tags[i]["chan"])
+		} else if tags[i].has("lww") {
+			fieldChanged = m.mergeLWW(aField, bField, siblings[i])
+		} else if tags[i].has("fww") {
+			fieldChanged = m.mergeFWW(aField, bField)
+		} else {
+			fieldChanged = m.merge(aField, bField)
+		}
+		if fieldChanged {
 			changed = true
 		}
-	} else {
-		panic("don't know how to merge type " + a.Type().String())
+		m.path.pop()
+	}
+	m.tag = nil
+	if m.mergeSums(a, tags) {
+		changed = true
 	}
 	return changed
 }
 
+// handledFields returns the set of the fields that the Merge method of partial merges.
+func handledFields(partial PartialMerger) map[string]bool {
+	handled := make(map[string]bool)
+	for _, name := range partial.MergeHandled() {
+		handled[name] = true
+	}
+	return handled
+}
+
+// mergePtr merges the value pointed to by b into the value pointed to by a.
+// A nil pointer is the bottom value; a nil a is set to point to a deep copy of b's value,
+// so that a never shares b's storage. It returns true if the value of a was modified.
+//
+// Cyclic values are merged safely: a pair of pointers that is already being merged further up
+// the recursion is not merged again, and a pointer merged with itself is left as it is.
+func (m *merger) mergePtr(a, b reflect.Value) bool {
+	if b.IsNil() || a.Pointer() == b.Pointer() {
+		m.decide(false, a)
+		return false
+	}
+	if a.IsNil() {
+		m.chargeCopy(b)
+		a.Set(deepCopy(b))
+		m.decide(true, a)
+		return true
+	}
+	pair := [2]uintptr{a.Pointer(), b.Pointer()}
+	if m.merging[pair] {
+		return false
+	}
+	if m.merging == nil {
+		m.merging = make(map[[2]uintptr]bool)
+	}
+	m.merging[pair] = true
+	defer delete(m.merging, pair)
+	return m.merge(a.Elem(), b.Elem())
+}
+
+// adopt returns the value to store for a map key that is present in b but not a.
+// If a tag or string interner is in effect, b's value is merged into a zero value so that they apply to it;
+// otherwise it is deep-copied, so that a doesn't share b's storage.
+func (m *merger) adopt(b reflect.Value) reflect.Value {
+	if m.tag == nil && m.intern == nil {
+		if isOrdered(b.Kind()) {
+			return b
+		}
+		return deepCopy(b)
+	}
+	value := reflect.New(b.Type()).Elem()
+	m.quiet().merge(value, b)
+	return value
+}
+
+// mergeStruct merges the struct b into the struct a fieldwise, with no options.
+// It returns true if the value of a was modified.
+func mergeStruct(a, b reflect.Value) bool {
+	return new(merger).mergeStruct(a, b)
+}
+
+// mergeMap merges the map b into the map a keywise.
+// Keys match as they do for Go's map index expressions, so keys of an interface type are the same key
+// only if their dynamic types and values are equal: 1 and int64(1) are distinct keys.
+// It returns true if the value of a was modified.
+func (m *merger) mergeMap(a, b reflect.Value) bool {
+	if m.tag.has("2pset") {
+		return m.mergeTwoPhase(a, b)
+	}
+	if !m.leafHooks() && !m.textScalars && m.tag == nil && m.deadline.IsZero() && m.budget == nil {
+		if isScalar(a.Type().Elem()) {
+			return mergeScalarMap(a, b)
+		}
+		if elem := a.Type().Elem(); elem.Kind() == reflect.Map && isScalar(elem.Elem()) {
+			return mergeNestedMap(a, b)
+		}
+	}
+	var changed bool
+	// added holds the keys added to a, if its size is bounded, since they may be evicted again.
+	var added []reflect.Value
+	if a.IsNil() && !b.IsNil() {
+		a.Set(reflect.MakeMap(a.Type()))
+	}
+	for _, key := range b.MapKeys() {
+		if m.expire() {
+			break
+		}
+		aValue := a.MapIndex(key)
+		bValue := b.MapIndex(key)
+		m.path.pushKey(key)
+		if aValue.IsValid() {
+			newValue := reflect.New(aValue.Type()).Elem()
+			if aValue.Kind() == reflect.Ptr {
+				// The value pointed to belongs to a, and can be merged in place.
+				newValue.Set(aValue)
+			} else {
+				m.quiet().merge(newValue, aValue)
+			}
+			if m.merge(newValue, bValue) {
+				a.SetMapIndex(key, newValue)
+				changed = true
+			}
+		} else {
+			if m.budget != nil {
+				m.charge(1 + allocSize(key, make(map[copiedPointer]bool)) + allocSize(bValue, make(map[copiedPointer]bool)))
+			}
+			if key.Kind() == reflect.String && m.intern != nil {
+				key = reflect.ValueOf(m.intern(key.String())).Convert(key.Type())
+			}
+			value := m.adopt(bValue)
+			a.SetMapIndex(key, value)
+			if m.maxMapSize > 0 {
+				added = append(added, key)
+			} else {
+				changed = true
+			}
+			m.decide(true, value)
+		}
+		m.path.pop()
+		m.progressed = true
+	}
+	// a changed if an added entry survived eviction or an entry it had before was evicted.
+	evicted := m.evict(a)
+	for _, key := range added {
+		if a.MapIndex(key).IsValid() {
+			changed = true
+		} else {
+			evicted--
+		}
+	}
+	return changed || evicted > 0
+}
+
 // Merge sets the value of a to the least upper bound of (a, b).
 // It returns true if the value of a was modified.
-// a must be a pointer to a mergeable type, and b must be a non-pointer value of the same type.
+// a must be a pointer to a mergeable type, and b must be a value of the same type or a pointer to one,
+// as described for MergeWith.
 func Merge(a, b interface{}) bool {
+	changed, err := MergeWith(a, b)
+	if err != nil {
+		panic(err)
+	}
+	return changed
+}
+
+// MergePtr is like Merge, but takes b by pointer, so that large values of b needn't be copied to be merged.
+// It sets the value pointed to by a to the least upper bound of (*a, *b), and returns true if it was modified.
+// a and b must be pointers to the same mergeable type. The value pointed to by b is not modified.
+func MergePtr(a, b interface{}) bool {
 	aVal := reflect.ValueOf(a)
 	bVal := reflect.ValueOf(b)
-	if aVal.Kind() != reflect.Ptr {
-		panic("a must be a pointer")
+	if aVal.Kind() != reflect.Ptr || bVal.Kind() != reflect.Ptr {
+		panic("a and b must be pointers")
+	}
+	if aVal.Type() != bVal.Type() {
+		panic("a and b must be the same type")
+	}
+	return merge(aVal.Elem(), bVal.Elem())
+}
+
+// MergeSliceElem merges b into the i-th element of the slice s in place, like Merge, leaving the other
+// elements untouched. It returns true if the element was modified. s must be a slice of a mergeable type,
+// or a pointer to one, and b must be a value of the element type or a pointer to one, which is read in place.
+// MergeSliceElem panics if i is out of range.
+func MergeSliceElem(s interface{}, i int, b interface{}) bool {
+	sVal := reflect.Indirect(reflect.ValueOf(s))
+	if sVal.Kind() != reflect.Slice {
+		panic("s must be a slice")
+	}
+	if i < 0 || i >= sVal.Len() {
+		panic("i is out of range")
+	}
+	_, bVal := mergeArgs(sVal.Index(i).Addr().Interface(), b)
+	changed, err := new(merger).run(sVal.Index(i), bVal)
+	if err != nil {
+		panic(err)
 	}
-	if aVal.Elem().Type() != bVal.Type() {
-		panic("a and &b must be the same type")
+	return changed
+}
+
+// MergeIf merges b into a, like Merge, but only if pred(a, b) returns true; otherwise a is left unchanged.
+// It returns true if the value of a was modified. pred is given a and b as they were passed to MergeIf,
+// so it can check an invariant, such as a monotonic version or a trusted signature, before b is accepted.
+func MergeIf(a, b interface{}, pred func(a, b interface{}) bool) bool {
+	if !pred(a, b) {
+		return false
 	}
-	return merge(aVal.Elem(), bVal)
+	return Merge(a, b)
 }
 
+// join returns the least upper bound of (a, b), which shares no storage with either of them.
+// The package's own rules copy what they take from b, but a Merger or registered MergeFunc may keep
+// a reference to the value it is given, so for types that may contain them, the merge is given copies.
 func join(a, b reflect.Value) reflect.Value {
 	value := reflect.New(a.Type()).Elem()
+	joinInto(value, a, b)
+	return value
+}
+
+// joinInto merges a and b into value, which is addressable and empty, so that it holds their join.
+// The joins that reuse storage for their results share it with join.
+func joinInto(value, a, b reflect.Value) {
+	if delegates(a.Type()) {
+		a, b = deepCopy(a), deepCopy(b)
+	}
 	merge(value, a)
 	merge(value, b)
-	return value
 }
 
 // Join returns the least upper bound of (a, b).
+// The result shares no maps, slices, or pointers with a or b, so it can be modified without affecting them.
 // Both a and b must be mergeable values of the same type.
 func Join(a, b interface{}) interface{} {
-	aVal := reflect.ValueOf(a)
-	bVal := reflect.ValueOf(b)
-	if aVal.Type() != bVal.Type() {
+	result, err := JoinWith(a, b)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// JoinAll returns the least upper bound of values, which must be mergeable values of the same type.
+// It panics if values is empty.
+func JoinAll(values ...interface{}) interface{} {
+	if len(values) == 0 {
+		panic("JoinAll needs at least one value")
+	}
+	result := values[0]
+	for _, value := range values[1:] {
+		result = Join(result, value)
+	}
+	return result
+}
+
+// JoinT is a typed form of Join: it returns the least upper bound of (a, b).
+func JoinT[T any](a, b T) T {
+	return Join(a, b).(T)
+}
+
+// WouldChange returns true if merging b into a would modify a, that is, if b is not less than or equal to a.
+// a is not modified. a and b must be mergeable values of the same type.
+func WouldChange(a, b interface{}) bool {
+	_, changed := joinChanged(reflect.ValueOf(a), reflect.ValueOf(b))
+	return changed
+}
+
+// JoinChanged returns the least upper bound of (a, b), and whether it differs from a,
+// as WouldChange(a, b) would report, in one merge.
+func JoinChanged[T any](a, b T) (T, bool) {
+	value, changed := joinChanged(reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem())
+	return value.Interface().(T), changed
+}
+
+// joinChanged merges b into a copy of a, and returns the copy and whether the merge modified it.
+func joinChanged(a, b reflect.Value) (reflect.Value, bool) {
+	if a.Type() != b.Type() {
 		panic("a and b must be the same type")
 	}
-	return join(aVal, bVal).Interface()
+	value := deepCopy(a)
+	changed, err := new(merger).run(value, b)
+	if err != nil {
+		panic(err)
+	}
+	return value, changed
+}
+
+// MergeMapT merges the map b into the map a keywise, merging the values of keys in both by the package's rules,
+// and returns a and whether it was modified. If a is nil and b isn't empty, a new map is allocated and returned.
+func MergeMapT[K comparable, V any](a, b map[K]V) (map[K]V, bool) {
+	if a == nil && len(b) > 0 {
+		a = make(map[K]V, len(b))
+	}
+	changed := Merge(&a, b)
+	return a, changed
 }
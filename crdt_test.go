@@ -2,7 +2,10 @@ package crdt
 
 import (
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 type decreasingInt int
@@ -34,6 +37,78 @@ func TestMergeMerger(t *testing.T) {
 	testMerge(-2, true, -2)
 }
 
+// priority is ordered so that smaller numbers are more urgent, and therefore greater.
+type priority int
+
+func (p priority) Compare(other interface{}) int {
+	return int(other.(priority)) - int(p)
+}
+
+func TestMergeComparable(t *testing.T) {
+	type A struct {
+		P priority
+	}
+	testJoin := func(a, b, expected A) {
+		if result := Join(a, b); result != expected {
+			t.Errorf("Join(%#v, %#v) = %#v, expected %#v", a, b, result, expected)
+		}
+	}
+	testJoin(A{1}, A{2}, A{1})
+	testJoin(A{2}, A{1}, A{1})
+	testJoin(A{3}, A{3}, A{3})
+	// Zero is the bottom value, even though Compare would rank it above 1.
+	testJoin(A{0}, A{2}, A{2})
+	testJoin(A{2}, A{0}, A{2})
+	testJoin(A{-1}, A{1}, A{-1})
+}
+
+// rank is ordered so that earlier strings are greater, and treats blank strings as zero.
+type rank string
+
+func (r rank) Compare(other interface{}) int {
+	return strings.Compare(string(other.(rank)), string(r))
+}
+
+func (r rank) IsZero() bool {
+	return strings.TrimSpace(string(r)) == ""
+}
+
+// blank is a string that treats blank strings as zero.
+type blank string
+
+func (b blank) IsZero() bool {
+	return strings.TrimSpace(string(b)) == ""
+}
+
+func TestMergeIsZero(t *testing.T) {
+	testJoin := func(a, b, expected interface{}) {
+		if result := Join(a, b); result != expected {
+			t.Errorf("Join(%#v, %#v) = %#v, expected %#v", a, b, result, expected)
+		}
+	}
+	// A blank rank is bottom, even though it isn't Go's zero value and Compare would rank it first.
+	testJoin(rank(" "), rank("b"), rank("b"))
+	testJoin(rank("b"), rank(" "), rank("b"))
+	testJoin(rank("a"), rank("b"), rank("a"))
+	// A blank string is bottom, even though it's greater than the empty string.
+	testJoin(blank(" "), blank(""), blank(""))
+	testJoin(blank(" "), blank("a"), blank("a"))
+
+	// Zero is bottom for signed numbers too.
+	testJoin(-1, 0, -1)
+	testJoin(0, -1, -1)
+	testJoin(-1, -2, -1)
+
+	// A nil pointer is bottom without calling the IsZero method of the type it points to.
+	times := map[string]*time.Time{"a": nil}
+	if !Equal(times, map[string]*time.Time{}) {
+		t.Errorf("a map of nil *time.Time isn't Equal to an empty one")
+	}
+	if !Compact(&times) || len(times) != 0 {
+		t.Errorf("Compact left %v, expected an empty map", times)
+	}
+}
+
 func TestMergeStruct(t *testing.T) {
 	type A struct {
 		I int
@@ -80,6 +155,103 @@ func TestMergeMap(t *testing.T) {
 	testMerge(A{1: 1, 2: 0}, false, A{1: 1, 2: 1})
 }
 
+func TestMergeEmptyStruct(t *testing.T) {
+	var empty struct{}
+	if Merge(&empty, struct{}{}) {
+		t.Errorf("Merge of struct{} values changed a")
+	}
+	if !isZero(reflect.ValueOf(empty)) {
+		t.Errorf("struct{} is not the bottom value")
+	}
+
+	type A struct {
+		Done struct{}
+		N    int
+	}
+	value := A{}
+	if Merge(&value, A{}) {
+		t.Errorf("Merge of zero structs with a struct{} field changed a")
+	}
+	if !Merge(&value, A{N: 1}) || value != (A{N: 1}) {
+		t.Errorf("After merge was %#v, expected %#v", value, A{N: 1})
+	}
+	if Merge(&value, A{}) {
+		t.Errorf("Merge of struct{} field changed a")
+	}
+
+	type Set map[string]struct{}
+	var set Set
+	testMerge := func(other Set, expectedChanged bool, expectedResult Set) {
+		changed := Merge(&set, other)
+		if changed != expectedChanged {
+			t.Errorf("Merge(a, %#v) = %v, expected %v", other, changed, expectedChanged)
+		}
+		if !reflect.DeepEqual(set, expectedResult) {
+			t.Fatalf("After merge was %#v, expected %#v", set, expectedResult)
+		}
+	}
+	testMerge(Set{"a": {}}, true, Set{"a": {}})
+	testMerge(Set{"a": {}}, false, Set{"a": {}})
+	testMerge(Set{"b": {}}, true, Set{"a": {}, "b": {}})
+	testMerge(Set{}, false, Set{"a": {}, "b": {}})
+	testMerge(Set{"a": {}, "b": {}}, false, Set{"a": {}, "b": {}})
+}
+
+func TestMergeInterfaceKeys(t *testing.T) {
+	type point struct{ X, Y int }
+	a := map[interface{}]int{1: 1, "1": 2, int64(1): 3, point{1, 2}: 1}
+	b := map[interface{}]int{1: 5, "1": 1, uint(1): 7, point{1, 2}: 4, [2]string{"x", "y"}: 1}
+	expected := map[interface{}]int{1: 5, "1": 2, int64(1): 3, uint(1): 7, point{1, 2}: 4, [2]string{"x", "y"}: 1}
+	if !Merge(&a, b) || !reflect.DeepEqual(a, expected) {
+		t.Errorf("Merge gave %v, expected %v", a, expected)
+	}
+	if Merge(&a, b) {
+		t.Errorf("merging b again reported a change")
+	}
+	var paths []string
+	Walk(map[interface{}]int{1: 1, "1": 1, int64(1): 1}, func(path string, value interface{}) {
+		paths = append(paths, path)
+	})
+	sort.Strings(paths)
+	if expected := []string{"[int(1)]", "[int64(1)]", "[string(1)]"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Walk visited %q, expected %q", paths, expected)
+	}
+
+	type counts struct{ N int }
+	c := map[interface{}]counts{1: {1}, "1": {2}}
+	Merge(&c, map[interface{}]counts{1: {3}, 1.5: {1}})
+	if len(c) != 3 || c[1].N != 3 || c["1"].N != 2 || c[1.5].N != 1 {
+		t.Errorf("Merge of struct values gave %v", c)
+	}
+}
+
+func TestMergePointer(t *testing.T) {
+	type Inner struct {
+		M map[string]int
+	}
+	type A struct {
+		P *Inner
+	}
+	source := A{&Inner{map[string]int{"a": 1}}}
+	var value A
+	if !Merge(&value, source) {
+		t.Errorf("Merge(nil, b) = false, expected true")
+	}
+	if value.P == source.P || !reflect.DeepEqual(value, source) {
+		t.Errorf("After merge was %#v, expected a copy of %#v", value, source)
+	}
+	source.P.M["a"] = 2
+	if value.P.M["a"] != 1 {
+		t.Errorf("mutating source changed the merged value")
+	}
+	if !Merge(&value, source) || value.P.M["a"] != 2 {
+		t.Errorf("After merge was %#v, expected a=2", value.P.M)
+	}
+	if Merge(&value, A{}) {
+		t.Errorf("Merge(a, nil) = true, expected false")
+	}
+}
+
 func TestJoin(t *testing.T) {
 	testJoin := func(a, b, expected interface{}) {
 		if result := Join(a, b); !reflect.DeepEqual(result, expected) {
@@ -134,4 +306,193 @@ func TestJoin(t *testing.T) {
 	// string
 	testJoin("foo", "bar", "foo")
 	testJoin("bar", "foo", "foo")
+
+	// named ordered types
+	type level int
+	testJoin(level(0), level(1), level(1))
+	testJoin(level(1), level(0), level(1))
+}
+
+func TestMergePtr(t *testing.T) {
+	type A struct {
+		I int
+		M map[string]int
+	}
+	testMerge := func(a, b A) {
+		viaMerge := A{a.I, map[string]int{}}
+		viaPtr := A{a.I, map[string]int{}}
+		viaMergeRef := A{a.I, map[string]int{}}
+		for k, v := range a.M {
+			viaMerge.M[k], viaPtr.M[k], viaMergeRef.M[k] = v, v, v
+		}
+		bCopy := A{b.I, map[string]int{}}
+		for k, v := range b.M {
+			bCopy.M[k] = v
+		}
+		mergeChanged := Merge(&viaMerge, b)
+		ptrChanged := MergePtr(&viaPtr, &b)
+		if mergeChanged != ptrChanged || !reflect.DeepEqual(viaMerge, viaPtr) {
+			t.Errorf("MergePtr(%#v, %#v) = (%#v, %v), Merge gave (%#v, %v)", a, b, viaPtr, ptrChanged, viaMerge, mergeChanged)
+		}
+		refChanged := Merge(&viaMergeRef, &b)
+		if mergeChanged != refChanged || !reflect.DeepEqual(viaMerge, viaMergeRef) {
+			t.Errorf("Merge(%#v, &%#v) = (%#v, %v), Merge by value gave (%#v, %v)", a, b, viaMergeRef, refChanged, viaMerge, mergeChanged)
+		}
+		if !reflect.DeepEqual(b, bCopy) {
+			t.Errorf("merging by reference modified b: %#v, expected %#v", b, bCopy)
+		}
+	}
+	testMerge(A{}, A{1, map[string]int{"a": 1}})
+	testMerge(A{2, map[string]int{"a": 2}}, A{1, map[string]int{"a": 1}})
+	testMerge(A{1, map[string]int{"a": 1}}, A{1, map[string]int{"a": 2, "b": 1}})
+}
+
+func TestMergeSliceElem(t *testing.T) {
+	type A struct {
+		I int
+		M map[string]int
+	}
+	s := []A{{1, map[string]int{"a": 1}}, {2, nil}, {3, map[string]int{"c": 3}}}
+	if !MergeSliceElem(s, 1, A{1, map[string]int{"b": 2}}) {
+		t.Errorf("MergeSliceElem reported no change")
+	}
+	expected := []A{{1, map[string]int{"a": 1}}, {2, map[string]int{"b": 2}}, {3, map[string]int{"c": 3}}}
+	if !reflect.DeepEqual(s, expected) {
+		t.Errorf("MergeSliceElem gave %#v, expected %#v", s, expected)
+	}
+	if MergeSliceElem(&s, 1, &A{2, map[string]int{"b": 1}}) {
+		t.Errorf("MergeSliceElem reported a change merging a lesser value")
+	}
+	if !reflect.DeepEqual(s, expected) {
+		t.Errorf("MergeSliceElem of a lesser value gave %#v, expected %#v", s, expected)
+	}
+	for _, i := range []int{-1, 3} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("MergeSliceElem(s, %d, ...) didn't panic", i)
+				}
+			}()
+			MergeSliceElem(s, i, A{})
+		}()
+	}
+}
+
+// largeState is a struct big enough that copying it costs something.
+type largeState struct {
+	F0, F1, F2, F3, F4, F5, F6, F7, F8, F9, F10, F11, F12, F13, F14, F15 flatStruct
+}
+
+func BenchmarkMergeLarge(b *testing.B) {
+	var x, y largeState
+	y.F15.B = 1
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Merge(&x, y)
+	}
+}
+
+// BenchmarkMergeRefLarge merges b by reference through Merge:
+// unlike in BenchmarkMergeLarge, b isn't copied into an interface value on every call.
+func BenchmarkMergeRefLarge(b *testing.B) {
+	var x, y largeState
+	y.F15.B = 1
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Merge(&x, &y)
+	}
+}
+
+func BenchmarkMergePtrLarge(b *testing.B) {
+	var x, y largeState
+	y.F15.B = 1
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		MergePtr(&x, &y)
+	}
+}
+
+func TestMergeIf(t *testing.T) {
+	type versioned struct {
+		Version int
+		Data    map[string]int
+	}
+	newer := func(a, b interface{}) bool {
+		return b.(versioned).Version > a.(*versioned).Version
+	}
+	a := versioned{2, map[string]int{"x": 1}}
+	if MergeIf(&a, versioned{1, map[string]int{"x": 5}}, newer) {
+		t.Errorf("MergeIf accepted a stale value")
+	}
+	if a.Data["x"] != 1 {
+		t.Errorf("MergeIf merged a stale value: %#v", a)
+	}
+	if !MergeIf(&a, versioned{3, map[string]int{"x": 5}}, newer) {
+		t.Errorf("MergeIf rejected a newer value")
+	}
+	if a.Version != 3 || a.Data["x"] != 5 {
+		t.Errorf("MergeIf didn't merge a newer value: %#v", a)
+	}
+}
+
+func TestJoinAll(t *testing.T) {
+	joined := JoinAll(map[string]int{"a": 1}, map[string]int{"a": 3}, map[string]int{"b": 2})
+	if expected := map[string]int{"a": 3, "b": 2}; !reflect.DeepEqual(joined, expected) {
+		t.Errorf("JoinAll gave %v, expected %v", joined, expected)
+	}
+	if joined := JoinAll(5); joined != 5 {
+		t.Errorf("JoinAll of one value gave %v", joined)
+	}
+}
+
+func TestJoinChanged(t *testing.T) {
+	type state struct {
+		Count int
+		Seen  map[string]bool
+	}
+	for _, test := range []struct {
+		a, b state
+	}{
+		{state{1, map[string]bool{"x": true}}, state{2, nil}},
+		{state{2, map[string]bool{"x": true}}, state{1, map[string]bool{"x": true}}},
+		{state{2, map[string]bool{"x": true}}, state{2, map[string]bool{"y": true}}},
+		{state{}, state{}},
+	} {
+		joined, changed := JoinChanged(test.a, test.b)
+		if wouldChange := WouldChange(test.a, test.b); changed != wouldChange {
+			t.Errorf("JoinChanged(%v, %v) reported %v, WouldChange reported %v", test.a, test.b, changed, wouldChange)
+		}
+		if expected := JoinT(test.a, test.b); !Equal(joined, expected) {
+			t.Errorf("JoinChanged(%v, %v) = %v, JoinT gave %v", test.a, test.b, joined, expected)
+		}
+		if changed == Equal(joined, test.a) {
+			t.Errorf("JoinChanged(%v, %v) reported %v, but the join is %v", test.a, test.b, changed, joined)
+		}
+	}
+}
+
+func TestMergeMapT(t *testing.T) {
+	counts, changed := MergeMapT(map[string]int{"a": 1, "b": 5}, map[string]int{"b": 3, "c": 2})
+	if expected := map[string]int{"a": 1, "b": 5, "c": 2}; !changed || !reflect.DeepEqual(counts, expected) {
+		t.Errorf("MergeMapT gave %v, %v, expected %v, true", counts, changed, expected)
+	}
+	if _, changed := MergeMapT(counts, map[string]int{"a": 1}); changed {
+		t.Errorf("MergeMapT of a contained map reported a change")
+	}
+	disjoint, changed := MergeMapT(nil, map[string]int{"x": 1})
+	if !changed || !reflect.DeepEqual(disjoint, map[string]int{"x": 1}) {
+		t.Errorf("MergeMapT(nil, ...) gave %v, %v", disjoint, changed)
+	}
+
+	type user struct {
+		Name   string
+		Logins int
+	}
+	users, changed := MergeMapT(
+		map[string]user{"alice": {"Alice", 2}},
+		map[string]user{"alice": {"Alice", 3}, "bob": {"Bob", 1}},
+	)
+	if expected := map[string]user{"alice": {"Alice", 3}, "bob": {"Bob", 1}}; !changed || !reflect.DeepEqual(users, expected) {
+		t.Errorf("MergeMapT gave %v, %v, expected %v, true", users, changed, expected)
+	}
 }
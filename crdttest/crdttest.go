@@ -0,0 +1,27 @@
+// Package crdttest provides helpers for testing code that produces CRDT states,
+// such as delta generators, kept separate so that package crdt doesn't depend on package testing.
+package crdttest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kevinwallace/crdt"
+)
+
+// AssertJoinEq reports a test failure if the join of parts (see crdt.JoinAll) isn't equal to full
+// as a CRDT state (see crdt.Equal). With no parts, full must be equal to the zero value of its type.
+// It is useful for checking that a set of deltas reconstructs the state they were taken from.
+// It returns true if the assertion held.
+func AssertJoinEq(t testing.TB, full interface{}, parts ...interface{}) bool {
+	t.Helper()
+	joined := reflect.Zero(reflect.TypeOf(full)).Interface()
+	if len(parts) > 0 {
+		joined = crdt.JoinAll(parts...)
+	}
+	if !crdt.Equal(joined, full) {
+		t.Errorf("join of %d parts is %+v, expected %+v", len(parts), joined, full)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package crdttest
+
+import (
+	"testing"
+
+	"github.com/kevinwallace/crdt"
+)
+
+// recorder is a testing.TB that records failures rather than failing the test.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func TestAssertJoinEq(t *testing.T) {
+	type state struct {
+		Counts map[string]int
+		Tags   []string `crdt:"set"`
+	}
+	base := state{Counts: map[string]int{"a": 1}}
+	full := state{Counts: map[string]int{"a": 3, "b": 2}, Tags: []string{"x"}}
+	delta := crdt.DeltaFor(full, base).(state)
+	parts := []interface{}{base, delta}
+
+	if !AssertJoinEq(t, full, parts...) {
+		t.Errorf("AssertJoinEq failed for parts that join to full")
+	}
+	r := &recorder{TB: t}
+	if AssertJoinEq(r, full, base) || !r.failed {
+		t.Errorf("AssertJoinEq passed with a missing part")
+	}
+	r = &recorder{TB: t}
+	if AssertJoinEq(r, full) || !r.failed {
+		t.Errorf("AssertJoinEq passed with no parts")
+	}
+	if !AssertJoinEq(t, state{}) {
+		t.Errorf("AssertJoinEq failed for the zero value with no parts")
+	}
+}
@@ -0,0 +1,132 @@
+package crdttest
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/kevinwallace/crdt"
+)
+
+// maxFuzzDepth bounds how deeply FuzzMerge nests the pointers, maps, and slices it decodes,
+// so that recursive types decode to finite values.
+const maxFuzzDepth = 6
+
+// FuzzMerge decodes three values of typ's type from data, and reports a test failure if their joins
+// (see crdt.Join) aren't commutative, associative, and idempotent, with results compared as CRDT states
+// (see crdt.Equal). It is meant to be called from the function passed to testing.F.Fuzz, with a typ
+// that exercises the merge rules under test:
+//
+//	func FuzzState(f *testing.F) {
+//		f.Add([]byte("seed"))
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			crdttest.FuzzMerge(t, State{}, data)
+//		})
+//	}
+//
+// Any data decodes, to zero values once it runs out. Values are drawn from small ranges, so that decoded
+// values often collide: integers from -128 to 127, strings of up to three letters from "abc",
+// and up to three map entries or slice elements. Floats are drawn from quarter steps, including -0,
+// but never NaN, which is merged deterministically but isn't DeepEqual to itself. Unexported struct fields,
+// interfaces, channels, and funcs are left zero. It returns true if the laws held.
+func FuzzMerge(t testing.TB, typ interface{}, data []byte) bool {
+	t.Helper()
+	src := &fuzzSource{data: data}
+	values := make([]interface{}, 3)
+	for i := range values {
+		v := reflect.New(reflect.TypeOf(typ)).Elem()
+		src.fill(v, 0)
+		values[i] = v.Interface()
+	}
+	a, b, c := values[0], values[1], values[2]
+	held := true
+	if ab, ba := crdt.Join(a, b), crdt.Join(b, a); !crdt.Equal(ab, ba) {
+		t.Errorf("join isn't commutative: join(%+v, %+v) is %+v, but the reverse is %+v", a, b, ab, ba)
+		held = false
+	}
+	if left, right := crdt.Join(crdt.Join(a, b), c), crdt.Join(a, crdt.Join(b, c)); !crdt.Equal(left, right) {
+		t.Errorf("join isn't associative: joining %+v, %+v, %+v from the left is %+v, from the right %+v", a, b, c, left, right)
+		held = false
+	}
+	if aa := crdt.Join(a, a); !crdt.Equal(aa, a) {
+		t.Errorf("join isn't idempotent: join(%+v, %+v) is %+v", a, a, aa)
+		held = false
+	}
+	return held
+}
+
+// fuzzSource decodes values from fuzzer-provided bytes.
+type fuzzSource struct {
+	data []byte
+}
+
+// next consumes and returns the next byte of data, or 0 once it is exhausted.
+func (s *fuzzSource) next() byte {
+	if len(s.data) == 0 {
+		return 0
+	}
+	b := s.data[0]
+	s.data = s.data[1:]
+	return b
+}
+
+// fill sets the settable, zero v to a value decoded from the data, at the given nesting depth.
+func (s *fuzzSource) fill(v reflect.Value, depth int) {
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(s.next()%2 == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(int8(s.next())))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(s.next()))
+	case reflect.Float32, reflect.Float64:
+		if b := s.next(); b == 0x80 {
+			v.SetFloat(math.Copysign(0, -1))
+		} else {
+			v.SetFloat(float64(int8(b)) / 4)
+		}
+	case reflect.String:
+		letters := make([]byte, s.next()%4)
+		for i := range letters {
+			letters[i] = 'a' + s.next()%3
+		}
+		v.SetString(string(letters))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			s.fill(v.Index(i), depth)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				s.fill(v.Field(i), depth)
+			}
+		}
+	case reflect.Ptr:
+		if depth < maxFuzzDepth && s.next()%2 == 1 {
+			v.Set(reflect.New(v.Type().Elem()))
+			s.fill(v.Elem(), depth+1)
+		}
+	case reflect.Slice:
+		if depth >= maxFuzzDepth {
+			return
+		}
+		n := int(s.next() % 4)
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n; i++ {
+			s.fill(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		if depth >= maxFuzzDepth {
+			return
+		}
+		n := int(s.next() % 4)
+		v.Set(reflect.MakeMapWithSize(v.Type(), n))
+		for i := 0; i < n; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			s.fill(key, depth+1)
+			value := reflect.New(v.Type().Elem()).Elem()
+			s.fill(value, depth+1)
+			v.SetMapIndex(key, value)
+		}
+	}
+}
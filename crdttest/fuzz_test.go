@@ -0,0 +1,55 @@
+package crdttest
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fuzzInner struct {
+	N     int
+	Label string
+	Next  *fuzzInner
+}
+
+type fuzzState struct {
+	Counts map[string]int
+	Nested fuzzInner
+	Ptr    *fuzzInner
+	ByKey  map[string]*fuzzInner
+	Items  []int
+	Tags   []string `crdt:"set"`
+	Ratio  float64
+	On     bool
+}
+
+func FuzzMergeLaws(f *testing.F) {
+	f.Add([]byte{})
+	// Maps of counts and of pointers, with colliding keys.
+	f.Add([]byte{2, 1, 0, 5, 1, 0, 250, 0, 0, 0, 0, 0, 2, 1, 1, 1, 3, 1, 1, 0, 0, 9})
+	// Nested structs and pointer chains.
+	f.Add([]byte{0, 7, 2, 0, 1, 1, 3, 0, 0, 1, 200, 1, 2, 1, 1, 4, 1, 2, 0, 0})
+	// Slices, sets, and signed zero floats.
+	f.Add([]byte{0, 0, 0, 0, 0, 0, 0, 3, 1, 2, 3, 3, 1, 2, 1, 0, 1, 1, 0x80, 1, 0, 0, 0, 0, 0, 0, 0, 2, 9, 8, 1, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		FuzzMerge(t, fuzzState{}, data)
+	})
+}
+
+func TestFuzzMergeDecode(t *testing.T) {
+	data := []byte{1, 1, 1, 5, 3, 1}
+	src := &fuzzSource{data: data}
+	var a, b fuzzState
+	src.fill(reflect.ValueOf(&a).Elem(), 0)
+	src = &fuzzSource{data: data}
+	src.fill(reflect.ValueOf(&b).Elem(), 0)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("decoding the same data twice gave %+v and %+v", a, b)
+	}
+	if a.Counts["b"] != 5 {
+		t.Errorf("decoded %+v, expected Counts[b] = 5", a)
+	}
+	r := &recorder{TB: t}
+	if !FuzzMerge(r, fuzzState{}, data) || r.failed {
+		t.Errorf("FuzzMerge reported a law violation for %+v", a)
+	}
+}
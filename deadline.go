@@ -0,0 +1,31 @@
+package crdt
+
+import "time"
+
+// MergeDeadline sets the value of a to the least upper bound of (a, b), like Merge,
+// but stops merging map keys once deadline has passed, so that the merge takes roughly a bounded time.
+// It returns true if the value of a was modified, and whether the merge was complete.
+// A merge only ever moves a up, so an incomplete merge still leaves a valid state that lies
+// between a and the full merge, and merging the same b again, with a new deadline, completes it.
+// At least one map entry is merged before the deadline is checked.
+// The arguments are as described for Merge.
+func MergeDeadline(a, b interface{}, deadline time.Time) (changed, complete bool) {
+	m := newMerger([]Option{func(c *config) {
+		c.deadline = deadline
+	}})
+	changed, err := m.run(mergeArgs(a, b))
+	if err != nil {
+		panic(err)
+	}
+	return changed, !m.expired
+}
+
+// expire returns true, and stops the merge from merging any more map keys, if the merge's deadline
+// has passed and it has merged at least one map entry. Merges that copy values never stop,
+// since a partial copy would lose some of a's value.
+func (m *merger) expire() bool {
+	if !m.expired && m.progressed && !m.copying && !m.deadline.IsZero() && time.Now().After(m.deadline) {
+		m.expired = true
+	}
+	return m.expired
+}
@@ -0,0 +1,39 @@
+package crdt
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMergeDeadline(t *testing.T) {
+	b := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		b[fmt.Sprint(i)] = i + 1
+	}
+	a := map[string]int{"-": 1}
+	changed, complete := MergeDeadline(&a, b, time.Now().Add(-time.Millisecond))
+	if !changed || complete {
+		t.Errorf("MergeDeadline with a past deadline returned (%v, %v), expected (true, false)", changed, complete)
+	}
+	if len(a) < 2 || len(a) > len(b) {
+		t.Errorf("MergeDeadline with a past deadline merged %d of %d keys, expected some but not all", len(a), len(b))
+	}
+	for key, value := range a {
+		if expected := Join(map[string]int{"-": 1}, b).(map[string]int)[key]; value != expected {
+			t.Errorf("a[%s] = %d after a partial merge, expected %d", key, value, expected)
+		}
+	}
+
+	if _, complete = MergeDeadline(&a, b, time.Now().Add(time.Minute)); !complete {
+		t.Errorf("MergeDeadline with a future deadline was incomplete")
+	}
+	if len(a) != len(b)+1 {
+		t.Errorf("retrying MergeDeadline until complete gave %d keys, expected %d", len(a), len(b)+1)
+	}
+
+	changed, complete = MergeDeadline(&a, map[string]int{"x": 1}, time.Now().Add(time.Minute))
+	if !changed || !complete || a["x"] != 1 {
+		t.Errorf("MergeDeadline with a future deadline returned (%v, %v)", changed, complete)
+	}
+}
@@ -0,0 +1,147 @@
+package crdt
+
+import (
+	"reflect"
+	"sync"
+)
+
+// delta returns a value containing the parts of a that are not already in b:
+// the map entries and leaves (as defined by Walk) at which a differs from b, with everything else zero.
+// Records (structs with a `crdt:"primary"` field), unions, and fixed-point numbers are merged whole,
+// so they are included whole if they differ, as are fields tagged `crdt:"lww=Field"` whose siblings differ.
+// tag holds the `crdt` tag options in effect for a and b.
+// If a is an inflation of b, Join(b, delta(a, b)) equals a.
+func delta(a, b reflect.Value, tag tagOptions) reflect.Value {
+	d := reflect.New(a.Type()).Elem()
+	switch {
+	case isLeaf(a.Type()) && a.Kind() != reflect.Ptr:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			copyInto(d, a)
+		}
+	case a.Kind() == reflect.Ptr:
+		if a.IsNil() {
+			break
+		}
+		if b.IsNil() {
+			copyInto(d, a)
+			break
+		}
+		if elem := delta(a.Elem(), b.Elem(), tag); !elem.IsZero() {
+			d.Set(reflect.New(a.Type().Elem()))
+			d.Elem().Set(elem)
+		}
+	case a.Kind() == reflect.Struct && (recordFields(a.Type()) != nil || tag.has("union") || tag.has("fixedpoint")):
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			copyInto(d, a)
+		}
+	case a.Kind() == reflect.Struct:
+		// A field tagged lww=Field whose sibling differs is taken whole by the side whose sibling wins,
+		// so it is included whole.
+		tags := fieldTags(a.Type())
+		siblings := lwwSiblings(a, b, tags)
+		for i := 0; i < a.NumField(); i++ {
+			if !d.Field(i).CanSet() {
+				continue
+			}
+			if siblings[i] != 0 {
+				copyInto(d.Field(i), a.Field(i))
+			} else {
+				d.Field(i).Set(delta(a.Field(i), b.Field(i), tags[i]))
+			}
+		}
+		// Fields tagged since are only merged from states of their version or later,
+		// so a non-empty delta carries a's version field even where b's is the same.
+		if !d.IsZero() {
+			for i, opts := range tags {
+				if opts.has("version") && d.Field(i).CanSet() {
+					d.Field(i).Set(a.Field(i))
+				}
+			}
+		}
+	case a.Kind() == reflect.Map:
+		iter := a.MapRange()
+		for iter.Next() {
+			bValue := b.MapIndex(iter.Key())
+			var value reflect.Value
+			if !bValue.IsValid() {
+				value = deepCopy(iter.Value())
+			} else if reflect.DeepEqual(iter.Value().Interface(), bValue.Interface()) {
+				continue
+			} else {
+				value = delta(iter.Value(), bValue, tag)
+			}
+			if d.IsNil() {
+				d.Set(reflect.MakeMap(a.Type()))
+			}
+			d.SetMapIndex(iter.Key(), value)
+		}
+	}
+	return d
+}
+
+// DeltaFor returns the delta to send to a peer whose state is remote so that it catches up with local:
+// a value such that Join(remote, DeltaFor(local, remote)) equals Join(local, remote).
+// It contains only the map entries and leaves at which local exceeds remote, and the records
+// (structs with a `crdt:"primary"` field), unions, fixed-point numbers, and last-writer-wins fields
+// that local wins, whole, with everything else zero.
+// A map key that remote lacks is treated as holding the bottom value there, so its entry is included
+// whole, even if local's value is zero, while a key that remote has is included only where local's
+// value strictly exceeds remote's: entries that local ties or that remote dominates are left out.
+// local and remote must be mergeable values of the same type.
+func DeltaFor(local, remote interface{}) interface{} {
+	localVal := reflect.ValueOf(local)
+	remoteVal := reflect.ValueOf(remote)
+	if localVal.Type() != remoteVal.Type() {
+		panic("local and remote must be the same type")
+	}
+	return delta(join(remoteVal, localVal), remoteVal, nil).Interface()
+}
+
+// DeltaBuffer wraps a CRDT value and records the delta produced by each local mutation made through it,
+// so that replicas can send each other small deltas rather than their full states.
+// Merging every drained delta into a peer has the same effect as merging the full state.
+// A DeltaBuffer is safe for concurrent use.
+type DeltaBuffer struct {
+	mu    sync.Mutex
+	state reflect.Value
+	delta reflect.Value
+}
+
+// NewDeltaBuffer returns a DeltaBuffer wrapping the value pointed to by state.
+// The value must only be mutated through the DeltaBuffer from then on.
+func NewDeltaBuffer(state interface{}) *DeltaBuffer {
+	v := reflect.ValueOf(state)
+	if v.Kind() != reflect.Ptr {
+		panic("state must be a pointer")
+	}
+	return &DeltaBuffer{state: v.Elem(), delta: reflect.New(v.Elem().Type()).Elem()}
+}
+
+// Mutate calls fn, which may mutate the wrapped value, and records the delta it produces.
+// Mutations must be inflations: they may only move the value up in the lattice.
+// Computing the delta copies and compares the whole value, so it costs time proportional to its size.
+func (d *DeltaBuffer) Mutate(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	before := deepCopy(d.state)
+	fn()
+	merge(d.delta, delta(d.state, before, nil))
+}
+
+// Merge merges b, a value of the wrapped value's type, into the wrapped value, without recording a delta:
+// changes that came from elsewhere needn't be sent on. It returns true if the wrapped value was modified.
+func (d *DeltaBuffer) Merge(b interface{}) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return merge(d.state, reflect.ValueOf(b))
+}
+
+// Drain returns the delta accumulated since the last call to Drain, and starts accumulating a new one.
+// The delta is a value of the wrapped value's type, ready to be merged into a peer's state.
+func (d *DeltaBuffer) Drain() interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drained := d.delta.Interface()
+	d.delta = reflect.New(d.state.Type()).Elem()
+	return drained
+}
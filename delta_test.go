@@ -0,0 +1,172 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type deltaState struct {
+	Name   string
+	Counts map[string]int
+	Users  map[string]map[string]int
+}
+
+func TestDeltaBuffer(t *testing.T) {
+	initial := deltaState{"a", map[string]int{"x": 1, "y": 1}, map[string]map[string]int{"alice": {"logins": 1}}}
+	state := Join(deltaState{}, initial).(deltaState)
+	buffer := NewDeltaBuffer(&state)
+	full := Join(deltaState{}, initial).(deltaState)
+	viaDeltas := Join(deltaState{}, initial).(deltaState)
+
+	buffer.Mutate(func() {
+		state.Counts["x"] = 5
+	})
+	buffer.Mutate(func() {
+		state.Counts["z"] = 1
+		state.Users["alice"]["logins"] = 2
+	})
+	first := buffer.Drain().(deltaState)
+	expected := deltaState{"", map[string]int{"x": 5, "z": 1}, map[string]map[string]int{"alice": {"logins": 2}}}
+	if !reflect.DeepEqual(first, expected) {
+		t.Errorf("first delta = %#v, expected %#v", first, expected)
+	}
+	Merge(&viaDeltas, first)
+
+	buffer.Mutate(func() {
+		state.Name = "b"
+		state.Users["bob"] = map[string]int{"logins": 1}
+	})
+	Merge(&viaDeltas, buffer.Drain())
+	if drained := buffer.Drain().(deltaState); !reflect.DeepEqual(drained, deltaState{}) {
+		t.Errorf("delta after drain = %#v, expected empty", drained)
+	}
+
+	Merge(&full, state)
+	if !reflect.DeepEqual(viaDeltas, full) {
+		t.Errorf("peer merging deltas has %#v, peer merging full state has %#v", viaDeltas, full)
+	}
+}
+
+func TestDeltaBufferMerge(t *testing.T) {
+	state := deltaState{Counts: map[string]int{"x": 1}}
+	buffer := NewDeltaBuffer(&state)
+	if !buffer.Merge(deltaState{Counts: map[string]int{"x": 2}}) {
+		t.Errorf("Merge = false, expected true")
+	}
+	if state.Counts["x"] != 2 {
+		t.Errorf("After merge x = %d, expected 2", state.Counts["x"])
+	}
+	if drained := buffer.Drain().(deltaState); !reflect.DeepEqual(drained, deltaState{}) {
+		t.Errorf("delta after remote merge = %#v, expected empty", drained)
+	}
+}
+
+func TestDeltaFor(t *testing.T) {
+	local := deltaState{"b", map[string]int{"x": 5, "y": 1}, map[string]map[string]int{"alice": {"logins": 2, "posts": 1}, "bob": {"logins": 1}}}
+	remote := deltaState{"a", map[string]int{"x": 1, "y": 1, "z": 3}, map[string]map[string]int{"alice": {"logins": 3, "posts": 1}}}
+
+	d := DeltaFor(local, remote).(deltaState)
+	expected := deltaState{"b", map[string]int{"x": 5}, map[string]map[string]int{"bob": {"logins": 1}}}
+	if !reflect.DeepEqual(d, expected) {
+		t.Errorf("DeltaFor(local, remote) = %#v, expected %#v", d, expected)
+	}
+	if got, want := Join(remote, d), Join(local, remote); !Equal(got, want) {
+		t.Errorf("Join(remote, DeltaFor(local, remote)) = %#v, expected %#v", got, want)
+	}
+	if got, want := Join(local, DeltaFor(remote, local)), Join(local, remote); !Equal(got, want) {
+		t.Errorf("Join(local, DeltaFor(remote, local)) = %#v, expected %#v", got, want)
+	}
+	if d := DeltaFor(remote, remote); !Equal(d, deltaState{}) {
+		t.Errorf("DeltaFor(remote, remote) = %#v, expected the zero value", d)
+	}
+}
+
+func TestDeltaForRecord(t *testing.T) {
+	type record struct {
+		Score int `crdt:"primary"`
+		When  int
+	}
+	local, remote := record{Score: 5, When: 9}, record{Score: 3, When: 9}
+	d := DeltaFor(local, remote).(record)
+	if d != local {
+		t.Errorf("DeltaFor(%v, %v) = %v, expected the whole record %v", local, remote, d, local)
+	}
+	if got := Join(remote, d); got != local {
+		t.Errorf("Join(remote, DeltaFor(local, remote)) = %v, expected %v", got, local)
+	}
+	if d := DeltaFor(local, local).(record); d != (record{}) {
+		t.Errorf("DeltaFor(local, local) = %v, expected the zero value", d)
+	}
+}
+
+func TestDeltaForLWW(t *testing.T) {
+	type state struct {
+		M       map[string]int `crdt:"lww=Updated"`
+		Updated int
+	}
+	local := state{map[string]int{"x": 1, "y": 2}, 5}
+	remote := state{map[string]int{"x": 1, "z": 3}, 3}
+	d := DeltaFor(local, remote).(state)
+	if !reflect.DeepEqual(d, local) {
+		t.Errorf("DeltaFor(%v, %v) = %v, expected the whole value %v", local, remote, d, local)
+	}
+	if got := Join(remote, d); !reflect.DeepEqual(got, local) {
+		t.Errorf("Join(remote, DeltaFor(local, remote)) = %v, expected %v", got, local)
+	}
+}
+
+func TestDeltaForSince(t *testing.T) {
+	type state struct {
+		V   int `crdt:"version"`
+		Old int
+		New int `crdt:"since=2"`
+	}
+	local, remote := state{V: 2, New: 5}, state{V: 2, New: 1}
+	d := DeltaFor(local, remote).(state)
+	if expected := (state{V: 2, New: 5}); d != expected {
+		t.Errorf("DeltaFor(%v, %v) = %v, expected %v", local, remote, d, expected)
+	}
+	if got := Join(remote, d); got != local {
+		t.Errorf("Join(remote, DeltaFor(local, remote)) = %v, expected %v", got, local)
+	}
+	if d := DeltaFor(local, local).(state); d != (state{}) {
+		t.Errorf("DeltaFor(local, local) = %v, expected the zero value", d)
+	}
+}
+
+func TestDeltaForMapKeys(t *testing.T) {
+	type entry struct {
+		Count int
+		Name  string
+	}
+	local := map[string]entry{
+		"absent":   {1, "a"},
+		"zero":     {},
+		"tied":     {2, "t"},
+		"greater":  {3, "g"},
+		"partial":  {5, "p"},
+		"lesser":   {1, "a"},
+		"dominant": {1, ""},
+	}
+	remote := map[string]entry{
+		"tied":     {2, "t"},
+		"greater":  {1, "a"},
+		"partial":  {4, "p"},
+		"lesser":   {2, "b"},
+		"dominant": {1, "d"},
+		"remote":   {9, "r"},
+	}
+	delta := DeltaFor(local, remote).(map[string]entry)
+	expected := map[string]entry{
+		"absent":  {1, "a"},
+		"zero":    {},
+		"greater": {3, "g"},
+		"partial": {Count: 5},
+	}
+	if !reflect.DeepEqual(delta, expected) {
+		t.Errorf("DeltaFor(%v, %v) = %v, expected %v", local, remote, delta, expected)
+	}
+	if caughtUp, joined := Join(remote, delta), Join(local, remote); !reflect.DeepEqual(caughtUp, joined) {
+		t.Errorf("Join(remote, delta) = %v, expected %v", caughtUp, joined)
+	}
+}
@@ -0,0 +1,161 @@
+package crdt
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+)
+
+// decodeJSON decodes data into a new value of type t.
+// If data doesn't decode cleanly as t, but does as a type with a registered migration to t,
+// it is decoded as that type and migrated.
+func decodeJSON(data []byte, t reflect.Type) (reflect.Value, error) {
+	if sources := migrationSources(t); len(sources) > 0 {
+		if v, err := decodeJSONStrict(data, t); err == nil {
+			return v, nil
+		}
+		for _, from := range sources {
+			if v, err := decodeJSONStrict(data, from); err == nil {
+				return migrate(v, t)
+			}
+		}
+	}
+	v := reflect.New(t)
+	if err := json.Unmarshal(renameJSON(data, t), v.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return v.Elem(), nil
+}
+
+// decodeJSONStrict decodes data into a new value of type t, failing on unknown fields.
+func decodeJSONStrict(data []byte, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t)
+	dec := json.NewDecoder(bytes.NewReader(renameJSON(data, t)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return v.Elem(), nil
+}
+
+// UnmarshalMergeJSON decodes the JSON-encoded state in data and merges it into the value pointed to by a.
+// It returns true if the value of a was modified, or an error if data can't be decoded or merged into a,
+// in which case a may have been partially merged.
+//
+// If data has fields that a's type lacks, but decodes cleanly as a type with a registered migration
+// to a's type (see RegisterMigration), it is decoded as that type and upgraded before merging.
+// Fields encoded under a name that a's type has renamed are decoded into the fields tagged `crdt:"was=Name"`.
+func UnmarshalMergeJSON(data []byte, a interface{}) (bool, error) {
+	aVal := reflect.ValueOf(a)
+	if aVal.Kind() != reflect.Ptr {
+		panic("a must be a pointer")
+	}
+	b, err := decodeJSON(data, aVal.Elem().Type())
+	if err != nil {
+		return false, err
+	}
+	return newMerger(nil).run(aVal.Elem(), b)
+}
+
+// MergeJSON decodes the JSON-encoded states a and b as values of typ's type, merges b into a,
+// and returns the JSON encoding of the result, and true if it differs from a's decoded state,
+// or an error if a or b can't be decoded, or can't be merged.
+// Both states are decoded as by UnmarshalMergeJSON. If typ is a pointer, they are decoded as its element type.
+func MergeJSON(a, b []byte, typ interface{}) ([]byte, bool, error) {
+	t := reflect.TypeOf(typ)
+	if t == nil {
+		panic("typ must not be nil")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	aVal, err := decodeJSON(a, t)
+	if err != nil {
+		return nil, false, err
+	}
+	bVal, err := decodeJSON(b, t)
+	if err != nil {
+		return nil, false, err
+	}
+	result := reflect.New(t)
+	result.Elem().Set(aVal)
+	changed, err := newMerger(nil).run(result.Elem(), bVal)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := json.Marshal(result.Interface())
+	if err != nil {
+		return nil, false, err
+	}
+	return data, changed, nil
+}
+
+// MarshalGob returns the gob encoding of v, in the form expected by UnmarshalMergeGob.
+// The encoding records v's type, so v's type must have been registered with gob.Register.
+func MarshalGob(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalMergeGob decodes the state in data, as encoded by MarshalGob,
+// and merges it into the value pointed to by a.
+// It returns true if the value of a was modified, or an error if data can't be decoded or merged into a,
+// in which case a may have been partially merged.
+//
+// If the decoded state is of a different type than a's, it is upgraded
+// using the registered migrations (see RegisterMigration) before merging.
+func UnmarshalMergeGob(data []byte, a interface{}) (bool, error) {
+	aVal := reflect.ValueOf(a)
+	if aVal.Kind() != reflect.Ptr {
+		panic("a must be a pointer")
+	}
+	var decoded interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+		return false, err
+	}
+	b, err := migrate(reflect.ValueOf(decoded), aVal.Elem().Type())
+	if err != nil {
+		return false, err
+	}
+	return newMerger(nil).run(aVal.Elem(), b)
+}
+
+// Codec encodes and decodes states for transfer between replicas.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec is a Codec using encoding/json.
+var JSONCodec Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// GobCodec is a Codec using encoding/gob.
+var GobCodec Codec = gobCodec{}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
@@ -0,0 +1,96 @@
+package crdt
+
+import (
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+type encodingState struct {
+	Name  string
+	Count map[string]int
+}
+
+func init() {
+	gob.Register(encodingState{})
+	gob.Register(map[string]interface{}{})
+}
+
+func TestUnmarshalMergeJSON(t *testing.T) {
+	value := encodingState{"a", map[string]int{"x": 1}}
+	changed, err := UnmarshalMergeJSON([]byte(`{"Name": "b", "Count": {"x": 0, "y": 2}}`), &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Errorf("UnmarshalMergeJSON changed = false, expected true")
+	}
+	if expected := (encodingState{"b", map[string]int{"x": 1, "y": 2}}); !reflect.DeepEqual(value, expected) {
+		t.Errorf("After merge was %#v, expected %#v", value, expected)
+	}
+	if _, err := UnmarshalMergeJSON([]byte(`{`), &value); err == nil {
+		t.Errorf("UnmarshalMergeJSON of invalid JSON returned no error")
+	}
+	conflicting := map[string]interface{}{"k": "s"}
+	if _, err := UnmarshalMergeJSON([]byte(`{"k": 1}`), &conflicting); err == nil {
+		t.Errorf("UnmarshalMergeJSON of a conflicting state returned no error")
+	}
+}
+
+func TestMergeJSON(t *testing.T) {
+	type inner struct {
+		Tags  []string `json:"tags" crdt:"set"`
+		Count int      `json:"count"`
+	}
+	type outer struct {
+		Name  string           `json:"name"`
+		Inner inner            `json:"inner"`
+		ByID  map[string]inner `json:"by_id"`
+	}
+	a := []byte(`{"name":"a","inner":{"tags":["y"],"count":3},"by_id":{"p":{"tags":["1"],"count":1}}}`)
+	b := []byte(`{"name":"b","inner":{"tags":["x"],"count":2},"by_id":{"q":{"tags":null,"count":5},"p":{"tags":["0"],"count":0}}}`)
+	merged, changed, err := MergeJSON(a, b, outer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"name":"b","inner":{"tags":["x","y"],"count":3},"by_id":{"p":{"tags":["0","1"],"count":1},"q":{"tags":null,"count":5}}}`
+	if !changed || string(merged) != expected {
+		t.Errorf("MergeJSON = %s, %v; expected %s, true", merged, changed, expected)
+	}
+	if merged, changed, err := MergeJSON(merged, a, &outer{}); err != nil || changed || string(merged) != expected {
+		t.Errorf("MergeJSON of merged and a = %s, %v, %v; expected %s, false, nil", merged, changed, err, expected)
+	}
+	if _, _, err := MergeJSON(a, []byte(`{`), outer{}); err == nil {
+		t.Errorf("MergeJSON of invalid JSON returned no error")
+	}
+	if _, _, err := MergeJSON([]byte(`{"k":"s"}`), []byte(`{"k":1}`), map[string]interface{}{}); err == nil {
+		t.Errorf("MergeJSON of conflicting states returned no error")
+	}
+}
+
+func TestUnmarshalMergeGob(t *testing.T) {
+	data, err := MarshalGob(encodingState{"b", map[string]int{"y": 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := encodingState{"a", map[string]int{"x": 1}}
+	changed, err := UnmarshalMergeGob(data, &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Errorf("UnmarshalMergeGob changed = false, expected true")
+	}
+	if expected := (encodingState{"b", map[string]int{"x": 1, "y": 2}}); !reflect.DeepEqual(value, expected) {
+		t.Errorf("After merge was %#v, expected %#v", value, expected)
+	}
+
+	data, err = MarshalGob(map[string]interface{}{"k": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conflicting := map[string]interface{}{"k": "s"}
+	if _, err := UnmarshalMergeGob(data, &conflicting); err == nil {
+		t.Errorf("UnmarshalMergeGob of a conflicting state returned no error")
+	}
+}
@@ -0,0 +1,65 @@
+package crdt
+
+import (
+	"reflect"
+	"sync"
+)
+
+// enums holds the registered enums, keyed by name. Each maps a value to its rank, starting at 1.
+var enums sync.Map // map[string]map[string]int
+
+// RegisterEnum registers an ordered set of string values under name, from least to greatest.
+// A string field tagged `crdt:"enum=name"` is merged by this order instead of lexicographically,
+// so that, say, "closed" can beat "active" in a status field. Values not in the set are ranked
+// below all of those in it, and ordered lexicographically among themselves; WithStrictEnums
+// makes them an error instead. The empty string is the bottom value, as usual.
+// Registering a name again replaces its values.
+func RegisterEnum(name string, values ...string) {
+	ranks := make(map[string]int, len(values))
+	for i, value := range values {
+		ranks[value] = i + 1
+	}
+	enums.Store(name, ranks)
+}
+
+// WithStrictEnums makes merging a value that isn't in its field's registered enum an error.
+func WithStrictEnums() Option {
+	return func(c *config) {
+		c.strictEnums = true
+	}
+}
+
+// enumRank returns the rank of v in the enum named name, or 0 if v isn't in it.
+func (m *merger) enumRank(name string, v reflect.Value) int {
+	rank := enumRanks(name)[v.String()]
+	if rank == 0 && m.strictEnums {
+		panic(mergeErrorf("%q is not a value of enum %s", v.String(), name))
+	}
+	return rank
+}
+
+// enumRanks returns the ranks of the values of the enum named name.
+func enumRanks(name string) map[string]int {
+	ranks, ok := enums.Load(name)
+	if !ok {
+		panic(mergeErrorf("enum %s is not registered", name))
+	}
+	return ranks.(map[string]int)
+}
+
+// mergeEnum merges the string b into the string a by their ranks in the enum named name.
+// It returns true if the value of a was modified.
+func (m *merger) mergeEnum(name string, a, b reflect.Value) bool {
+	if isZero(b) {
+		return false
+	}
+	aRank, bRank := 0, m.enumRank(name, b)
+	if !isZero(a) {
+		aRank = m.enumRank(name, a)
+		if aRank > bRank || aRank == bRank && !less(a, b) {
+			return false
+		}
+	}
+	a.Set(b)
+	return true
+}
@@ -0,0 +1,56 @@
+package crdt
+
+import (
+	"errors"
+	"testing"
+)
+
+func init() {
+	RegisterEnum("status", "pending", "active", "closed")
+}
+
+type ticket struct {
+	Status  string            `crdt:"enum=status"`
+	History map[string]string `crdt:"enum=status"`
+}
+
+func TestMergeEnum(t *testing.T) {
+	for _, test := range []struct {
+		a, b, expected string
+	}{
+		{"active", "closed", "closed"},
+		{"pending", "active", "active"},
+		{"closed", "pending", "closed"},
+		{"", "pending", "pending"},
+		{"bogus", "pending", "pending"},
+		{"bogus", "", "bogus"},
+		{"bogus", "alsobogus", "bogus"},
+	} {
+		for _, pair := range [][2]string{{test.a, test.b}, {test.b, test.a}} {
+			result := Join(ticket{Status: pair[0]}, ticket{Status: pair[1]}).(ticket)
+			if result.Status != test.expected {
+				t.Errorf("Join(%q, %q) = %q, expected %q", pair[0], pair[1], result.Status, test.expected)
+			}
+		}
+	}
+}
+
+func TestMergeEnumMap(t *testing.T) {
+	a := ticket{History: map[string]string{"x": "closed", "y": "pending"}}
+	b := ticket{History: map[string]string{"x": "active", "y": "active"}}
+	result := Join(a, b).(ticket)
+	if result.History["x"] != "closed" || result.History["y"] != "active" {
+		t.Errorf("Join(%#v, %#v) = %#v", a, b, result)
+	}
+}
+
+func TestWithStrictEnums(t *testing.T) {
+	a := ticket{Status: "active"}
+	var mergeErr *MergeError
+	if _, err := MergeWith(&a, ticket{Status: "bogus"}, WithStrictEnums()); !errors.As(err, &mergeErr) || mergeErr.Path != "Status" {
+		t.Errorf("MergeWith of an invalid value returned %v, expected a MergeError at Status", err)
+	}
+	if _, err := MergeWith(&a, ticket{Status: "closed"}, WithStrictEnums()); err != nil || a.Status != "closed" {
+		t.Errorf("MergeWith of a valid value = %q, %v", a.Status, err)
+	}
+}
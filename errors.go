@@ -0,0 +1,25 @@
+package crdt
+
+import "reflect"
+
+// Values of type error are merged as follows: nil is the bottom value, so any non-nil error
+// is greater than it; of two non-nil errors, the one with the lexicographically greater
+// Error() message wins, and if their messages are equal, a is kept.
+// This allows CRDTs to carry a "last error" field.
+func init() {
+	Register(reflect.TypeOf((*error)(nil)).Elem(), mergeError)
+}
+
+// mergeError merges the error b into the error pointed to by a.
+func mergeError(a, b interface{}) bool {
+	value := a.(*error)
+	other, _ := b.(error)
+	if other == nil {
+		return false
+	}
+	if *value == nil || other.Error() > (*value).Error() {
+		*value = other
+		return true
+	}
+	return false
+}
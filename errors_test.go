@@ -0,0 +1,32 @@
+package crdt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMergeError(t *testing.T) {
+	type A struct {
+		LastError error
+	}
+	errA := errors.New("a failed")
+	errB := errors.New("b failed")
+	testMerge := func(a, b A, expectedChanged bool, expected A) {
+		value := a
+		if changed := Merge(&value, b); changed != expectedChanged {
+			t.Errorf("Merge(%#v, %#v) = %v, expected %v", a, b, changed, expectedChanged)
+		}
+		if value != expected {
+			t.Errorf("After merge was %#v, expected %#v", value, expected)
+		}
+	}
+	// nil vs error
+	testMerge(A{}, A{errA}, true, A{errA})
+	// error vs nil
+	testMerge(A{errA}, A{}, false, A{errA})
+	// error vs error
+	testMerge(A{errA}, A{errB}, true, A{errB})
+	testMerge(A{errB}, A{errA}, false, A{errB})
+	testMerge(A{errA}, A{errors.New("a failed")}, false, A{errA})
+	testMerge(A{}, A{}, false, A{})
+}
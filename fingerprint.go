@@ -0,0 +1,98 @@
+package crdt
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+)
+
+// Fingerprint returns a 64-bit hash of the leaves of Normalize(a), as visited by Walk,
+// so that replicas can cheaply check whether their states differ.
+// Values that are Equal have equal fingerprints. Leaves are hashed by their contents:
+// pointers are hashed by what they point to rather than by their addresses.
+func Fingerprint(a interface{}, opts ...Option) uint64 {
+	h := fnv.New64a()
+	Walk(Normalize(a), func(path string, value interface{}) {
+		fmt.Fprintf(h, "%s=", path)
+		writeLeaf(h, reflect.ValueOf(value), make(map[uintptr]bool))
+		io.WriteString(h, ";")
+	}, opts...)
+	return h.Sum64()
+}
+
+// writeLeaf writes a representation of v to w that depends only on its contents, so that values
+// that are reflect.DeepEqual have the same representation: pointers are followed rather than formatted,
+// and map entries are combined independently of their order.
+// seen holds the pointers and maps being written, to stop at cycles.
+func writeLeaf(w io.Writer, v reflect.Value, seen map[uintptr]bool) {
+	if !v.IsValid() {
+		io.WriteString(w, "nil")
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if v.IsNil() {
+			io.WriteString(w, "nil")
+			return
+		}
+		if seen[v.Pointer()] {
+			io.WriteString(w, "cycle")
+			return
+		}
+		seen[v.Pointer()] = true
+		defer delete(seen, v.Pointer())
+		if v.Kind() == reflect.Ptr {
+			io.WriteString(w, "&")
+			writeLeaf(w, v.Elem(), seen)
+			return
+		}
+		var sum uint64
+		iter := v.MapRange()
+		for iter.Next() {
+			h := fnv.New64a()
+			writeLeaf(h, iter.Key(), seen)
+			io.WriteString(h, ":")
+			writeLeaf(h, iter.Value(), seen)
+			sum += h.Sum64()
+		}
+		fmt.Fprintf(w, "map[%d:%x]", v.Len(), sum)
+	case reflect.Interface:
+		if v.IsNil() {
+			io.WriteString(w, "nil")
+			return
+		}
+		fmt.Fprintf(w, "%s(", v.Elem().Type())
+		writeLeaf(w, v.Elem(), seen)
+		io.WriteString(w, ")")
+	case reflect.Slice, reflect.Array:
+		fmt.Fprintf(w, "[%d:", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			writeLeaf(w, v.Index(i), seen)
+			io.WriteString(w, ",")
+		}
+		io.WriteString(w, "]")
+	case reflect.Struct:
+		io.WriteString(w, "{")
+		for i := 0; i < v.NumField(); i++ {
+			writeLeaf(w, v.Field(i), seen)
+			io.WriteString(w, ";")
+		}
+		io.WriteString(w, "}")
+	case reflect.Bool:
+		fmt.Fprint(w, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fmt.Fprint(w, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		fmt.Fprint(w, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		fmt.Fprint(w, v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		fmt.Fprint(w, v.Complex())
+	case reflect.String:
+		fmt.Fprintf(w, "%q", v.String())
+	default:
+		// Channels, functions, and unsafe pointers have no contents to compare.
+		fmt.Fprint(w, v.Type())
+	}
+}
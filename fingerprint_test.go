@@ -0,0 +1,46 @@
+package crdt
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	type A struct {
+		I int
+		M map[string]int
+	}
+	a := A{1, map[string]int{"x": 1, "y": 2}}
+	if Fingerprint(a) != Fingerprint(A{1, map[string]int{"y": 2, "x": 1}}) {
+		t.Errorf("equal values have different fingerprints")
+	}
+	if Fingerprint(A{}) != Fingerprint(A{0, map[string]int{}}) {
+		t.Errorf("nil and empty maps have different fingerprints")
+	}
+	if Fingerprint(a) == Fingerprint(A{1, map[string]int{"x": 1, "y": 3}}) {
+		t.Errorf("different values have the same fingerprint")
+	}
+	if Fingerprint(a) == Fingerprint(A{1, map[string]int{"x": 1}}) {
+		t.Errorf("values with different keys have the same fingerprint")
+	}
+}
+
+func TestFingerprintPointers(t *testing.T) {
+	type A struct {
+		P *int
+		S []*string
+		M map[string]*int
+	}
+	newA := func() A {
+		i, s, j := 1, "s", 2
+		return A{&i, []*string{&s}, map[string]*int{"x": &j}}
+	}
+	a, b := newA(), newA()
+	if !Equal(a, b) {
+		t.Fatalf("Equal(%#v, %#v) = false, expected true", a, b)
+	}
+	if Fingerprint(a) != Fingerprint(a) || Fingerprint(a) != Fingerprint(b) {
+		t.Errorf("Equal values containing pointers have different fingerprints")
+	}
+	*b.S[0] = "t"
+	if Fingerprint(a) == Fingerprint(b) {
+		t.Errorf("values that differ behind a pointer have the same fingerprint")
+	}
+}
@@ -0,0 +1,88 @@
+package crdt
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// A struct-typed field tagged `crdt:"fixedpoint,units=Units,scale=Scale"` holds a fixed-point number
+// whose value is Units × 10^-Scale, where Units and Scale are integer fields of the struct.
+// Rather than being merged fieldwise, which could pair one side's Units with the other's Scale,
+// the struct is compared as a single number and taken whole from the side with the greater value,
+// along with any other fields it has. Equal values with different scales, like 150 at scale 2 and 15
+// at scale 1, are ordered by scale, so that the more precise representation wins and merges converge.
+// Equal values with the same scale have their other fields, if any, merged fieldwise.
+// units and scale default to Units and Scale. The zero struct is the bottom value.
+// As with other tags, the tag of a map field applies to its values.
+
+// fixedPointFields returns the indexes of the units and scale fields of the struct type t,
+// tagged with the fixedpoint options tag.
+func fixedPointFields(t reflect.Type, tag tagOptions) (units, scale int, err error) {
+	indexes := [2]int{}
+	for i, opt := range []string{"units", "scale"} {
+		name := tag[opt]
+		if name == "" {
+			name = [...]string{"Units", "Scale"}[i]
+		}
+		field, ok := t.FieldByName(name)
+		if !ok || len(field.Index) != 1 || field.PkgPath != "" {
+			return 0, 0, fmt.Errorf("fixedpoint %s field %s does not exist", opt, name)
+		}
+		if !reflect.Zero(field.Type).CanInt() {
+			return 0, 0, fmt.Errorf("fixedpoint %s field %s is not a signed integer", opt, name)
+		}
+		indexes[i] = field.Index[0]
+	}
+	return indexes[0], indexes[1], nil
+}
+
+// fixedPointValue returns the value of the fixed-point number with the given units and scale.
+func fixedPointValue(units, scale int64) *big.Rat {
+	exp := scale
+	if exp < 0 {
+		exp = -exp
+	}
+	pow := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil))
+	value := new(big.Rat).SetInt64(units)
+	if scale >= 0 {
+		return value.Quo(value, pow)
+	}
+	return value.Mul(value, pow)
+}
+
+// mergeFixedPoint merges the fixed-point number b into the fixed-point number a, which are structs.
+// It returns true if the value of a was modified.
+func (m *merger) mergeFixedPoint(a, b reflect.Value) bool {
+	units, scale, err := fixedPointFields(a.Type(), m.tag)
+	if err != nil {
+		panic(mergeErrorf("%v", err))
+	}
+	if isZero(b) {
+		m.decide(false, a)
+		return false
+	}
+	order := -1
+	if !isZero(a) {
+		aValue := fixedPointValue(a.Field(units).Int(), a.Field(scale).Int())
+		bValue := fixedPointValue(b.Field(units).Int(), b.Field(scale).Int())
+		order = aValue.Cmp(bValue)
+		if order == 0 {
+			order = compare(a.Field(scale), b.Field(scale))
+		}
+	}
+	switch {
+	case order < 0:
+		a.Set(deepCopy(b))
+		m.decide(true, a)
+		return true
+	case order > 0:
+		m.decide(false, a)
+		return false
+	}
+	// The units and scale are equal, so merging them fieldwise leaves them as they are.
+	tag := m.tag
+	changed := m.mergeFields(a, b)
+	m.tag = tag
+	return changed
+}
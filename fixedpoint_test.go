@@ -0,0 +1,71 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type decimal struct {
+	Units int64
+	Scale int32
+}
+
+func TestMergeFixedPoint(t *testing.T) {
+	type reading struct {
+		Value decimal            `crdt:"fixedpoint"`
+		Peaks map[string]decimal `crdt:"fixedpoint,units=Units,scale=Scale"`
+	}
+	for _, test := range []struct {
+		a, b, expected decimal
+	}{
+		{decimal{150, 2}, decimal{2, 0}, decimal{2, 0}},    // 1.50 < 2
+		{decimal{2, 0}, decimal{150, 2}, decimal{2, 0}},    // 2 > 1.50
+		{decimal{999, 3}, decimal{1, 0}, decimal{1, 0}},    // 0.999 < 1, though 999 > 1 and 3 > 0
+		{decimal{150, 2}, decimal{15, 1}, decimal{150, 2}}, // equal, the greater scale wins
+		{decimal{15, 1}, decimal{150, 2}, decimal{150, 2}}, // in either order
+		{decimal{-5, 0}, decimal{-45, 1}, decimal{-45, 1}}, // -5 < -4.5
+		{decimal{3, -2}, decimal{299, 0}, decimal{3, -2}},  // 300 > 299
+		{decimal{}, decimal{-1, 0}, decimal{-1, 0}},        // zero is the bottom value
+		{decimal{7, 1}, decimal{}, decimal{7, 1}},          // from either side
+	} {
+		joined := Join(reading{Value: test.a}, reading{Value: test.b}).(reading)
+		if joined.Value != test.expected {
+			t.Errorf("Join(%v, %v) = %v, expected %v", test.a, test.b, joined.Value, test.expected)
+		}
+	}
+	a := reading{Peaks: map[string]decimal{"x": {150, 2}, "y": {1, 0}}}
+	b := reading{Peaks: map[string]decimal{"x": {2, 0}, "y": {999, 3}}}
+	expected := map[string]decimal{"x": {2, 0}, "y": {1, 0}}
+	if joined := Join(a, b).(reading); !reflect.DeepEqual(joined.Peaks, expected) {
+		t.Errorf("Join of maps = %v, expected %v", joined.Peaks, expected)
+	}
+
+	type price struct {
+		Units    int64
+		Scale    int32
+		Currency string
+	}
+	type priced struct {
+		Price price `crdt:"fixedpoint"`
+	}
+	x, y := priced{price{15, 1, "EUR"}}, priced{price{15, 1, "USD"}}
+	if xy, yx := Join(x, y), Join(y, x); !reflect.DeepEqual(xy, yx) {
+		t.Errorf("Join(%v, %v) = %v, but Join(%v, %v) = %v", x, y, xy, y, x, yx)
+	}
+
+	local, remote := reading{Value: decimal{5, 1}}, reading{Value: decimal{5, 2}}
+	d := DeltaFor(local, remote).(reading)
+	if d.Value != local.Value {
+		t.Errorf("DeltaFor(%v, %v) = %v, expected the whole value %v", local, remote, d, local)
+	}
+	if got := Join(remote, d).(reading); got.Value != local.Value {
+		t.Errorf("Join(remote, DeltaFor(local, remote)) = %v, expected %v", got, local)
+	}
+
+	type bad struct {
+		Value decimal `crdt:"fixedpoint,scale=Exponent"`
+	}
+	if err := Validate(reflect.TypeOf(bad{})); err == nil {
+		t.Errorf("Validate accepted a fixedpoint struct with a missing scale field")
+	}
+}
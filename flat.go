@@ -0,0 +1,55 @@
+package crdt
+
+import (
+	"reflect"
+	"sync"
+)
+
+// flatTypes caches the result of isFlat for each struct type it has classified.
+var flatTypes sync.Map // map[reflect.Type]bool
+
+var (
+	mergerType     = reflect.TypeOf((*Merger)(nil)).Elem()
+	comparableType = reflect.TypeOf((*Comparable)(nil)).Elem()
+
+	partialMergerType = reflect.TypeOf((*PartialMerger)(nil)).Elem()
+)
+
+// isFlat returns true if t is a struct type made up entirely of exported fields with a total ordering,
+// none of which has custom merge behavior or a `crdt` tag. Such structs can be merged by mergeFlat.
+func isFlat(t reflect.Type) bool {
+	if flat, ok := flatTypes.Load(t); ok {
+		return flat.(bool)
+	}
+	flat := t.NumField() > 0
+	for i := 0; i < t.NumField() && flat; i++ {
+		field := t.Field(i)
+		flat = field.PkgPath == "" && field.Tag.Get("crdt") == "" && isScalar(field.Type)
+	}
+	flatTypes.Store(t, flat)
+	return flat
+}
+
+// isScalar returns true if t has a total ordering and no custom merge behavior,
+// so that its values can be merged by comparing them directly.
+func isScalar(t reflect.Type) bool {
+	return isOrdered(t.Kind()) &&
+		!reflect.PointerTo(t).Implements(mergerType) &&
+		!reflect.PointerTo(t).Implements(comparableType) &&
+		!reflect.PointerTo(t).Implements(zeroerType) &&
+		registered(t) == nil
+}
+
+// mergeFlat merges the struct b into the struct a, whose type must satisfy isFlat.
+// It returns true if the value of a was modified.
+func mergeFlat(a, b reflect.Value) bool {
+	var changed bool
+	for i := 0; i < a.NumField(); i++ {
+		aField, bField := a.Field(i), b.Field(i)
+		if !isScalarZero(bField) && (isScalarZero(aField) || less(aField, bField)) {
+			aField.Set(bField)
+			changed = true
+		}
+	}
+	return changed
+}
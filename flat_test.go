@@ -0,0 +1,94 @@
+package crdt
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type flatStruct struct {
+	A bool
+	B int
+	C int8
+	D int32
+	E int64
+	F uint
+	G uint16
+	H uint64
+	I float32
+	J string
+}
+
+func randomFlatStruct(r *rand.Rand) flatStruct {
+	return flatStruct{
+		A: r.Intn(2) == 0,
+		B: r.Intn(5) - 2,
+		C: int8(r.Intn(5) - 2),
+		D: int32(r.Intn(5) - 2),
+		E: int64(r.Intn(5) - 2),
+		F: uint(r.Intn(5)),
+		G: uint16(r.Intn(5)),
+		H: uint64(r.Intn(5)),
+		I: float32(r.Intn(5)) / 2,
+		J: string(rune('a' + r.Intn(5))),
+	}
+}
+
+func TestIsFlat(t *testing.T) {
+	type withMap struct {
+		I int
+		M map[int]int
+	}
+	type withMerger struct {
+		I int
+		D decreasingInt
+	}
+	type unexported struct {
+		I int
+		j int
+	}
+	testIsFlat := func(value interface{}, expected bool) {
+		if flat := isFlat(reflect.TypeOf(value)); flat != expected {
+			t.Errorf("isFlat(%T) = %v, expected %v", value, flat, expected)
+		}
+	}
+	testIsFlat(flatStruct{}, true)
+	testIsFlat(struct{}{}, false)
+	testIsFlat(withMap{}, false)
+	testIsFlat(withMerger{}, false)
+	testIsFlat(unexported{}, false)
+}
+
+func TestMergeFlat(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a, b := randomFlatStruct(r), randomFlatStruct(r)
+		flat, generic := a, a
+		flatChanged := mergeFlat(reflect.ValueOf(&flat).Elem(), reflect.ValueOf(b))
+		genericChanged := mergeStruct(reflect.ValueOf(&generic).Elem(), reflect.ValueOf(b))
+		if flat != generic || flatChanged != genericChanged {
+			t.Fatalf("merging %#v into %#v: flat path gave (%#v, %v), generic path gave (%#v, %v)",
+				b, a, flat, flatChanged, generic, genericChanged)
+		}
+	}
+}
+
+func BenchmarkMergeFlat(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	x, y := randomFlatStruct(r), randomFlatStruct(r)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		value := x
+		mergeFlat(reflect.ValueOf(&value).Elem(), reflect.ValueOf(y))
+	}
+}
+
+func BenchmarkMergeFlatGeneric(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	x, y := randomFlatStruct(r), randomFlatStruct(r)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		value := x
+		mergeStruct(reflect.ValueOf(&value).Elem(), reflect.ValueOf(y))
+	}
+}
@@ -0,0 +1,32 @@
+package crdt
+
+import "reflect"
+
+// mergeInterface merges the interface value b into the interface value a by merging their dynamic values,
+// which must be of the same type. A nil interface value is the bottom value; a nil a is set to a deep copy of b.
+// It returns true if the value of a was modified.
+func (m *merger) mergeInterface(a, b reflect.Value) bool {
+	if b.IsNil() {
+		m.decide(false, a)
+		return false
+	}
+	if a.IsNil() {
+		m.chargeCopy(b)
+		a.Set(deepCopy(b))
+		m.decide(true, a)
+		return true
+	}
+	aElem, bElem := a.Elem(), b.Elem()
+	if aElem.Type() != bElem.Type() {
+		panic(mergeErrorf("can't merge %s with %s", aElem.Type(), bElem.Type()))
+	}
+	// a's dynamic value isn't addressable, so it is merged in a copy, which shares its maps and pointers
+	// as a struct field's value would, and stored back if it changed.
+	value := reflect.New(aElem.Type()).Elem()
+	value.Set(aElem)
+	if !m.merge(value, bElem) {
+		return false
+	}
+	a.Set(value)
+	return true
+}
@@ -0,0 +1,62 @@
+package crdt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergeInterface(t *testing.T) {
+	type holder struct {
+		Value interface{}
+	}
+	for _, test := range []struct {
+		a, b, expected interface{}
+	}{
+		{nil, 3, 3},
+		{3, nil, 3},
+		{3, 5, 5},
+		{"b", "a", "b"},
+		{map[string]int{"x": 1}, map[string]int{"x": 2, "y": 1}, map[string]int{"x": 2, "y": 1}},
+		{flatStruct{B: 1}, flatStruct{C: 2}, flatStruct{B: 1, C: 2}},
+	} {
+		joined := Join(holder{test.a}, holder{test.b}).(holder)
+		if !reflect.DeepEqual(joined.Value, test.expected) {
+			t.Errorf("Join(%v, %v) = %v, expected %v", test.a, test.b, joined.Value, test.expected)
+		}
+	}
+	if _, err := JoinWith(holder{1}, holder{"1"}); err == nil {
+		t.Errorf("JoinWith of different dynamic types didn't fail")
+	}
+}
+
+func TestMergeInterfaceMap(t *testing.T) {
+	var ca, cb GCounter
+	ca.Increment("a", 2)
+	cb.Increment("b", 3)
+	a := map[string]interface{}{"count": ca, "max": 3, "nested": map[string]int{"x": 1}, "only a": "s"}
+	b := map[string]interface{}{"count": cb, "max": 5, "nested": map[string]int{"y": 2}, "only b": 1.5}
+	joined := Join(a, b).(map[string]interface{})
+	if count := joined["count"].(GCounter); count.Value() != 5 {
+		t.Errorf("joined counter = %d, expected 5", count.Value())
+	}
+	expected := map[string]interface{}{
+		"count":  joined["count"],
+		"max":    5,
+		"nested": map[string]int{"x": 1, "y": 2},
+		"only a": "s",
+		"only b": 1.5,
+	}
+	if !reflect.DeepEqual(joined, expected) {
+		t.Errorf("Join(%v, %v) = %v, expected %v", a, b, joined, expected)
+	}
+	if Merge(&joined, a) || Merge(&joined, b) {
+		t.Errorf("merging a or b into their join reported a change")
+	}
+
+	_, err := JoinWith(map[string]interface{}{"k": ca, "ok": 1}, map[string]interface{}{"k": 1, "ok": 2})
+	var mergeErr *MergeError
+	if !errors.As(err, &mergeErr) || mergeErr.Path != "[k]" {
+		t.Errorf("JoinWith of conflicting types under one key = %v, expected a MergeError at [k]", err)
+	}
+}
@@ -0,0 +1,50 @@
+package crdt
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+)
+
+// json.Number values are merged to the numerically greater of (a, b), rather than the lexicographically
+// greater string, with the empty string as the bottom value. The winner keeps its representation,
+// so "10" beats "9", and merging "1e3" with "999" gives "1e3". Numerically equal values with different
+// representations, like "1.0" and "1", are ordered by their strings, so that merges converge.
+func init() {
+	registerValue(reflect.TypeOf(json.Number("")), func(a, b reflect.Value) bool {
+		if b.String() == "" || a.String() == b.String() {
+			return false
+		}
+		if a.String() == "" || compareNumbers(a.String(), b.String()) < 0 {
+			a.SetString(b.String())
+			return true
+		}
+		return false
+	})
+}
+
+// compareNumbers compares two numbers in JSON syntax, returning -1, 0, or +1 as a is less than,
+// equal to, or greater than b, and ordering numerically equal values by their strings.
+// It panics with a MergeError if either isn't a number.
+func compareNumbers(a, b string) int {
+	x, y := parseNumber(a), parseNumber(b)
+	if c := x.Cmp(y); c != 0 {
+		return c
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// parseNumber parses s, a number in JSON syntax, exactly.
+func parseNumber(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic(mergeErrorf("%q is not a number", s))
+	}
+	return r
+}
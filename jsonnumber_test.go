@@ -0,0 +1,41 @@
+package crdt
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMergeJSONNumber(t *testing.T) {
+	for _, test := range []struct {
+		a, b, expected json.Number
+	}{
+		{"9", "10", "10"},
+		{"10", "9", "10"},
+		{"", "9", "9"},
+		{"9", "", "9"},
+		{"1e3", "999", "1e3"},
+		{"-2.5", "-10", "-2.5"},
+		{"1.0", "1", "1.0"},
+		{"1", "1.0", "1.0"},
+		{"123456789012345678901234567890", "123456789012345678901234567891", "123456789012345678901234567891"},
+	} {
+		if got := Join(test.a, test.b).(json.Number); got != test.expected {
+			t.Errorf("Join(%q, %q) = %q, expected %q", test.a, test.b, got, test.expected)
+		}
+	}
+
+	type reading struct {
+		Values map[string]json.Number
+	}
+	a := reading{map[string]json.Number{"x": "9"}}
+	if !Merge(&a, reading{map[string]json.Number{"x": "10.00"}}) || a.Values["x"] != "10.00" {
+		t.Errorf("Merge gave %v, expected x: 10.00", a.Values)
+	}
+}
+
+func TestMergeJSONNumberInvalid(t *testing.T) {
+	a := json.Number("9")
+	if _, err := MergeWith(&a, json.Number("nine")); err == nil {
+		t.Errorf("merging a json.Number that isn't a number didn't fail")
+	}
+}
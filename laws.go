@@ -0,0 +1,78 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithVerifyLaws checks every merge that is delegated to a Merger against the laws a join must obey,
+// on the inputs it is given: merging b again must be a no-op (idempotence), and merging the old value of a
+// into b must give the same result (commutativity). Each violation is reported by calling report
+// with the path of the value (in the format used by WithProvenance) and an error describing it;
+// if report is nil, the merge panics with the error instead.
+//
+// The checks deep-copy and re-merge every such value, so they are meant for diagnosing
+// convergence bugs rather than for production use.
+func WithVerifyLaws(report func(path string, err error)) Option {
+	return func(c *config) {
+		c.verifyLaws = true
+		c.lawViolation = report
+	}
+}
+
+// checkLaws checks that merger, which produced a from before and b, is idempotent and commutative on them.
+func (m *merger) checkLaws(a, b, before reflect.Value) {
+	again := deepCopy(a)
+	if again.Addr().Interface().(Merger).Merge(b.Interface()) || !reflect.DeepEqual(again.Interface(), a.Interface()) {
+		m.violation(fmt.Errorf("merge of %s is not idempotent: merging %#v into %#v again gave %#v",
+			a.Type(), b.Interface(), a.Interface(), again.Interface()))
+	}
+	swapped := deepCopy(b)
+	swapped.Addr().Interface().(Merger).Merge(before.Interface())
+	if !reflect.DeepEqual(swapped.Interface(), a.Interface()) {
+		m.violation(fmt.Errorf("merge of %s is not commutative: merging %#v into %#v gave %#v, but the reverse gave %#v",
+			a.Type(), b.Interface(), before.Interface(), a.Interface(), swapped.Interface()))
+	}
+}
+
+// violation reports a violation of the join laws.
+func (m *merger) violation(err error) {
+	if m.lawViolation == nil {
+		panic(&MergeError{Err: err})
+	}
+	m.lawViolation(m.path.String(), err)
+}
+
+// WithSelfCheck checks every merge it configures against the package's own implementation of the join:
+// once the merge is done, b is merged again into a copy of the result, which must report no change
+// and leave the copy equal to the result. A second merge that changes anything indicates a bug in this
+// package, rather than in a user's Merger, and makes the merge fail with a *MergeError describing it.
+// Merges that stopped early, at a deadline or with collected errors, are not checked.
+// With WithMaxMapSize, only the copy is checked, since the second merge may evict entries it re-adds.
+//
+// Like WithVerifyLaws, the check deep-copies and re-merges the value, so it is meant for tests.
+func WithSelfCheck() Option {
+	return func(c *config) {
+		c.selfCheck = true
+	}
+}
+
+// checkSelf checks that merging b into a, the result of a merge of b, is a no-op.
+func (m *merger) checkSelf(a, b reflect.Value) {
+	if m.expired || (m.errs != nil && len(*m.errs) > 0) {
+		return
+	}
+	again := deepCopy(a)
+	q := &merger{config: m.config, copying: true}
+	q.provenance, q.logger, q.verifyLaws, q.selfCheck = nil, nil, false, false
+	changed := q.merge(again, b)
+	if m.maxMapSize > 0 {
+		// Merging b again may re-add entries that the first merge evicted, only to evict them again,
+		// so with WithMaxMapSize only the result is checked.
+		changed = false
+	}
+	if changed || !reflect.DeepEqual(again.Interface(), a.Interface()) {
+		panic(mergeErrorf("merging %#v into %#v again gave %#v; this is a bug in package crdt",
+			b.Interface(), a.Interface(), again.Interface()))
+	}
+}
@@ -0,0 +1,105 @@
+package crdt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// summingInt is a broken Merger that adds rather than joins, so it isn't idempotent.
+type summingInt int
+
+func (i *summingInt) Merge(other interface{}) bool {
+	*i += other.(summingInt)
+	return other.(summingInt) != 0
+}
+
+// overwritingInt is a broken Merger that always takes the other value, so it isn't commutative.
+type overwritingInt int
+
+func (i *overwritingInt) Merge(other interface{}) bool {
+	changed := *i != other.(overwritingInt)
+	*i = other.(overwritingInt)
+	return changed
+}
+
+func TestWithVerifyLaws(t *testing.T) {
+	type A struct {
+		Good MaxRegister[int]
+		Sum  summingInt
+		Last overwritingInt
+	}
+	violations := map[string]string{}
+	value := A{MaxRegister[int]{1, true}, 1, 1}
+	MergeWith(&value, A{MaxRegister[int]{2, true}, 2, 2}, WithVerifyLaws(func(path string, err error) {
+		violations[path] += err.Error()
+	}))
+	if _, ok := violations["Good"]; ok {
+		t.Errorf("lawful Merger reported a violation: %s", violations["Good"])
+	}
+	if !strings.Contains(violations["Sum"], "not idempotent") {
+		t.Errorf("summing Merger violations = %q, expected idempotence violation", violations["Sum"])
+	}
+	if !strings.Contains(violations["Last"], "not commutative") {
+		t.Errorf("overwriting Merger violations = %q, expected commutativity violation", violations["Last"])
+	}
+}
+
+func TestWithVerifyLawsError(t *testing.T) {
+	value := summingInt(1)
+	_, err := MergeWith(&value, summingInt(1), WithVerifyLaws(nil))
+	if err == nil || !strings.Contains(err.Error(), "not idempotent") {
+		t.Errorf("MergeWith with nil report returned %v, expected idempotence violation", err)
+	}
+}
+
+func TestWithSelfCheck(t *testing.T) {
+	type item struct {
+		ID    string
+		Count int
+	}
+	type A struct {
+		Name    string
+		Scores  map[string]int
+		Nested  map[string]map[string]int
+		Tags    []string `crdt:"set"`
+		Items   []item   `crdt:"set=ID"`
+		List    []int
+		Ptr     *int
+		Counter GCounter
+		Title   string `crdt:"lww=Updated"`
+		Updated int64
+		Any     interface{}
+		Views   uint64 `crdt:"sum=Counts"`
+		Counts  map[string]uint64
+	}
+	one, two := 1, 2
+	values := []A{
+		{},
+		{Name: "a", Scores: map[string]int{"x": 1}, Tags: []string{"b", "a"}, Ptr: &one, Title: "old", Updated: 1},
+		{Name: "b", Scores: map[string]int{"x": 0, "y": 2}, Nested: map[string]map[string]int{"n": {"k": 1}},
+			Items: []item{{"i", 1}}, List: []int{3}, Ptr: &two, Title: "new", Updated: 2, Any: 5},
+		{Nested: map[string]map[string]int{"n": {"k": 0, "j": 2}}, Items: []item{{"i", 2}, {"h", 0}},
+			List: []int{1, 4}, Counter: GCounter{map[string]uint64{"r": 1}}, Any: 3,
+			Views: 2, Counts: map[string]uint64{"r": 2}},
+	}
+	for _, a := range values {
+		for _, b := range values {
+			value := deepCopy(reflect.ValueOf(a)).Interface().(A)
+			if _, err := MergeWith(&value, b, WithSelfCheck()); err != nil {
+				t.Errorf("MergeWith(%#v, %#v, WithSelfCheck()) = %v", a, b, err)
+			}
+			if _, err := JoinWith(a, b, WithSelfCheck()); err != nil {
+				t.Errorf("JoinWith(%#v, %#v, WithSelfCheck()) = %v", a, b, err)
+			}
+		}
+	}
+}
+
+func TestWithSelfCheckError(t *testing.T) {
+	value := summingInt(1)
+	_, err := MergeWith(&value, summingInt(1), WithSelfCheck())
+	if err == nil || !strings.Contains(err.Error(), "again") {
+		t.Errorf("MergeWith of a non-idempotent merge with WithSelfCheck returned %v, expected an error", err)
+	}
+}
@@ -0,0 +1,82 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// A slice field tagged `crdt:"log"` is an append-only log, such as a grow-only stack or queue,
+// whose order matters but which isn't a set. Each element is a struct recording its origin
+// in a field tagged `crdt:"time"`, holding a logical time such as a Lamport timestamp,
+// and a field tagged `crdt:"replica"`, holding the ID of the replica that appended it.
+// Merging two logs concatenates them causally: the result holds the elements of both,
+// ordered by (time, replica), so that concurrent appends on different replicas interleave
+// the same way wherever they are merged. Elements with the same origin are merged together.
+//
+// For the order to be causal, a replica must append each element with a time greater than
+// that of every element already in its log.
+
+// logOrigin returns the indexes of the fields of the struct type t tagged `crdt:"time"` and `crdt:"replica"`.
+func logOrigin(t reflect.Type) (timeField, replicaField int, err error) {
+	if t.Kind() != reflect.Struct {
+		return 0, 0, fmt.Errorf("log elements of type %s are not structs", t)
+	}
+	timeField, replicaField = -1, -1
+	for i, tag := range fieldTags(t) {
+		switch {
+		case tag.has("time"):
+			timeField = i
+		case tag.has("replica"):
+			replicaField = i
+		default:
+			continue
+		}
+		if !isOrdered(t.Field(i).Type.Kind()) {
+			return 0, 0, fmt.Errorf("log origin field %s.%s has no total ordering", t, t.Field(i).Name)
+		}
+	}
+	if timeField < 0 || replicaField < 0 {
+		return 0, 0, fmt.Errorf("log elements of type %s need fields tagged `crdt:\"time\"` and `crdt:\"replica\"`", t)
+	}
+	return timeField, replicaField, nil
+}
+
+// mergeLog sets the log a to the causal concatenation of the logs a and b.
+// It returns true if the value of a was modified.
+func (m *merger) mergeLog(a, b reflect.Value) bool {
+	if b.Len() == 0 {
+		return false
+	}
+	timeField, replicaField, err := logOrigin(a.Type().Elem())
+	if err != nil {
+		panic(&MergeError{Err: err})
+	}
+	compareOrigins := func(x, y reflect.Value) int {
+		if c := compare(x.Field(timeField), y.Field(timeField)); c != 0 {
+			return c
+		}
+		return compare(x.Field(replicaField), y.Field(replicaField))
+	}
+	all := reflect.MakeSlice(a.Type(), 0, a.Len()+b.Len())
+	all = reflect.AppendSlice(all, a)
+	all = reflect.AppendSlice(all, b)
+	sort.SliceStable(all.Interface(), func(i, j int) bool {
+		return compareOrigins(all.Index(i), all.Index(j)) < 0
+	})
+	elems := m.quiet()
+	elems.tag = nil
+	log := reflect.MakeSlice(a.Type(), 0, all.Len())
+	for i := 0; i < all.Len(); i++ {
+		if n := log.Len(); n > 0 && compareOrigins(log.Index(n-1), all.Index(i)) == 0 {
+			elems.merge(log.Index(n-1), all.Index(i))
+			continue
+		}
+		log = reflect.Append(log, deepCopy(all.Index(i)))
+	}
+	if reflect.DeepEqual(log.Interface(), a.Interface()) {
+		return false
+	}
+	a.Set(log)
+	return true
+}
@@ -0,0 +1,63 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type logEntry struct {
+	Time    int    `crdt:"time"`
+	Replica string `crdt:"replica"`
+	Text    string
+}
+
+type logState struct {
+	Entries []logEntry `crdt:"log"`
+}
+
+// appendEntry appends text to s on behalf of replica, with a Lamport timestamp.
+func (s *logState) appendEntry(replica, text string) {
+	time := 1
+	if n := len(s.Entries); n > 0 {
+		time = s.Entries[n-1].Time + 1
+	}
+	s.Entries = append(s.Entries, logEntry{time, replica, text})
+}
+
+func TestMergeLog(t *testing.T) {
+	var base logState
+	base.appendEntry("a", "hello")
+	a := Clone(base).(logState)
+	b := Clone(base).(logState)
+	a.appendEntry("a", "a1")
+	a.appendEntry("a", "a2")
+	b.appendEntry("b", "b1")
+
+	expected := logState{[]logEntry{{1, "a", "hello"}, {2, "a", "a1"}, {2, "b", "b1"}, {3, "a", "a2"}}}
+	ab := Join(a, b).(logState)
+	ba := Join(b, a).(logState)
+	if !reflect.DeepEqual(ab, expected) || !reflect.DeepEqual(ba, expected) {
+		t.Errorf("Join(a, b) = %v, Join(b, a) = %v, expected %v", ab, ba, expected)
+	}
+
+	// Appends after the merge follow everything either replica had seen.
+	ab.appendEntry("b", "b2")
+	if last := ab.Entries[len(ab.Entries)-1]; last.Time != 4 {
+		t.Errorf("append after merge got time %d, expected 4", last.Time)
+	}
+	if Merge(&ab, a) {
+		t.Errorf("merging a log already included reported a change")
+	}
+}
+
+func TestValidateLog(t *testing.T) {
+	type bad struct {
+		Entries []int `crdt:"log"`
+	}
+	if err := Validate(reflect.TypeOf(logState{})); err != nil {
+		t.Errorf("Validate(logState) = %v, expected nil", err)
+	}
+	if err := Validate(reflect.TypeOf(bad{})); err == nil {
+		t.Errorf("Validate of a log of ints returned nil")
+	}
+}
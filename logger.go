@@ -0,0 +1,35 @@
+package crdt
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+)
+
+// WithLogger logs every leaf decision made during the merge to logger, at debug level, as a record
+// with the message "crdt: merge decision" and the attributes path, winner, and value: the path
+// of the leaf (in the format used by WithProvenance), the side whose value won ("A" or "B"),
+// and the leaf's value after the decision, as reported by WithRedact if it is also given.
+// Leaves are reported as they are for WithProvenance.
+//
+// If logger doesn't have debug level enabled when the merge starts, nothing is reported,
+// and the merge costs no more than one without the option.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// logDecision logs a leaf decision at the current path, where winner's value won and the leaf is now value.
+func (m *merger) logDecision(winner Side, value reflect.Value) {
+	path := m.path.String()
+	var v interface{}
+	if value.IsValid() && value.CanInterface() {
+		v = value.Interface()
+	}
+	if m.redact != nil {
+		v = m.redact(path, v)
+	}
+	m.logger.LogAttrs(context.Background(), slog.LevelDebug, "crdt: merge decision",
+		slog.String("path", path), slog.String("winner", winner.String()), slog.Any("value", v))
+}
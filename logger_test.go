@@ -0,0 +1,68 @@
+package crdt
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	type A struct {
+		Name   string
+		Scores map[string]int
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	a := A{"a", map[string]int{"x": 3}}
+	b := A{"b", map[string]int{"x": 1, "y": 2}}
+	if _, err := MergeWith(&a, b, WithLogger(logger)); err != nil {
+		t.Fatal(err)
+	}
+	type record struct {
+		Msg    string
+		Level  string
+		Path   string
+		Winner string
+		Value  interface{}
+	}
+	got := map[string]record{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("couldn't decode log record %q: %v", line, err)
+		}
+		got[r.Path] = r
+	}
+	expected := map[string]record{
+		"Name":      {"crdt: merge decision", "DEBUG", "Name", "B", "b"},
+		"Scores[x]": {"crdt: merge decision", "DEBUG", "Scores[x]", "A", float64(3)},
+		"Scores[y]": {"crdt: merge decision", "DEBUG", "Scores[y]", "B", float64(2)},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("WithLogger logged %v, expected %v", got, expected)
+	}
+}
+
+func TestWithLoggerDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	a := map[string]int{"x": 1}
+	MergeWith(&a, map[string]int{"x": 2, "y": 1}, WithLogger(logger))
+	if buf.Len() != 0 {
+		t.Errorf("WithLogger logged at info level: %s", buf.String())
+	}
+}
+
+func TestWithLoggerRedact(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	a := struct{ Secret string }{}
+	MergeWith(&a, struct{ Secret string }{"hunter2"}, WithLogger(logger),
+		WithRedact(func(path string, value interface{}) interface{} { return "***" }))
+	if strings.Contains(buf.String(), "hunter2") || !strings.Contains(buf.String(), "value=***") {
+		t.Errorf("WithLogger with WithRedact logged %s", buf.String())
+	}
+}
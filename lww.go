@@ -0,0 +1,130 @@
+package crdt
+
+import "reflect"
+
+// A struct field tagged `crdt:"lww"` is last-writer-wins: rather than being merged with b's,
+// a's value is either kept or replaced wholesale with a copy of b's.
+//
+// With `crdt:"lww=Field"`, the side whose sibling field Field is greater wins, where Field
+// is compared by the package's ordering as it was before the merge. If Field is a WriteTimes map,
+// its entries for the field's name are compared instead, so that one map can time many fields.
+// If the siblings are equal, or there is no sibling, slices are compared by length, then
+// lexicographically by element, and the greater slice wins; other types are merged as usual.
+// Either way the rule is a total order on the inputs, so the result doesn't depend on the order of merges.
+
+// lwwSiblings returns, for each field of the struct a tagged with `crdt:"lww=Field"`, the comparison
+// of a's value of Field with b's: negative if b's is greater, positive if a's is, and zero if they are equal.
+// It must be called before a's fields are merged.
+func lwwSiblings(a, b reflect.Value, tags []tagOptions) map[int]int {
+	var order map[int]int
+	for i, opts := range tags {
+		sibling := opts["lww"]
+		if sibling == "" {
+			continue
+		}
+		aSibling := a.FieldByName(sibling)
+		if !aSibling.IsValid() {
+			panic(mergeErrorf("field %s: lww sibling %s does not exist", a.Type().Field(i).Name, sibling))
+		}
+		if order == nil {
+			order = make(map[int]int)
+		}
+		if aSibling.Type() == writeTimesType {
+			// The field's write times are kept in a WriteTimes map, keyed by the field's name.
+			name := reflect.ValueOf(a.Type().Field(i).Name)
+			order[i] = compare(writeTime(aSibling, name), writeTime(b.FieldByName(sibling), name))
+			continue
+		}
+		order[i] = compare(aSibling, b.FieldByName(sibling))
+	}
+	return order
+}
+
+// compare returns a negative number if a < b, a positive number if a > b, and zero if they are equal,
+// according to the package's merge rules. a and b must be totally ordered by them.
+func compare(a, b reflect.Value) int {
+	if isOrdered(a.Kind()) {
+		if less(a, b) {
+			return -1
+		} else if less(b, a) {
+			return 1
+		}
+		return 0
+	}
+	joined := join(a, b)
+	aIsJoin := reflect.DeepEqual(joined.Interface(), a.Interface())
+	bIsJoin := reflect.DeepEqual(joined.Interface(), b.Interface())
+	switch {
+	case aIsJoin && bIsJoin:
+		return 0
+	case bIsJoin:
+		return -1
+	case aIsJoin:
+		return 1
+	default:
+		panic(mergeErrorf("values of type %s are not totally ordered", a.Type()))
+	}
+}
+
+// compareSlices compares the slices a and b by length, then lexicographically by element.
+// The slices' elements must have a total ordering.
+func compareSlices(a, b reflect.Value) int {
+	if a.Len() != b.Len() {
+		return a.Len() - b.Len()
+	}
+	for i := 0; i < a.Len(); i++ {
+		if c := compare(a.Index(i), b.Index(i)); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// copySlice returns a copy of the slice s that shares no storage with it.
+func copySlice(s reflect.Value) reflect.Value {
+	if s.IsNil() {
+		return reflect.Zero(s.Type())
+	}
+	c := reflect.MakeSlice(s.Type(), s.Len(), s.Len())
+	reflect.Copy(c, s)
+	return c
+}
+
+// mergeLWW merges a field tagged `crdt:"lww"`, where order is the comparison of a's sibling
+// timestamp to b's, or zero if there is none.
+// It returns true if the value of a was modified.
+func (m *merger) mergeLWW(a, b reflect.Value, order int) bool {
+	if order == 0 {
+		if a.Kind() != reflect.Slice {
+			return m.merge(a, b)
+		}
+		order = compareSlices(a, b)
+	}
+	changed := order < 0
+	if changed {
+		if a.Kind() == reflect.Slice {
+			// The winning slice is taken whole, elements and all, so it is copied deeply.
+			a.Set(deepCopy(b))
+		} else {
+			a.Set(join(reflect.Zero(b.Type()), b))
+		}
+	}
+	m.decide(changed, a)
+	return changed
+}
+
+// A struct field tagged `crdt:"fww"` is first-writer-wins, for values that are set once and never change,
+// like a creation time or an owner: if a's value is non-zero, it is kept, and otherwise it is replaced
+// with a copy of b's. This depends on the order of merges, so it only converges if every write to
+// such a field is coordinated or writes the same value; concurrent writes of different values
+// leave each replica with whichever it saw first.
+
+// mergeFWW merges a field tagged `crdt:"fww"`. It returns true if the value of a was modified.
+func (m *merger) mergeFWW(a, b reflect.Value) bool {
+	changed := isZero(a) && !isZero(b)
+	if changed {
+		a.Set(deepCopy(b))
+	}
+	m.decide(changed, a)
+	return changed
+}
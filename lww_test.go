@@ -0,0 +1,99 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeLWWTimestamp(t *testing.T) {
+	type Config struct {
+		Hosts   []string `crdt:"lww=Updated"`
+		Updated int64
+	}
+	older := Config{[]string{"a", "b", "c"}, 1}
+	newer := Config{[]string{"z"}, 2}
+	for _, pair := range [][2]Config{{older, newer}, {newer, older}} {
+		result := Join(pair[0], pair[1]).(Config)
+		if !reflect.DeepEqual(result, newer) {
+			t.Errorf("Join(%v, %v) = %v, expected %v", pair[0], pair[1], result, newer)
+		}
+	}
+
+	// The winning slice is copied, not shared.
+	value := older
+	if !Merge(&value, newer) {
+		t.Errorf("Merge(older, newer) = false, expected true")
+	}
+	value.Hosts[0] = "changed"
+	if newer.Hosts[0] != "z" {
+		t.Errorf("mutating merge result changed its input: %v", newer.Hosts)
+	}
+	if Merge(&value, older) {
+		t.Errorf("Merge(newer, older) = true, expected false")
+	}
+}
+
+func TestMergeLWWSliceIndependent(t *testing.T) {
+	type Config struct {
+		Ptrs    []*int           `crdt:"lww=Updated"`
+		Lists   [][]int          `crdt:"lww=Updated"`
+		Maps    []map[string]int `crdt:"lww=Updated"`
+		Updated int64
+	}
+	x := 1
+	b := Config{[]*int{&x}, [][]int{{1}}, []map[string]int{{"a": 1}}, 2}
+	result := Join(Config{}, b).(Config)
+	if !reflect.DeepEqual(result, b) {
+		t.Fatalf("Join(Config{}, %v) = %v, expected %v", b, result, b)
+	}
+	if result.Ptrs[0] == &x {
+		t.Errorf("Join result shares a pointer with its operand")
+	}
+	*result.Ptrs[0], result.Lists[0][0], result.Maps[0]["a"] = 10, 10, 10
+	if x != 1 || b.Lists[0][0] != 1 || b.Maps[0]["a"] != 1 {
+		t.Errorf("mutating the Join result changed its operand: %v", b)
+	}
+}
+
+func TestMergeLWWSlice(t *testing.T) {
+	type Config struct {
+		Hosts []string `crdt:"lww"`
+	}
+	testJoin := func(a, b, expected Config) {
+		for _, pair := range [][2]Config{{a, b}, {b, a}} {
+			if result := Join(pair[0], pair[1]); !reflect.DeepEqual(result, expected) {
+				t.Errorf("Join(%v, %v) = %v, expected %v", pair[0], pair[1], result, expected)
+			}
+		}
+	}
+	// Longer wins.
+	testJoin(Config{[]string{"b"}}, Config{[]string{"a", "a"}}, Config{[]string{"a", "a"}})
+	// Equal lengths compare lexicographically, atomically.
+	testJoin(Config{[]string{"a", "z"}}, Config{[]string{"b", "a"}}, Config{[]string{"b", "a"}})
+	// Empty is bottom.
+	testJoin(Config{}, Config{[]string{"a"}}, Config{[]string{"a"}})
+	testJoin(Config{[]string{"a"}}, Config{[]string{"a"}}, Config{[]string{"a"}})
+}
+
+func TestMergeFWW(t *testing.T) {
+	type record struct {
+		Owner   string   `crdt:"fww"`
+		Created int64    `crdt:"fww"`
+		Tags    []string `crdt:"fww"`
+		Views   int
+	}
+	a := record{Owner: "alice", Created: 100, Views: 1}
+	b := record{Owner: "bob", Created: 50, Tags: []string{"x"}, Views: 2}
+	Merge(&a, b)
+	expected := record{Owner: "alice", Created: 100, Tags: []string{"x"}, Views: 2}
+	if !reflect.DeepEqual(a, expected) {
+		t.Errorf("Merge gave %#v, expected %#v", a, expected)
+	}
+	b.Tags[0] = "y"
+	if a.Tags[0] != "x" {
+		t.Errorf("merged fww field shares storage with b")
+	}
+	if Merge(&a, record{Owner: "carol"}) {
+		t.Errorf("merging a different value into a set fww field reported a change")
+	}
+}
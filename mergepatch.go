@@ -0,0 +1,71 @@
+package crdt
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// MergePatch returns an RFC 7386 JSON Merge Patch document that, applied to the JSON encoding of old,
+// gives the JSON encoding of new, for interoperating with systems that exchange changes in that format.
+// Members that are equal in both are left out of the patch, and objects are patched member by member;
+// any other value that differs, including an array, is replaced whole.
+//
+// The formats' notions of deletion differ. A merge patch deletes a member by setting it to null,
+// so a member that old's encoding has but new's lacks, such as a map key or an omitempty field,
+// is patched to null. A CRDT never deletes by merging: merging a state without a map key keeps the key,
+// and a value can only be removed by tombstoning it, which encodes as an ordinary change to the tombstone.
+// Likewise, a member whose new value is null, such as a nil map or pointer, can't be expressed by a patch,
+// which would delete it instead. Usually new is the result of merging into old, which only adds keys
+// and raises values, so its patch sets no nulls.
+func MergePatch(old, new interface{}) ([]byte, error) {
+	oldDoc, err := jsonDocument(old)
+	if err != nil {
+		return nil, err
+	}
+	newDoc, err := jsonDocument(new)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(oldDoc, newDoc))
+}
+
+// jsonDocument returns the JSON encoding of v, decoded as a generic JSON value.
+// Numbers are decoded as json.Number, so that integers too large for a float64 keep their precision.
+func jsonDocument(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// mergePatch returns the merge patch from the generic JSON value old to new.
+func mergePatch(old, new interface{}) interface{} {
+	oldObject, oldOK := old.(map[string]interface{})
+	newObject, newOK := new.(map[string]interface{})
+	if !oldOK || !newOK {
+		return new
+	}
+	patch := map[string]interface{}{}
+	for name, newValue := range newObject {
+		oldValue, ok := oldObject[name]
+		if !ok {
+			patch[name] = newValue
+		} else if !reflect.DeepEqual(oldValue, newValue) {
+			patch[name] = mergePatch(oldValue, newValue)
+		}
+	}
+	for name := range oldObject {
+		if _, ok := newObject[name]; !ok {
+			patch[name] = nil
+		}
+	}
+	return patch
+}
@@ -0,0 +1,68 @@
+package crdt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	type profile struct {
+		Name  string
+		Tags  []string          `crdt:"set"`
+		Prefs map[string]string `json:",omitempty"`
+	}
+	type state struct {
+		Version int
+		Profile profile
+		Scores  map[string]int
+	}
+	old := state{1, profile{"ann", []string{"a"}, nil}, map[string]int{"x": 1, "y": 2}}
+	for _, test := range []struct {
+		new      state
+		expected string
+	}{
+		{old, `{}`},
+		{
+			Join(old, state{2, profile{"bob", nil, map[string]string{"theme": "dark"}}, map[string]int{"x": 3, "z": 1}}).(state),
+			`{"Version":2,"Profile":{"Name":"bob","Prefs":{"theme":"dark"}},"Scores":{"x":3,"z":1}}`,
+		},
+		{
+			Join(old, state{Profile: profile{Tags: []string{"b"}}}).(state),
+			`{"Profile":{"Tags":["a","b"]}}`,
+		},
+		{
+			state{1, profile{"ann", []string{"a"}, nil}, map[string]int{"x": 1}},
+			`{"Scores":{"y":null}}`,
+		},
+	} {
+		patch, err := MergePatch(old, test.new)
+		if err != nil {
+			t.Fatalf("MergePatch(%#v, %#v) = %v", old, test.new, err)
+		}
+		var got, expected interface{}
+		json.Unmarshal(patch, &got)
+		json.Unmarshal([]byte(test.expected), &expected)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("MergePatch(%#v, %#v) = %s, expected %s", old, test.new, patch, test.expected)
+		}
+	}
+}
+
+func TestMergePatchLargeInteger(t *testing.T) {
+	// 1<<53 and 1<<53 + 1 are equal as float64s.
+	old, new := map[string]int64{"n": 1 << 53}, map[string]int64{"n": 1<<53 + 1}
+	patch, err := MergePatch(old, new)
+	if err != nil {
+		t.Fatalf("MergePatch(%v, %v) = %v", old, new, err)
+	}
+	if expected := `{"n":9007199254740993}`; string(patch) != expected {
+		t.Errorf("MergePatch(%v, %v) = %s, expected %s", old, new, patch, expected)
+	}
+}
+
+func TestMergePatchError(t *testing.T) {
+	if _, err := MergePatch(map[string]interface{}{"f": func() {}}, 1); err == nil {
+		t.Errorf("MergePatch of an unencodable value gave no error")
+	}
+}
@@ -0,0 +1,104 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// migration upgrades a value of one type to another.
+type migration struct {
+	to reflect.Type
+	fn func(interface{}) interface{}
+}
+
+// migrations holds the migrations registered with RegisterMigration, keyed by source type.
+var migrations struct {
+	sync.RWMutex
+	from map[reflect.Type][]migration
+}
+
+// RegisterMigration registers fn as a way to upgrade a value of type from to a value of type to.
+// fn is passed a value of type from, and must return a value of type to.
+//
+// Migrations are consulted by the decode-then-merge entry points (UnmarshalMergeJSON and
+// UnmarshalMergeGob) when the decoded state is not of the type being merged into.
+// Migrations may be chained: a value is upgraded through as many registered steps as
+// it takes to reach the target type.
+func RegisterMigration(from, to reflect.Type, fn func(interface{}) interface{}) {
+	migrations.Lock()
+	defer migrations.Unlock()
+	if migrations.from == nil {
+		migrations.from = make(map[reflect.Type][]migration)
+	}
+	steps := migrations.from[from]
+	for i, step := range steps {
+		if step.to == to {
+			steps[i].fn = fn
+			return
+		}
+	}
+	migrations.from[from] = append(steps, migration{to, fn})
+}
+
+// migrationPath returns the shortest chain of migrations from one type to another,
+// or false if there is none.
+func migrationPath(from, to reflect.Type) ([]migration, bool) {
+	migrations.RLock()
+	defer migrations.RUnlock()
+	paths := map[reflect.Type][]migration{from: nil}
+	queue := []reflect.Type{from}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		if t == to {
+			return paths[t], true
+		}
+		for _, step := range migrations.from[t] {
+			if _, seen := paths[step.to]; !seen {
+				paths[step.to] = append(append([]migration(nil), paths[t]...), step)
+				queue = append(queue, step.to)
+			}
+		}
+	}
+	return nil, false
+}
+
+// migrationSources returns the types that can be migrated to t, in a deterministic order.
+func migrationSources(t reflect.Type) []reflect.Type {
+	migrations.RLock()
+	var candidates []reflect.Type
+	for from := range migrations.from {
+		if from != t {
+			candidates = append(candidates, from)
+		}
+	}
+	migrations.RUnlock()
+	var sources []reflect.Type
+	for _, from := range candidates {
+		if _, ok := migrationPath(from, t); ok {
+			sources = append(sources, from)
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].String() < sources[j].String() })
+	return sources
+}
+
+// migrate upgrades v to a value of type t, using the registered migrations.
+func migrate(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	if v.Type() == t {
+		return v, nil
+	}
+	path, ok := migrationPath(v.Type(), t)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("crdt: no migration from %s to %s", v.Type(), t)
+	}
+	for _, step := range path {
+		v = reflect.ValueOf(step.fn(v.Interface()))
+		if v.Type() != step.to {
+			return reflect.Value{}, fmt.Errorf("crdt: migration to %s returned %s", step.to, v.Type())
+		}
+	}
+	return v, nil
+}
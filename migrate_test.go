@@ -0,0 +1,72 @@
+package crdt
+
+import (
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+type migrateV1 struct {
+	Name  string
+	Count int
+}
+
+type migrateV2 struct {
+	Name   string
+	Totals map[string]int
+}
+
+func init() {
+	gob.Register(migrateV1{})
+	gob.Register(migrateV2{})
+	RegisterMigration(reflect.TypeOf(migrateV1{}), reflect.TypeOf(migrateV2{}), func(v interface{}) interface{} {
+		v1 := v.(migrateV1)
+		return migrateV2{Name: v1.Name, Totals: map[string]int{"legacy": v1.Count}}
+	})
+}
+
+func TestMigrateJSON(t *testing.T) {
+	value := migrateV2{"a", map[string]int{"x": 1}}
+	changed, err := UnmarshalMergeJSON([]byte(`{"Name": "b", "Count": 3}`), &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Errorf("UnmarshalMergeJSON changed = false, expected true")
+	}
+	if expected := (migrateV2{"b", map[string]int{"x": 1, "legacy": 3}}); !reflect.DeepEqual(value, expected) {
+		t.Errorf("After merge was %#v, expected %#v", value, expected)
+	}
+
+	// Current-shaped state decodes without migrating.
+	if _, err := UnmarshalMergeJSON([]byte(`{"Totals": {"x": 2}}`), &value); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (migrateV2{"b", map[string]int{"x": 2, "legacy": 3}}); !reflect.DeepEqual(value, expected) {
+		t.Errorf("After merge was %#v, expected %#v", value, expected)
+	}
+}
+
+func TestMigrateGob(t *testing.T) {
+	data, err := MarshalGob(migrateV1{"b", 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := migrateV2{"a", map[string]int{"x": 1}}
+	if _, err := UnmarshalMergeGob(data, &value); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (migrateV2{"b", map[string]int{"x": 1, "legacy": 3}}); !reflect.DeepEqual(value, expected) {
+		t.Errorf("After merge was %#v, expected %#v", value, expected)
+	}
+
+	// There is no way back from v2 to v1.
+	data, err = MarshalGob(migrateV2{"b", nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var old migrateV1
+	if _, err := UnmarshalMergeGob(data, &old); err == nil {
+		t.Errorf("UnmarshalMergeGob without a migration returned no error")
+	}
+}
@@ -0,0 +1,44 @@
+package crdt
+
+import "fmt"
+
+// Money is an amount of money in cents. It merges to the greater amount, except that zero,
+// meaning no balance has been reported, is the bottom value: any non-zero amount,
+// even a negative one, is greater than it.
+type Money int64
+
+// Cents returns the amount in cents.
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// Add returns the sum of m and other.
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+// Sub returns the difference of m and other.
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+// String formats the amount with two decimal places, e.g. "-12.05".
+func (m Money) String() string {
+	sign := ""
+	cents := uint64(m)
+	if m < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// Merge merges another Money into this one.
+func (m *Money) Merge(other interface{}) bool {
+	o := other.(Money)
+	if o == 0 || (*m != 0 && o <= *m) {
+		return false
+	}
+	*m = o
+	return true
+}
@@ -0,0 +1,48 @@
+package crdt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMoneyString(t *testing.T) {
+	testString := func(m Money, expected string) {
+		if s := m.String(); s != expected {
+			t.Errorf("Money(%d).String() = %q, expected %q", int64(m), s, expected)
+		}
+	}
+	testString(0, "0.00")
+	testString(5, "0.05")
+	testString(1234, "12.34")
+	testString(-1205, "-12.05")
+	testString(math.MinInt64, "-92233720368547758.08")
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	if sum := Money(150).Add(275); sum != 425 || sum.Cents() != 425 {
+		t.Errorf("150 + 275 = %v, expected 4.25", sum)
+	}
+	if diff := Money(150).Sub(275); diff != -125 {
+		t.Errorf("150 - 275 = %v, expected -1.25", diff)
+	}
+}
+
+func TestMergeMoney(t *testing.T) {
+	type Account struct {
+		Balance Money
+	}
+	testJoin := func(a, b, expected Money) {
+		for _, pair := range [][2]Money{{a, b}, {b, a}} {
+			if result := Join(Account{pair[0]}, Account{pair[1]}).(Account); result.Balance != expected {
+				t.Errorf("Join(%v, %v) = %v, expected %v", pair[0], pair[1], result.Balance, expected)
+			}
+		}
+	}
+	testJoin(100, 250, 250)
+	testJoin(-100, 50, 50)
+	testJoin(-100, -50, -50)
+	// Zero is bottom: an unreported balance doesn't override a reported negative one.
+	testJoin(0, -100, -100)
+	testJoin(0, 100, 100)
+	testJoin(0, 0, 0)
+}
@@ -0,0 +1,11 @@
+package crdt
+
+import "net/netip"
+
+// The netip value types are structs with unexported fields, but are totally ordered by
+// their Compare methods, with the zero (invalid) value sorting first.
+func init() {
+	RegisterCompare[netip.Addr]()
+	RegisterCompare[netip.AddrPort]()
+	RegisterCompare[netip.Prefix]()
+}
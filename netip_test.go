@@ -0,0 +1,39 @@
+package crdt
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestMergeAddr(t *testing.T) {
+	low := netip.MustParseAddr("10.0.0.1")
+	high := netip.MustParseAddr("10.0.0.2")
+	testJoin := func(a, b, expected netip.Addr) {
+		if result := Join(a, b); result != expected {
+			t.Errorf("Join(%v, %v) = %v, expected %v", a, b, result, expected)
+		}
+	}
+	testJoin(low, high, high)
+	testJoin(high, low, high)
+	testJoin(netip.Addr{}, low, low)
+	testJoin(low, netip.Addr{}, low)
+	testJoin(netip.Addr{}, netip.Addr{}, netip.Addr{})
+}
+
+func TestMergeAddrField(t *testing.T) {
+	type A struct {
+		Highest netip.Addr
+		Seen    map[netip.Addr]int
+	}
+	addr := netip.MustParseAddr("::1")
+	value := A{}
+	if !Merge(&value, A{addr, map[netip.Addr]int{addr: 1}}) {
+		t.Errorf("Merge(a, b) = false, expected true")
+	}
+	if value.Highest != addr || value.Seen[addr] != 1 {
+		t.Errorf("After merge was %#v", value)
+	}
+	if Merge(&value, A{}) {
+		t.Errorf("Merge(a, zero) = true, expected false")
+	}
+}
@@ -0,0 +1,92 @@
+package crdt
+
+import "reflect"
+
+// normalizer carries the pointers normalized so far through a Normalize.
+type normalizer struct {
+	// copies maps each pointer already normalized to its normalized copy,
+	// so that shared and cyclic pointers are normalized once and stay shared.
+	copies map[copiedPointer]reflect.Value
+}
+
+// normalize returns the canonical form of v, as described by Normalize,
+// where tag holds the `crdt` tag options in effect for v.
+func (nz *normalizer) normalize(v reflect.Value, tag tagOptions) reflect.Value {
+	t := v.Type()
+	if reflect.PointerTo(t).Implements(mergerType) || registered(t) != nil || reflect.PointerTo(t).Implements(comparableType) {
+		return deepCopy(v)
+	}
+	n := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			break
+		}
+		key := copiedPointer{t, v.Pointer()}
+		if c, ok := nz.copies[key]; ok {
+			n.Set(c)
+			break
+		}
+		if nz.copies == nil {
+			nz.copies = make(map[copiedPointer]reflect.Value)
+		}
+		n.Set(reflect.New(t.Elem()))
+		nz.copies[key] = n
+		n.Elem().Set(nz.normalize(v.Elem(), tag))
+	case reflect.Slice:
+		if v.Len() == 0 {
+			break
+		}
+		if key := tag["set"]; key != "" {
+			(&merger{tag: tag}).mergeKeyedSet(n, v, key)
+		} else if tag.has("set") {
+			mergeSetUnion(n, v)
+		} else {
+			copyInto(n, v)
+		}
+	case reflect.Struct:
+		n.Set(v)
+		tags := fieldTags(t)
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath == "" {
+				n.Field(i).Set(nz.normalize(v.Field(i), tags[i]))
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			value := nz.normalize(iter.Value(), tag)
+			if isZero(value) && !tag.has("2pset") {
+				continue
+			}
+			if n.IsNil() {
+				n.Set(reflect.MakeMap(t))
+			}
+			n.SetMapIndex(iter.Key(), value)
+		}
+	default:
+		n.Set(v)
+	}
+	return n
+}
+
+// Normalize returns a canonical form of a, suitable for storage, hashing, or comparison with reflect.DeepEqual:
+// map entries whose values are zero (the bottom value) are removed, empty maps and slices become nil,
+// and slices merged as sets (`crdt:"set"`) are sorted and deduplicated. Values merged by a Merger,
+// registered MergeFunc, or Comparable are copied as they are. a is not modified.
+// Pointers shared within a, including those forming cycles, remain shared in the result.
+//
+// Normalizing doesn't change a's position in the lattice: Join(a, Normalize(a)) is lattice-equal to a,
+// and Normalize(Join(a, zero)) is DeepEqual to Normalize(a).
+func Normalize(a interface{}) interface{} {
+	return new(normalizer).normalize(reflect.ValueOf(a), nil).Interface()
+}
+
+// Equal returns true if a and b are equal as CRDT states, that is, if their normalized forms are DeepEqual.
+// a and b must be values of the same type.
+func Equal(a, b interface{}) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		panic("a and b must be the same type")
+	}
+	return reflect.DeepEqual(Normalize(a), Normalize(b))
+}
@@ -0,0 +1,58 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type normalizeState struct {
+	Name   string
+	Counts map[string]int
+	Tags   map[string][]string `crdt:"set"`
+	Nested map[string]map[string]int
+	Inner  *normalizeState
+}
+
+func TestNormalize(t *testing.T) {
+	a := normalizeState{
+		Name:   "a",
+		Counts: map[string]int{"x": 1, "zero": 0},
+		Tags:   map[string][]string{"t": {"b", "a", "b"}, "empty": {}},
+		Nested: map[string]map[string]int{"n": {"zero": 0}},
+		Inner:  &normalizeState{Counts: map[string]int{}},
+	}
+	expected := normalizeState{
+		Name:   "a",
+		Counts: map[string]int{"x": 1},
+		Tags:   map[string][]string{"t": {"a", "b"}},
+		Inner:  &normalizeState{},
+	}
+	if normalized := Normalize(a); !reflect.DeepEqual(normalized, expected) {
+		t.Errorf("Normalize(%#v) = %#v, expected %#v", a, normalized, expected)
+	}
+	if !reflect.DeepEqual(Normalize(a), Normalize(Join(a, normalizeState{}))) {
+		t.Errorf("Normalize(a) and Normalize(Join(a, bottom)) differ")
+	}
+	// Normalize doesn't modify its input.
+	if len(a.Tags["t"]) != 3 || len(a.Counts) != 2 {
+		t.Errorf("Normalize modified its input: %#v", a)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := normalizeState{Counts: map[string]int{"x": 1}, Tags: map[string][]string{"t": {"a", "b"}}}
+	b := normalizeState{Counts: map[string]int{"x": 1, "y": 0}, Tags: map[string][]string{"t": {"b", "a"}}, Nested: map[string]map[string]int{}}
+	if !Equal(a, b) {
+		t.Errorf("Equal(%#v, %#v) = false, expected true", a, b)
+	}
+	if !reflect.DeepEqual(Normalize(a), Normalize(b)) {
+		t.Errorf("Equal values normalize differently: %#v and %#v", Normalize(a), Normalize(b))
+	}
+	if Fingerprint(a) != Fingerprint(b) {
+		t.Errorf("Equal values have different fingerprints")
+	}
+	c := normalizeState{Counts: map[string]int{"x": 2}}
+	if Equal(a, c) {
+		t.Errorf("Equal(%#v, %#v) = true, expected false", a, c)
+	}
+}
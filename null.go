@@ -0,0 +1,33 @@
+package crdt
+
+import (
+	"reflect"
+	"strings"
+)
+
+// A database/sql.Null[T] is merged as an optional T: a Null that isn't Valid is the bottom value,
+// whatever its V holds, so a valid Null always replaces an invalid one, and two valid Nulls have their Vs
+// merged. A Null field's `crdt` tag applies to its V.
+
+// isSQLNull returns true if t is an instantiation of database/sql.Null.
+func isSQLNull(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" && strings.HasPrefix(t.Name(), "Null[")
+}
+
+// mergeNull merges the sql.Null b into the sql.Null a.
+// It returns true if the value of a was modified.
+func (m *merger) mergeNull(a, b reflect.Value) bool {
+	if !b.FieldByName("Valid").Bool() {
+		m.decide(false, a)
+		return false
+	}
+	if !a.FieldByName("Valid").Bool() {
+		m.chargeCopy(b)
+		a.Set(deepCopy(b))
+		m.decide(true, a)
+		return true
+	}
+	m.path.pushField("V")
+	defer m.path.pop()
+	return m.merge(a.FieldByName("V"), b.FieldByName("V"))
+}
@@ -0,0 +1,61 @@
+package crdt
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestMergeSQLNull(t *testing.T) {
+	ints := []struct {
+		a, b     sql.Null[int]
+		changed  bool
+		expected sql.Null[int]
+	}{
+		{sql.Null[int]{}, sql.Null[int]{}, false, sql.Null[int]{}},
+		{sql.Null[int]{}, sql.Null[int]{V: 0, Valid: true}, true, sql.Null[int]{V: 0, Valid: true}},
+		{sql.Null[int]{V: 5}, sql.Null[int]{V: 3, Valid: true}, true, sql.Null[int]{V: 3, Valid: true}},
+		{sql.Null[int]{V: 3, Valid: true}, sql.Null[int]{V: 5}, false, sql.Null[int]{V: 3, Valid: true}},
+		{sql.Null[int]{V: 3, Valid: true}, sql.Null[int]{V: 5, Valid: true}, true, sql.Null[int]{V: 5, Valid: true}},
+		{sql.Null[int]{V: 5, Valid: true}, sql.Null[int]{V: 3, Valid: true}, false, sql.Null[int]{V: 5, Valid: true}},
+	}
+	for _, test := range ints {
+		a := test.a
+		if changed := Merge(&a, test.b); changed != test.changed || a != test.expected {
+			t.Errorf("Merge(%+v, %+v) = %v, %+v; expected %v, %+v", test.a, test.b, changed, a, test.changed, test.expected)
+		}
+		compiled := test.a
+		if changed := Compile[sql.Null[int]]()(&compiled, test.b); changed != test.changed || compiled != test.expected {
+			t.Errorf("Compiled merge(%+v, %+v) = %v, %+v; expected %v, %+v", test.a, test.b, changed, compiled, test.changed, test.expected)
+		}
+	}
+
+	texts := []struct {
+		a, b     sql.Null[string]
+		changed  bool
+		expected sql.Null[string]
+	}{
+		{sql.Null[string]{V: "z"}, sql.Null[string]{V: "a", Valid: true}, true, sql.Null[string]{V: "a", Valid: true}},
+		{sql.Null[string]{V: "a", Valid: true}, sql.Null[string]{V: "z"}, false, sql.Null[string]{V: "a", Valid: true}},
+		{sql.Null[string]{V: "a", Valid: true}, sql.Null[string]{V: "b", Valid: true}, true, sql.Null[string]{V: "b", Valid: true}},
+		{sql.Null[string]{V: "b", Valid: true}, sql.Null[string]{V: "a", Valid: true}, false, sql.Null[string]{V: "b", Valid: true}},
+	}
+	for _, test := range texts {
+		a := test.a
+		if changed := Merge(&a, test.b); changed != test.changed || a != test.expected {
+			t.Errorf("Merge(%+v, %+v) = %v, %+v; expected %v, %+v", test.a, test.b, changed, a, test.changed, test.expected)
+		}
+	}
+
+	type row struct {
+		Tags sql.Null[[]string] `crdt:"set"`
+	}
+	a := row{sql.Null[[]string]{V: []string{"b"}, Valid: true}}
+	b := row{sql.Null[[]string]{V: []string{"a"}, Valid: true}}
+	if err := Validate(reflect.TypeOf(a)); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	if !Merge(&a, b) || !reflect.DeepEqual(a.Tags.V, []string{"a", "b"}) {
+		t.Errorf("After merge was %+v, expected the union of the tags", a)
+	}
+}
@@ -0,0 +1,222 @@
+package crdt
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+)
+
+// An Option configures the behavior of a merge, or of another operation that walks values,
+// such as Walk, Diff, and Fingerprint. Options that don't apply to an operation are ignored by it.
+type Option func(*config)
+
+// config holds the options for a merge.
+type config struct {
+	provenance   func(path string, winner Side)
+	verifyLaws   bool
+	lawViolation func(path string, err error)
+	redact       func(path string, value interface{}) interface{}
+	textScalars  bool
+	strictEnums  bool
+	keyOrder     func(a, b interface{}) bool
+	pnFields     [2]string
+	sliceLen     SliceLenPolicy
+	intern       func(string) string
+	errs         *[]error
+	deadline     time.Time
+	unexported   bool
+	selfCheck    bool
+	maxMapSize   int
+	mapTimestamp string
+	logger       *slog.Logger
+	channels     bool
+	budget       *allocBudget
+}
+
+// leafHooks returns true if any option needs to see every leaf decision or stored leaf,
+// which rules out fast paths that skip them.
+func (c *config) leafHooks() bool {
+	return c.provenance != nil || c.intern != nil || c.logger != nil
+}
+
+// WithStringInterner makes the merge pass every string it stores, whether a leaf value or a new map key,
+// through intern, and store the result instead, which must equal its argument. Routing strings
+// through an interner, such as unique.Make(s).Value(), lets equal strings from different keys and replicas
+// share storage, which can greatly reduce the memory used by large, string-heavy states.
+// intern may be called concurrently by concurrent merges.
+func WithStringInterner(intern func(string) string) Option {
+	return func(c *config) {
+		c.intern = intern
+	}
+}
+
+// quiet returns a merger with m's options and tag, but without options that report on the merge,
+// for merges that copy values rather than make decisions.
+func (m *merger) quiet() *merger {
+	q := &merger{config: m.config, tag: m.tag, copying: true}
+	q.provenance = nil
+	q.logger = nil
+	q.verifyLaws = false
+	return q
+}
+
+// decide reports a leaf decision at the current path: if changed, b's value won, otherwise a's did.
+// value is the leaf's value after the decision.
+func (m *merger) decide(changed bool, value reflect.Value) {
+	if m.provenance == nil && m.logger == nil {
+		return
+	}
+	winner := SideA
+	if changed {
+		winner = SideB
+	}
+	if m.provenance != nil {
+		m.provenance(m.path.String(), winner)
+	}
+	if m.logger != nil {
+		m.logDecision(winner, value)
+	}
+}
+
+// Side identifies one of the two values being merged.
+type Side int
+
+const (
+	// SideA is the value being merged into.
+	SideA Side = iota
+	// SideB is the value being merged from.
+	SideB
+)
+
+func (s Side) String() string {
+	if s == SideB {
+		return "B"
+	}
+	return "A"
+}
+
+// WithProvenance calls fn for every leaf decision made during the merge, with the path of the leaf
+// and the side whose value won. Paths separate struct fields with dots and put map keys in brackets,
+// e.g. "Users[alice].Name"; the root is the empty string. a's value is reported as the winner
+// when the merge leaves it unchanged, including when both sides are equal.
+// Leaves are ordered values, values merged by a Merger or registered MergeFunc,
+// and map entries present only in b. Map entries present only in a are not reported.
+func WithProvenance(fn func(path string, winner Side)) Option {
+	return func(c *config) {
+		c.provenance = fn
+	}
+}
+
+// newMerger returns a merger configured by opts.
+func newMerger(opts []Option) *merger {
+	m := new(merger)
+	for _, opt := range opts {
+		opt(&m.config)
+	}
+	if m.logger != nil && !m.logger.Enabled(context.Background(), slog.LevelDebug) {
+		// Don't pay for reporting decisions that wouldn't be logged.
+		m.logger = nil
+	}
+	return m
+}
+
+// run merges b into a, returning any MergeError raised during the merge as an error.
+func (m *merger) run(a, b reflect.Value) (changed bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			mergeErr, ok := r.(*MergeError)
+			if !ok {
+				panic(r)
+			}
+			if mergeErr.Path == "" {
+				mergeErr.Path = m.path.String()
+			}
+			err = mergeErr
+		}
+	}()
+	changed = m.merge(a, b)
+	if m.selfCheck {
+		m.checkSelf(a, b)
+	}
+	return changed, nil
+}
+
+// MergeWith sets the value of a to the least upper bound of (a, b), like Merge, configured by opts.
+// It returns true if the value of a was modified, or an error if some part of the values can't be merged,
+// in which case a may have been partially merged.
+// a must be a pointer to a mergeable type, and b must be a value of the same type or, to avoid copying
+// large values of b, a pointer to one, which is read in place and not modified.
+func MergeWith(a, b interface{}, opts ...Option) (bool, error) {
+	aVal, bVal := mergeArgs(a, b)
+	return newMerger(opts).run(aVal, bVal)
+}
+
+// mergeArgs checks the arguments of MergeWith, and returns the values they refer to.
+func mergeArgs(a, b interface{}) (aVal, bVal reflect.Value) {
+	aVal = reflect.ValueOf(a)
+	bVal = reflect.ValueOf(b)
+	if aVal.Kind() != reflect.Ptr {
+		panic("a must be a pointer")
+	}
+	if bVal.Type() == aVal.Type() {
+		if bVal.IsNil() {
+			panic("b must not be a nil pointer")
+		}
+		bVal = bVal.Elem()
+	}
+	if aVal.Elem().Type() != bVal.Type() {
+		panic("a and &b must be the same type")
+	}
+	return aVal.Elem(), bVal
+}
+
+// JoinWith returns the least upper bound of (a, b), like Join, configured by opts,
+// or an error if some part of the values can't be merged.
+// Options that report on the merge, like WithProvenance, see a merge of b into a copy of a.
+// Both a and b must be mergeable values of the same type.
+func JoinWith(a, b interface{}, opts ...Option) (interface{}, error) {
+	aVal := reflect.ValueOf(a)
+	bVal := reflect.ValueOf(b)
+	if aVal.Type() != bVal.Type() {
+		panic("a and b must be the same type")
+	}
+	if delegates(aVal.Type()) {
+		// See join.
+		aVal, bVal = deepCopy(aVal), deepCopy(bVal)
+	}
+	value := reflect.New(aVal.Type()).Elem()
+	m := newMerger(opts)
+	if _, err := m.quiet().run(value, aVal); err != nil {
+		return nil, err
+	}
+	if _, err := m.run(value, bVal); err != nil {
+		return nil, err
+	}
+	return value.Interface(), nil
+}
+
+// MergeError reports a part of the values being merged that couldn't be merged.
+type MergeError struct {
+	// Path is the location of the part, in the format used by WithProvenance.
+	Path string
+	// Err describes why it couldn't be merged.
+	Err error
+}
+
+// mergeErrorf returns a MergeError for the current path, whose Err is formatted from format and args.
+func mergeErrorf(format string, args ...interface{}) *MergeError {
+	return &MergeError{Err: fmt.Errorf(format, args...)}
+}
+
+func (e *MergeError) Error() string {
+	if e.Path == "" {
+		return "crdt: " + e.Err.Error()
+	}
+	return "crdt: " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *MergeError) Unwrap() error {
+	return e.Err
+}
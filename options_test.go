@@ -0,0 +1,153 @@
+package crdt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func TestWithProvenance(t *testing.T) {
+	type Inner struct {
+		X int
+		Y string
+	}
+	type A struct {
+		I      int
+		S      string
+		Inner  Inner
+		Counts map[string]int
+		Max    MaxRegister[int]
+	}
+	a := A{1, "b", Inner{2, "x"}, map[string]int{"k": 1, "only-a": 1}, MaxRegister[int]{}}
+	b := A{2, "a", Inner{2, "y"}, map[string]int{"k": 0, "only-b": 1}, MaxRegister[int]{-1, true}}
+	winners := map[string]Side{}
+	value := a
+	MergeWith(&value, b, WithProvenance(func(path string, winner Side) {
+		if _, ok := winners[path]; ok {
+			t.Errorf("leaf %q reported twice", path)
+		}
+		winners[path] = winner
+	}))
+	expected := map[string]Side{
+		"I":              SideB,
+		"S":              SideA,
+		"Inner.X":        SideA,
+		"Inner.Y":        SideB,
+		"Counts[k]":      SideA,
+		"Counts[only-b]": SideB,
+		"Max":            SideB,
+	}
+	if !reflect.DeepEqual(winners, expected) {
+		t.Errorf("winners = %v, expected %v", winners, expected)
+	}
+	expectedValue := A{2, "b", Inner{2, "y"}, map[string]int{"k": 1, "only-a": 1, "only-b": 1}, MaxRegister[int]{-1, true}}
+	if !reflect.DeepEqual(value, expectedValue) {
+		t.Errorf("After merge was %#v, expected %#v", value, expectedValue)
+	}
+}
+
+func TestMergeWithOptions(t *testing.T) {
+	type A struct {
+		Good MaxRegister[int]
+		Sum  summingInt
+	}
+	winners := map[string]Side{}
+	violations := map[string]error{}
+	value := A{MaxRegister[int]{2, true}, 1}
+	changed, err := MergeWith(&value, A{MaxRegister[int]{1, true}, 1},
+		WithProvenance(func(path string, winner Side) {
+			winners[path] = winner
+		}),
+		WithVerifyLaws(func(path string, err error) {
+			violations[path] = err
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Errorf("MergeWith changed = false, expected true")
+	}
+	if expected := (map[string]Side{"Good": SideA, "Sum": SideB}); !reflect.DeepEqual(winners, expected) {
+		t.Errorf("winners = %v, expected %v", winners, expected)
+	}
+	if len(violations) != 1 || violations["Sum"] == nil {
+		t.Errorf("violations = %v, expected one at Sum", violations)
+	}
+}
+
+func TestMergeWithError(t *testing.T) {
+	type A struct {
+		M map[string]chan int
+	}
+	value := A{map[string]chan int{"k": make(chan int)}}
+	_, err := MergeWith(&value, A{map[string]chan int{"k": make(chan int)}})
+	var mergeErr *MergeError
+	if !errors.As(err, &mergeErr) {
+		t.Fatalf("MergeWith returned %v, expected a *MergeError", err)
+	}
+	if mergeErr.Path != "M[k]" {
+		t.Errorf("MergeError.Path = %q, expected %q", mergeErr.Path, "M[k]")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Merge of unmergeable value didn't panic")
+		}
+	}()
+	Merge(&value, A{map[string]chan int{"k": make(chan int)}})
+}
+
+func TestJoinWith(t *testing.T) {
+	type A struct {
+		I, J int
+	}
+	winners := map[string]Side{}
+	result, err := JoinWith(A{1, 2}, A{2, 1}, WithProvenance(func(path string, winner Side) {
+		winners[path] = winner
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (A{2, 2}) {
+		t.Errorf("JoinWith = %v, expected {2 2}", result)
+	}
+	if expected := (map[string]Side{"I": SideB, "J": SideA}); !reflect.DeepEqual(winners, expected) {
+		t.Errorf("winners = %v, expected %v", winners, expected)
+	}
+	if _, err := JoinWith(make(chan int), make(chan int)); err == nil {
+		t.Errorf("JoinWith of channels returned no error")
+	}
+}
+
+func TestWithStringInterner(t *testing.T) {
+	type user struct {
+		Name string
+		Role string
+	}
+	interned := make(map[string]string)
+	interner := WithStringInterner(func(s string) string {
+		if i, ok := interned[s]; ok {
+			return i
+		}
+		interned[s] = s
+		return s
+	})
+	// Build equal strings with distinct storage, as decoding each replica's state would.
+	role := func() string { return string([]byte("admin")) }
+	a := map[string]user{"alice": {"Alice", role()}}
+	b := map[string]user{"bob": {"Bob", role()}, "carol": {"Carol", role()}}
+	if _, err := MergeWith(&a, b, interner); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MergeWith(&a, map[string]user{"alice": {"Alice", role() + "s"}}, interner); err != nil {
+		t.Fatal(err)
+	}
+	bob, carol := a["bob"].Role, a["carol"].Role
+	if bob != "admin" || unsafe.StringData(bob) != unsafe.StringData(carol) {
+		t.Errorf("merged roles %q and %q don't share storage", bob, carol)
+	}
+	if alice := a["alice"].Role; alice != "admins" || unsafe.StringData(alice) != unsafe.StringData(interned["admins"]) {
+		t.Errorf("merged role %q wasn't interned", alice)
+	}
+}
@@ -0,0 +1,34 @@
+package crdt
+
+// ORSet is an observed-remove set: an element is removed only by the adds of it that the remover
+// has observed, so an add concurrent with a remove survives it.
+// It is a TombstoneMap with AddWins policy whose values carry no information.
+// The zero value is an empty set.
+type ORSet[T comparable] struct {
+	Map TombstoneMap[T, struct{}]
+}
+
+// Add adds elem to the set on behalf of replica.
+func (s *ORSet[T]) Add(replica string, elem T) {
+	s.Map.Add(replica, elem, struct{}{})
+}
+
+// Remove removes elem from the set on behalf of replica.
+func (s *ORSet[T]) Remove(replica string, elem T) {
+	s.Map.Remove(replica, elem)
+}
+
+// Contains returns true if elem is in the set.
+func (s *ORSet[T]) Contains(elem T) bool {
+	return s.Map.Contains(elem)
+}
+
+// Elements returns the elements of the set, in no particular order.
+func (s *ORSet[T]) Elements() []T {
+	return s.Map.Keys()
+}
+
+// Merge merges another ORSet of the same type into this one.
+func (s *ORSet[T]) Merge(other interface{}) bool {
+	return s.Map.Merge(other.(ORSet[T]).Map)
+}
@@ -0,0 +1,23 @@
+package crdt
+
+import "testing"
+
+func TestORSet(t *testing.T) {
+	var base ORSet[string]
+	base.Add("a", "x")
+	a := Join(ORSet[string]{}, base).(ORSet[string])
+	b := Join(ORSet[string]{}, base).(ORSet[string])
+	a.Remove("a", "x")
+	b.Add("b", "x")
+	b.Add("b", "y")
+	for _, joined := range []ORSet[string]{Join(a, b).(ORSet[string]), Join(b, a).(ORSet[string])} {
+		if !joined.Contains("x") || !joined.Contains("y") || len(joined.Elements()) != 2 {
+			t.Errorf("joined set has elements %v, expected x and y", joined.Elements())
+		}
+	}
+	joined := Join(a, b).(ORSet[string])
+	joined.Remove("a", "x")
+	if joined.Contains("x") {
+		t.Errorf("x survived a remove that observed every add")
+	}
+}
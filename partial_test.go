@@ -0,0 +1,67 @@
+package crdt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// taggedDoc is a PartialMerger whose Merge method merges its Title as the longer string,
+// and leaves its other fields to the package.
+type taggedDoc struct {
+	Title string
+	Views map[string]int
+	Tags  []string `crdt:"set"`
+}
+
+func (d *taggedDoc) Merge(other interface{}) bool {
+	o := other.(taggedDoc)
+	if len(o.Title) <= len(d.Title) {
+		return false
+	}
+	d.Title = o.Title
+	return true
+}
+
+func (d *taggedDoc) MergeHandled() []string {
+	return []string{"Title"}
+}
+
+func TestPartialMerger(t *testing.T) {
+	a := taggedDoc{"a long title", map[string]int{"x": 1}, []string{"b"}}
+	b := taggedDoc{"short", map[string]int{"x": 2, "y": 1}, []string{"a"}}
+	expected := taggedDoc{"a long title", map[string]int{"x": 2, "y": 1}, []string{"a", "b"}}
+	for _, joined := range []taggedDoc{Join(a, b).(taggedDoc), Join(b, a).(taggedDoc)} {
+		if !reflect.DeepEqual(joined, expected) {
+			t.Errorf("Join gave %#v, expected %#v", joined, expected)
+		}
+	}
+	if !Merge(&b, a) {
+		t.Errorf("merging a into b reported no change")
+	}
+	if Merge(&b, a) {
+		t.Errorf("merging a into b again reported a change")
+	}
+	if _, err := MergeWith(&a, taggedDoc{Views: map[string]int{"z": 1}}); err != nil || a.Views["z"] != 1 {
+		t.Errorf("merging only an unhandled field gave %#v, %v", a, err)
+	}
+}
+
+// partialFunc is a PartialMerger whose unhandled field can't be merged.
+type partialFunc struct {
+	Name string
+	Fn   func()
+}
+
+func (p *partialFunc) Merge(other interface{}) bool { return false }
+
+func (p *partialFunc) MergeHandled() []string { return []string{"Name"} }
+
+func TestPartialMergerValidate(t *testing.T) {
+	if err := Validate(reflect.TypeOf(taggedDoc{})); err != nil {
+		t.Errorf("Validate(taggedDoc) = %v", err)
+	}
+	if err := Validate(reflect.TypeOf(partialFunc{})); err == nil || !strings.Contains(err.Error(), "Fn") {
+		t.Errorf("Validate(partialFunc) = %v, expected an error for Fn", err)
+	}
+}
@@ -0,0 +1,50 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// step is one step along a path from the root of a value: either a struct field or a map key.
+type step struct {
+	field string
+	key   reflect.Value
+}
+
+// path is the location of a value within the value being merged,
+// kept as a stack of steps so that it costs nothing to format unless asked.
+type path []step
+
+func (p *path) pushField(name string) {
+	*p = append(*p, step{field: name})
+}
+
+func (p *path) pushKey(key reflect.Value) {
+	*p = append(*p, step{key: key})
+}
+
+func (p *path) pop() {
+	*p = (*p)[:len(*p)-1]
+}
+
+// String formats the path with struct fields separated by dots and map keys in brackets,
+// e.g. "Users[alice].Name". Keys of maps with interface key types are qualified by their dynamic types,
+// e.g. "Counts[int64(1)]", so that keys of different types that print alike are told apart.
+// The root of the value is the empty string.
+func (p path) String() string {
+	var b strings.Builder
+	for _, s := range p {
+		if s.key.Kind() == reflect.Interface {
+			fmt.Fprintf(&b, "[%T(%v)]", s.key.Interface(), s.key.Interface())
+		} else if s.key.IsValid() {
+			fmt.Fprintf(&b, "[%v]", s.key.Interface())
+		} else {
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(s.field)
+		}
+	}
+	return b.String()
+}
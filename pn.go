@@ -0,0 +1,76 @@
+package crdt
+
+import "reflect"
+
+// The PN helpers operate on legacy states laid out as a PN-counter by convention:
+// a struct with two map[string]uint64 fields, by default named P and N, holding each replica's
+// total increments and decrements. Since each replica only ever raises its own entries,
+// the maps merge keywise by max, and the counter's value is sum(P) - sum(N).
+
+// WithPNFields makes the PN helpers use the fields named p and n, instead of P and N.
+func WithPNFields(p, n string) Option {
+	return func(c *config) {
+		c.pnFields = [2]string{p, n}
+	}
+}
+
+// pnMaps returns the P and N maps of state, a struct or pointer to one, as configured by opts.
+func pnMaps(state interface{}, opts []Option) (p, n reflect.Value) {
+	var c config
+	c.pnFields = [2]string{"P", "N"}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	v := reflect.Indirect(reflect.ValueOf(state))
+	if v.Kind() != reflect.Struct {
+		panic("state must be a struct or a pointer to one")
+	}
+	mapType := reflect.TypeOf(map[string]uint64(nil))
+	p, n = v.FieldByName(c.pnFields[0]), v.FieldByName(c.pnFields[1])
+	if !p.IsValid() || p.Type() != mapType || !n.IsValid() || n.Type() != mapType {
+		panic("state must have map[string]uint64 fields " + c.pnFields[0] + " and " + c.pnFields[1])
+	}
+	return p, n
+}
+
+// PNValue returns the value of the PN-counter state: the sum of its P map less the sum of its N map.
+func PNValue(state interface{}, opts ...Option) int64 {
+	p, n := pnMaps(state, opts)
+	var value int64
+	for _, count := range p.Interface().(map[string]uint64) {
+		value += int64(count)
+	}
+	for _, count := range n.Interface().(map[string]uint64) {
+		value -= int64(count)
+	}
+	return value
+}
+
+// PNIncrement adds n to the PN-counter state, a pointer to a struct, on behalf of replica.
+func PNIncrement(state interface{}, replica string, n uint64, opts ...Option) {
+	p, _ := pnMaps(mustPointer(state), opts)
+	addCount(p, replica, n)
+}
+
+// PNDecrement subtracts n from the PN-counter state, a pointer to a struct, on behalf of replica.
+func PNDecrement(state interface{}, replica string, n uint64, opts ...Option) {
+	_, neg := pnMaps(mustPointer(state), opts)
+	addCount(neg, replica, n)
+}
+
+// mustPointer returns state, which must be a pointer.
+func mustPointer(state interface{}) interface{} {
+	if reflect.ValueOf(state).Kind() != reflect.Ptr {
+		panic("state must be a pointer")
+	}
+	return state
+}
+
+// addCount adds n to replica's entry in the map[string]uint64 m, creating the map if it is nil.
+func addCount(m reflect.Value, replica string, n uint64) {
+	if m.IsNil() {
+		m.Set(reflect.ValueOf(map[string]uint64{}))
+	}
+	counts := m.Interface().(map[string]uint64)
+	counts[replica] += n
+}
@@ -0,0 +1,45 @@
+package crdt
+
+import "testing"
+
+func TestPN(t *testing.T) {
+	type counter struct {
+		P, N map[string]uint64
+	}
+	var a, b counter
+	PNIncrement(&a, "a", 5)
+	PNDecrement(&a, "a", 2)
+	PNIncrement(&b, "b", 1)
+	PNDecrement(&b, "b", 3)
+	if value := PNValue(a); value != 3 {
+		t.Errorf("PNValue(a) = %d, expected 3", value)
+	}
+	if value := PNValue(&b); value != -2 {
+		t.Errorf("PNValue(&b) = %d, expected -2", value)
+	}
+	joined := Join(a, b).(counter)
+	if value := PNValue(joined); value != 1 {
+		t.Errorf("PNValue(Join(a, b)) = %d, expected 1", value)
+	}
+	// Merging a's state again doesn't count its increments twice.
+	Merge(&joined, a)
+	if value := PNValue(joined); value != 1 {
+		t.Errorf("PNValue after merging a again = %d, expected 1", value)
+	}
+}
+
+func TestWithPNFields(t *testing.T) {
+	type legacy struct {
+		Incs, Decs map[string]uint64
+	}
+	var state legacy
+	fields := WithPNFields("Incs", "Decs")
+	PNIncrement(&state, "a", 4, fields)
+	PNDecrement(&state, "b", 1, fields)
+	if value := PNValue(state, fields); value != 3 {
+		t.Errorf("PNValue = %d, expected 3", value)
+	}
+	if state.Incs["a"] != 4 || state.Decs["b"] != 1 {
+		t.Errorf("state = %#v", state)
+	}
+}
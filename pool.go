@@ -0,0 +1,77 @@
+package crdt
+
+import (
+	"reflect"
+	"sync"
+)
+
+// joinPools holds a *sync.Pool of result buffers for each type passed to JoinPooled.
+// Each pool hands out pointers to values of its type.
+var joinPools sync.Map // map[reflect.Type]*sync.Pool
+
+// joinPool returns the result buffer pool for values of type t.
+func joinPool(t reflect.Type) *sync.Pool {
+	if pool, ok := joinPools.Load(t); ok {
+		return pool.(*sync.Pool)
+	}
+	pool, _ := joinPools.LoadOrStore(t, &sync.Pool{
+		New: func() interface{} { return reflect.New(t).Interface() },
+	})
+	return pool.(*sync.Pool)
+}
+
+// reset returns v to an empty state so that it can be reused as a join result.
+// Maps are cleared in place rather than discarded, so that their storage can be reused.
+// Structs with unexported fields, such as time.Time, can't be reset fieldwise, so they are zeroed whole.
+func reset(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Map:
+		if !v.IsNil() {
+			v.Clear()
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				v.Set(reflect.Zero(v.Type()))
+				return
+			}
+		}
+		for i := 0; i < v.NumField(); i++ {
+			reset(v.Field(i))
+		}
+	default:
+		v.Set(reflect.Zero(v.Type()))
+	}
+}
+
+// JoinPooled returns the least upper bound of (a, b), like Join, but draws the storage
+// for the result from a pool of buffers kept per type.
+// Both a and b must be mergeable values of the same type.
+//
+// The caller must call release once it is done with result, after which result
+// (and any map reachable from it) must no longer be used.
+// Because map storage is reused, an empty map in result may be non-nil where Join would return nil.
+func JoinPooled(a, b interface{}) (result interface{}, release func()) {
+	var pool *sync.Pool
+	buf := joinBuffered(a, b, func(t reflect.Type) reflect.Value {
+		pool = joinPool(t)
+		return reflect.ValueOf(pool.Get())
+	})
+	return buf.Elem().Interface(), func() {
+		reset(buf.Elem())
+		pool.Put(buf.Interface())
+	}
+}
+
+// joinBuffered joins a and b, like Join, into the buffer that get returns for their type,
+// a pointer to an empty value, and returns the buffer. It is shared by JoinPooled and JoinArena.
+func joinBuffered(a, b interface{}, get func(t reflect.Type) reflect.Value) reflect.Value {
+	aVal := reflect.ValueOf(a)
+	bVal := reflect.ValueOf(b)
+	if aVal.Type() != bVal.Type() {
+		panic("a and b must be the same type")
+	}
+	buf := get(aVal.Type())
+	joinInto(buf.Elem(), aVal, bVal)
+	return buf
+}
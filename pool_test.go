@@ -0,0 +1,84 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJoinPooled(t *testing.T) {
+	type A struct {
+		I int
+		M map[string]int
+	}
+	testJoin := func(a, b, expected A) {
+		result, release := JoinPooled(a, b)
+		defer release()
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("JoinPooled(%#v, %#v) = %#v, expected %#v", a, b, result, expected)
+		}
+	}
+	testJoin(A{1, map[string]int{"a": 1}}, A{2, map[string]int{"b": 2}}, A{2, map[string]int{"a": 1, "b": 2}})
+	testJoin(A{1, map[string]int{"a": 3}}, A{0, map[string]int{"a": 2}}, A{1, map[string]int{"a": 3}})
+}
+
+func TestJoinPooledTime(t *testing.T) {
+	type A struct {
+		When time.Time
+		M    map[string]int
+	}
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		result, release := JoinPooled(A{When: when}, A{M: map[string]int{"a": 1}})
+		if expected := (A{when, map[string]int{"a": 1}}); !reflect.DeepEqual(result, expected) {
+			t.Fatalf("JoinPooled = %#v, expected %#v", result, expected)
+		}
+		release()
+	}
+}
+
+func TestJoinPooledReuse(t *testing.T) {
+	type A map[int]int
+	result, release := JoinPooled(A{1: 5, 2: 5}, A{3: 5})
+	if expected := (A{1: 5, 2: 5, 3: 5}); !reflect.DeepEqual(result, expected) {
+		t.Fatalf("JoinPooled = %#v, expected %#v", result, expected)
+	}
+	release()
+	// Join repeatedly so that the released buffer is drawn from the pool again.
+	for i := 0; i < 10; i++ {
+		result, release = JoinPooled(A{4: 1}, A{})
+		if expected := (A{4: 1}); !reflect.DeepEqual(result, expected) {
+			t.Fatalf("JoinPooled after release = %#v, expected %#v", result, expected)
+		}
+		release()
+	}
+}
+
+func benchmarkMap(n int) map[int]int {
+	m := make(map[int]int, n)
+	for i := 0; i < n; i++ {
+		m[i] = i
+	}
+	return m
+}
+
+func BenchmarkJoin(b *testing.B) {
+	x, y := benchmarkMap(64), benchmarkMap(64)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			Join(x, y)
+		}
+	})
+}
+
+func BenchmarkJoinPooled(b *testing.B) {
+	x, y := benchmarkMap(64), benchmarkMap(64)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, release := JoinPooled(x, y)
+			release()
+		}
+	})
+}
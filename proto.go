@@ -0,0 +1,166 @@
+//go:build crdtproto
+
+// Protobuf support depends on google.golang.org/protobuf, so it is only built with the crdtproto build tag.
+
+package crdt
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RegisterProto registers a merge function for pointers to the protobuf message type of m,
+// such as a generated *pb.Message, which merges messages using the protobuf reflection API:
+//   - Scalar fields are set to the greater of (a, b), where bytes compare lexicographically and enums by number.
+//   - Message fields are merged recursively.
+//   - Map fields are merged keywise, with scalar and message values merged as above.
+//   - Repeated fields are merged as sets: the result is the sorted, deduplicated union of both sides,
+//     where messages are ordered by their deterministic encoding.
+//   - If a and b set different fields of a oneof, the field with the greater number wins.
+//
+// A nil message is the bottom value, and unset fields are the bottom value of their kind.
+// Only the fields of the type's descriptor are merged; unknown fields are left as they are in a.
+func RegisterProto(m proto.Message) {
+	Register(reflect.TypeOf(m), func(a, b interface{}) bool {
+		ptr := reflect.ValueOf(a).Elem()
+		other := b.(proto.Message)
+		if reflect.ValueOf(other).IsNil() {
+			return false
+		}
+		if ptr.IsNil() {
+			ptr.Set(reflect.ValueOf(proto.Clone(other)))
+			return true
+		}
+		return mergeMessage(ptr.Interface().(proto.Message).ProtoReflect(), other.ProtoReflect())
+	})
+}
+
+// mergeMessage merges the message b into the message a. It returns true if a was modified.
+func mergeMessage(a, b protoreflect.Message) bool {
+	var changed bool
+	b.Range(func(fd protoreflect.FieldDescriptor, bValue protoreflect.Value) bool {
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			if which := a.WhichOneof(oneof); which != nil && which.Number() != fd.Number() {
+				if which.Number() > fd.Number() {
+					return true
+				}
+				a.Clear(which)
+			}
+		}
+		var fieldChanged bool
+		switch {
+		case fd.IsMap():
+			fieldChanged = mergeProtoMap(fd.MapValue(), a.Mutable(fd).Map(), bValue.Map())
+		case fd.IsList():
+			fieldChanged = mergeProtoList(fd, a.Mutable(fd).List(), bValue.List())
+		case fd.Message() != nil:
+			fieldChanged = !a.Has(fd)
+			if mergeMessage(a.Mutable(fd).Message(), bValue.Message()) {
+				fieldChanged = true
+			}
+		default:
+			if !a.Has(fd) || protoLess(fd, a.Get(fd), bValue) {
+				a.Set(fd, bValue)
+				fieldChanged = true
+			}
+		}
+		if fieldChanged {
+			changed = true
+		}
+		return true
+	})
+	return changed
+}
+
+// mergeProtoMap merges the map b into the map a, whose values are described by fd.
+// It returns true if a was modified.
+func mergeProtoMap(fd protoreflect.FieldDescriptor, a, b protoreflect.Map) bool {
+	var changed bool
+	b.Range(func(key protoreflect.MapKey, bValue protoreflect.Value) bool {
+		if fd.Message() != nil {
+			if !a.Has(key) {
+				changed = true
+			}
+			if mergeMessage(a.Mutable(key).Message(), bValue.Message()) {
+				changed = true
+			}
+		} else if !a.Has(key) || protoLess(fd, a.Get(key), bValue) {
+			a.Set(key, bValue)
+			changed = true
+		}
+		return true
+	})
+	return changed
+}
+
+// mergeProtoList sets the list a to the sorted, deduplicated union of the lists a and b,
+// whose elements are described by fd. It returns true if a was modified.
+func mergeProtoList(fd protoreflect.FieldDescriptor, a, b protoreflect.List) bool {
+	union := make([]protoreflect.Value, 0, a.Len()+b.Len())
+	for i := 0; i < a.Len(); i++ {
+		union = append(union, a.Get(i))
+	}
+	for i := 0; i < b.Len(); i++ {
+		value := b.Get(i)
+		if fd.Message() != nil {
+			value = protoreflect.ValueOfMessage(proto.Clone(value.Message().Interface()).ProtoReflect())
+		}
+		union = append(union, value)
+	}
+	sort.SliceStable(union, func(i, j int) bool {
+		return protoLess(fd, union[i], union[j])
+	})
+	deduped := union[:0]
+	for i, value := range union {
+		if i == 0 || protoLess(fd, deduped[len(deduped)-1], value) {
+			deduped = append(deduped, value)
+		}
+	}
+	changed := len(deduped) != a.Len()
+	for i := 0; i < a.Len() && !changed; i++ {
+		changed = protoLess(fd, a.Get(i), deduped[i]) || protoLess(fd, deduped[i], a.Get(i))
+	}
+	if changed {
+		a.Truncate(0)
+		for _, value := range deduped {
+			a.Append(value)
+		}
+	}
+	return changed
+}
+
+// protoLess returns true if a < b, where a and b are values of the field described by fd.
+func protoLess(fd protoreflect.FieldDescriptor, a, b protoreflect.Value) bool {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return !a.Bool() && b.Bool()
+	case protoreflect.EnumKind:
+		return a.Enum() < b.Enum()
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return a.Int() < b.Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return a.Uint() < b.Uint()
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return a.Float() < b.Float()
+	case protoreflect.StringKind:
+		return a.String() < b.String()
+	case protoreflect.BytesKind:
+		return bytes.Compare(a.Bytes(), b.Bytes()) < 0
+	default:
+		return bytes.Compare(protoBytes(a.Message()), protoBytes(b.Message())) < 0
+	}
+}
+
+// protoBytes returns the deterministic encoding of the message m, by which messages are ordered.
+func protoBytes(m protoreflect.Message) []byte {
+	encoded, err := proto.MarshalOptions{Deterministic: true}.Marshal(m.Interface())
+	if err != nil {
+		panic(mergeErrorf("marshaling %s: %w", m.Descriptor().FullName(), err))
+	}
+	return encoded
+}
@@ -0,0 +1,159 @@
+//go:build crdtproto
+
+package crdt
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// docDescriptor describes a small message type, as if generated from:
+//
+//	message Doc {
+//	  int64 version = 1;
+//	  string title = 2;
+//	  map<string, int64> counts = 3;
+//	  repeated string tags = 4;
+//	  Doc child = 5;
+//	  oneof choice {
+//	    string name = 6;
+//	    int64 id = 7;
+//	  }
+//	}
+var docDescriptor = func() protoreflect.MessageDescriptor {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+		}
+	}
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	counts := field("counts", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated)
+	counts.TypeName = proto.String(".crdttest.Doc.CountsEntry")
+	child := field("child", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional)
+	child.TypeName = proto.String(".crdttest.Doc")
+	name := field("name", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional)
+	name.OneofIndex = proto.Int32(0)
+	id := field("id", 7, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional)
+	id.OneofIndex = proto.Int32(0)
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("crdttest/doc.proto"),
+		Package: proto.String("crdttest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Doc"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("version", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional),
+				field("title", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+				counts,
+				field("tags", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, repeated),
+				child,
+				name,
+				id,
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("CountsEntry"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+					field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional),
+				},
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+			OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("choice")}},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		panic(err)
+	}
+	return fd.Messages().Get(0)
+}()
+
+func init() {
+	RegisterProto(dynamicpb.NewMessage(docDescriptor))
+}
+
+type doc struct {
+	version int64
+	title   string
+	counts  map[string]int64
+	tags    []string
+	child   *doc
+	name    string
+	id      int64
+}
+
+// newDoc returns a Doc message with the fields of d.
+func newDoc(d doc) *dynamicpb.Message {
+	m := dynamicpb.NewMessage(docDescriptor)
+	fields := docDescriptor.Fields()
+	if d.version != 0 {
+		m.Set(fields.ByName("version"), protoreflect.ValueOfInt64(d.version))
+	}
+	if d.title != "" {
+		m.Set(fields.ByName("title"), protoreflect.ValueOfString(d.title))
+	}
+	for key, value := range d.counts {
+		m.Mutable(fields.ByName("counts")).Map().Set(protoreflect.ValueOfString(key).MapKey(), protoreflect.ValueOfInt64(value))
+	}
+	for _, tag := range d.tags {
+		m.Mutable(fields.ByName("tags")).List().Append(protoreflect.ValueOfString(tag))
+	}
+	if d.child != nil {
+		m.Set(fields.ByName("child"), protoreflect.ValueOfMessage(newDoc(*d.child)))
+	}
+	if d.name != "" {
+		m.Set(fields.ByName("name"), protoreflect.ValueOfString(d.name))
+	}
+	if d.id != 0 {
+		m.Set(fields.ByName("id"), protoreflect.ValueOfInt64(d.id))
+	}
+	return m
+}
+
+type protoHolder struct {
+	Doc *dynamicpb.Message
+}
+
+func TestRegisterProto(t *testing.T) {
+	a := newDoc(doc{
+		version: 2, title: "a", counts: map[string]int64{"x": 1, "y": 5}, tags: []string{"red"},
+		child: &doc{version: 1}, name: "alice",
+	})
+	b := newDoc(doc{
+		version: 1, title: "b", counts: map[string]int64{"x": 3, "z": 1}, tags: []string{"blue", "red"},
+		child: &doc{version: 3, title: "c"}, id: 7,
+	})
+	expected := newDoc(doc{
+		version: 2, title: "b", counts: map[string]int64{"x": 3, "y": 5, "z": 1}, tags: []string{"blue", "red"},
+		child: &doc{version: 3, title: "c"}, id: 7,
+	})
+	ab := Join(protoHolder{a}, protoHolder{b}).(protoHolder)
+	ba := Join(protoHolder{b}, protoHolder{a}).(protoHolder)
+	if !proto.Equal(ab.Doc, expected) || !proto.Equal(ba.Doc, expected) {
+		t.Errorf("Join(a, b) = %v, Join(b, a) = %v, expected %v", ab.Doc, ba.Doc, expected)
+	}
+	if ab.Doc == a || ab.Doc == b {
+		t.Errorf("Join(a, b) shares a message with its inputs")
+	}
+
+	holder := protoHolder{a}
+	if !Merge(&holder, protoHolder{b}) || !proto.Equal(holder.Doc, expected) {
+		t.Errorf("Merge(a, b) = %v, expected %v", holder.Doc, expected)
+	}
+	if Merge(&holder, protoHolder{b}) {
+		t.Errorf("merging b again reported a change")
+	}
+	if Merge(&holder, protoHolder{}) {
+		t.Errorf("merging a nil message reported a change")
+	}
+}
@@ -0,0 +1,69 @@
+package crdt
+
+import "reflect"
+
+// A struct with a field tagged `crdt:"primary"` is merged as a single record rather than fieldwise:
+// the whole struct is taken from the side whose primary field is greater, so that fields that belong
+// together, like a best score and when it was achieved, are never mixed from different sides.
+// Ties are broken by the fields tagged `crdt:"tiebreak"`, in order: the side whose tiebreak field
+// is greater wins, or, with `crdt:"tiebreak=min"`, the side whose tiebreak field is less, such as the
+// earliest timestamp. If the sides are still tied, they are merged fieldwise as usual.
+// The zero struct is the bottom value.
+
+// recordField is a field that decides which side of a record wins.
+type recordField struct {
+	index int
+	// min is true if the lesser value of the field wins.
+	min bool
+}
+
+// recordFields returns the fields of the struct type t that decide which side wins, primary first,
+// or nil if t has no primary field.
+func recordFields(t reflect.Type) []recordField {
+	var primary []recordField
+	var tiebreaks []recordField
+	for i, tag := range fieldTags(t) {
+		if tag.has("primary") {
+			primary = append(primary, recordField{index: i})
+		} else if order, ok := tag["tiebreak"]; ok {
+			tiebreaks = append(tiebreaks, recordField{index: i, min: order == "min"})
+		}
+	}
+	if primary == nil {
+		return nil
+	}
+	return append(primary, tiebreaks...)
+}
+
+// mergeRecord merges the struct b into the struct a as a single record, decided by fields.
+// It returns true if the value of a was modified.
+func (m *merger) mergeRecord(a, b reflect.Value, fields []recordField) bool {
+	if isZero(b) {
+		m.decide(false, a)
+		return false
+	}
+	order := 0
+	if isZero(a) {
+		order = -1
+	}
+	for _, field := range fields {
+		if order != 0 {
+			break
+		}
+		order = compare(a.Field(field.index), b.Field(field.index))
+		if field.min {
+			order = -order
+		}
+	}
+	switch {
+	case order < 0:
+		a.Set(deepCopy(b))
+		m.decide(true, a)
+		return true
+	case order > 0:
+		m.decide(false, a)
+		return false
+	default:
+		return m.mergeFields(a, b)
+	}
+}
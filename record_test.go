@@ -0,0 +1,41 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type bestScore struct {
+	Score  int   `crdt:"primary"`
+	At     int64 `crdt:"tiebreak=min"`
+	Replay string
+}
+
+func TestMergeRecord(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		a, b     bestScore
+		expected bestScore
+	}{
+		{"higher score wins", bestScore{10, 5, "a"}, bestScore{12, 9, "b"}, bestScore{12, 9, "b"}},
+		{"tie keeps earliest", bestScore{10, 5, "a"}, bestScore{10, 3, "b"}, bestScore{10, 3, "b"}},
+		{"tie keeps earliest record whole", bestScore{10, 3, "zzz"}, bestScore{10, 5, "aaa"}, bestScore{10, 3, "zzz"}},
+		{"zero is bottom", bestScore{}, bestScore{1, 9, "b"}, bestScore{1, 9, "b"}},
+		{"full tie merges fieldwise", bestScore{10, 3, "a"}, bestScore{10, 3, "b"}, bestScore{10, 3, "b"}},
+	} {
+		for _, pair := range [][2]bestScore{{test.a, test.b}, {test.b, test.a}} {
+			if joined := Join(pair[0], pair[1]); !reflect.DeepEqual(joined, test.expected) {
+				t.Errorf("%s: Join(%v, %v) = %v, expected %v", test.name, pair[0], pair[1], joined, test.expected)
+			}
+		}
+	}
+}
+
+func TestMergeRecordMap(t *testing.T) {
+	a := map[string]bestScore{"alice": {10, 5, "a1"}, "bob": {7, 1, "b1"}}
+	b := map[string]bestScore{"alice": {10, 2, "a2"}, "bob": {6, 0, "b2"}}
+	expected := map[string]bestScore{"alice": {10, 2, "a2"}, "bob": {7, 1, "b1"}}
+	if !Merge(&a, b) || !reflect.DeepEqual(a, expected) {
+		t.Errorf("Merge gave %v, expected %v", a, expected)
+	}
+}
@@ -0,0 +1,194 @@
+package crdt
+
+import (
+	"cmp"
+	"reflect"
+)
+
+// MaxRegister is a register that keeps the greatest value it has been set to.
+// Unlike a bare ordered value, it distinguishes being unset from holding T's zero value,
+// so it works for negative numbers too. The zero value is an unset register.
+type MaxRegister[T cmp.Ordered] struct {
+	Max   T
+	Valid bool
+}
+
+// Set sets the register to v, if v is greater than its current value.
+func (r *MaxRegister[T]) Set(v T) {
+	r.Merge(MaxRegister[T]{v, true})
+}
+
+// Value returns the greatest value the register has been set to,
+// or T's zero value if it hasn't been set.
+func (r *MaxRegister[T]) Value() T {
+	return r.Max
+}
+
+// Merge merges another MaxRegister of the same type into this one.
+func (r *MaxRegister[T]) Merge(other interface{}) bool {
+	o := other.(MaxRegister[T])
+	if !o.Valid || (r.Valid && !cmp.Less(r.Max, o.Max)) {
+		return false
+	}
+	*r = o
+	return true
+}
+
+// MinRegister is a register that keeps the least value it has been set to.
+// The zero value is an unset register.
+type MinRegister[T cmp.Ordered] struct {
+	Min   T
+	Valid bool
+}
+
+// Set sets the register to v, if v is less than its current value.
+func (r *MinRegister[T]) Set(v T) {
+	r.Merge(MinRegister[T]{v, true})
+}
+
+// Value returns the least value the register has been set to,
+// or T's zero value if it hasn't been set.
+func (r *MinRegister[T]) Value() T {
+	return r.Min
+}
+
+// Merge merges another MinRegister of the same type into this one.
+func (r *MinRegister[T]) Merge(other interface{}) bool {
+	o := other.(MinRegister[T])
+	if !o.Valid || (r.Valid && !cmp.Less(o.Min, r.Min)) {
+		return false
+	}
+	*r = o
+	return true
+}
+
+// LWWRegister is a last-writer-wins register: it holds the value written with the greatest timestamp,
+// with ties between replicas broken by replica ID. A replica must not write two different values
+// with the same timestamp. The zero value is an unwritten register.
+type LWWRegister[T any] struct {
+	Value     T
+	Timestamp int64
+	Replica   string
+}
+
+// Set writes value to the register on behalf of replica, at the given timestamp.
+// The write is ignored if the register already holds a later write.
+func (r *LWWRegister[T]) Set(value T, timestamp int64, replica string) {
+	r.Merge(LWWRegister[T]{value, timestamp, replica})
+}
+
+// Get returns the value of the register.
+func (r *LWWRegister[T]) Get() T {
+	return r.Value
+}
+
+// Replicas returns the ID of the replica that wrote the register's value, if any.
+func (r *LWWRegister[T]) Replicas() []string {
+	return []string{r.Replica}
+}
+
+// Merge merges another LWWRegister of the same type into this one.
+// The winning value is deep-copied, so the registers don't share storage.
+func (r *LWWRegister[T]) Merge(other interface{}) bool {
+	o := other.(LWWRegister[T])
+	if o.Timestamp < r.Timestamp || (o.Timestamp == r.Timestamp && o.Replica <= r.Replica) {
+		return false
+	}
+	r.Value = deepCopy(reflect.ValueOf(&o.Value).Elem()).Interface().(T)
+	r.Timestamp = o.Timestamp
+	r.Replica = o.Replica
+	return true
+}
+
+// MaxByRegister is a register that keeps the value with the greatest key, as extracted by a key function,
+// such as the Event with the largest Seq. Values with equal keys are ordered by a tiebreak comparison,
+// which must be a total order on them for merges to converge.
+// Registers must be created by NewMaxByRegister; the zero value, as Join starts from,
+// is an unset register that adopts the functions of the first register merged into it.
+type MaxByRegister[T any, K cmp.Ordered] struct {
+	Value T
+	Valid bool
+
+	key      func(T) K
+	tiebreak func(a, b T) int
+}
+
+// NewMaxByRegister returns an unset MaxByRegister ordering values by key, then by tiebreak,
+// which returns a negative number if a < b, a positive number if a > b, and zero if they are equal.
+func NewMaxByRegister[T any, K cmp.Ordered](key func(T) K, tiebreak func(a, b T) int) *MaxByRegister[T, K] {
+	return &MaxByRegister[T, K]{key: key, tiebreak: tiebreak}
+}
+
+// Set sets the register to v, if v is greater than its current value.
+func (r *MaxByRegister[T, K]) Set(v T) {
+	r.Merge(MaxByRegister[T, K]{v, true, r.key, r.tiebreak})
+}
+
+// Get returns the greatest value the register has been set to, or T's zero value if it hasn't been set.
+func (r *MaxByRegister[T, K]) Get() T {
+	return r.Value
+}
+
+// Merge merges another MaxByRegister of the same type into this one.
+// The winning value is deep-copied, so the registers don't share storage.
+func (r *MaxByRegister[T, K]) Merge(other interface{}) bool {
+	o := other.(MaxByRegister[T, K])
+	if r.key == nil {
+		r.key, r.tiebreak = o.key, o.tiebreak
+	}
+	if !o.Valid {
+		return false
+	}
+	if r.Valid {
+		c := cmp.Compare(r.key(r.Value), r.key(o.Value))
+		if c == 0 {
+			c = r.tiebreak(r.Value, o.Value)
+		}
+		if c >= 0 {
+			return false
+		}
+	}
+	r.Value = deepCopy(reflect.ValueOf(&o.Value).Elem()).Interface().(T)
+	r.Valid = true
+	return true
+}
+
+// Observed is a last-writer-wins cell for use as a struct field: it holds the value written with
+// the greatest version, with ties between replicas broken by replica ID. Unlike LWWRegister,
+// versions are counters rather than timestamps: each write is numbered one past the version it replaces,
+// so a write always wins over the writes its replica had observed. The zero value is an unwritten cell.
+type Observed[T any] struct {
+	Value   T
+	Version uint64
+	Replica string
+}
+
+// Set writes value to the cell on behalf of replica, with a version one greater than the cell's.
+func (o *Observed[T]) Set(value T, replica string) {
+	o.Value = value
+	o.Version++
+	o.Replica = replica
+}
+
+// Get returns the value of the cell.
+func (o *Observed[T]) Get() T {
+	return o.Value
+}
+
+// Replicas returns the ID of the replica that wrote the cell's value, if any.
+func (o *Observed[T]) Replicas() []string {
+	return []string{o.Replica}
+}
+
+// Merge merges another Observed of the same type into this one.
+// The winning value is deep-copied, so the cells don't share storage.
+func (o *Observed[T]) Merge(other interface{}) bool {
+	b := other.(Observed[T])
+	if b.Version < o.Version || (b.Version == o.Version && b.Replica <= o.Replica) {
+		return false
+	}
+	o.Value = deepCopy(reflect.ValueOf(&b.Value).Elem()).Interface().(T)
+	o.Version = b.Version
+	o.Replica = b.Replica
+	return true
+}
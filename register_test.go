@@ -0,0 +1,174 @@
+package crdt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMaxRegister(t *testing.T) {
+	var r MaxRegister[int]
+	r.Set(-3)
+	if r.Value() != -3 {
+		t.Errorf("Value() = %d, expected -3", r.Value())
+	}
+	r.Set(-5)
+	r.Set(2)
+	r.Set(1)
+	if r.Value() != 2 {
+		t.Errorf("Value() = %d, expected 2", r.Value())
+	}
+}
+
+func TestMinRegister(t *testing.T) {
+	var r MinRegister[string]
+	r.Set("m")
+	r.Set("z")
+	r.Set("b")
+	r.Set("c")
+	if r.Value() != "b" {
+		t.Errorf("Value() = %q, expected %q", r.Value(), "b")
+	}
+}
+
+func TestRegisterConvergence(t *testing.T) {
+	type A struct {
+		High MaxRegister[int]
+		Low  MinRegister[int]
+	}
+	values := []int{4, -2, 7, 0, 3}
+	states := make([]A, len(values))
+	for i, v := range values {
+		states[i].High.Set(v)
+		states[i].Low.Set(v)
+	}
+	expected := A{MaxRegister[int]{7, true}, MinRegister[int]{-2, true}}
+	// Merge the states in every rotation of their order.
+	for start := range states {
+		var value A
+		for i := range states {
+			Merge(&value, states[(start+i)%len(states)])
+		}
+		if value != expected {
+			t.Errorf("merging from state %d gave %#v, expected %#v", start, value, expected)
+		}
+	}
+	// Merging in reverse order converges too.
+	var value A
+	for i := len(states) - 1; i >= 0; i-- {
+		Merge(&value, states[i])
+	}
+	if value != expected {
+		t.Errorf("merging in reverse gave %#v, expected %#v", value, expected)
+	}
+}
+
+func TestLWWRegister(t *testing.T) {
+	var r LWWRegister[string]
+	r.Set("a", 2, "r1")
+	r.Set("b", 1, "r2")
+	if r.Get() != "a" {
+		t.Errorf("Get() = %q, expected %q", r.Get(), "a")
+	}
+	r.Set("c", 2, "r2")
+	if r.Get() != "c" {
+		t.Errorf("Get() after tied write from greater replica = %q, expected %q", r.Get(), "c")
+	}
+}
+
+func TestMapOfLWWRegisters(t *testing.T) {
+	type A map[string]*LWWRegister[int]
+	source := A{"x": {1, 1, "r1"}, "y": {2, 1, "r1"}}
+	value := A{"x": {5, 2, "r2"}}
+	if !Merge(&value, source) {
+		t.Errorf("Merge(a, b) = false, expected true")
+	}
+	if value["x"].Get() != 5 || value["y"].Get() != 2 {
+		t.Errorf("After merge was x=%d y=%d, expected x=5 y=2", value["x"].Get(), value["y"].Get())
+	}
+	if value["y"] == source["y"] {
+		t.Errorf("merge shared the source's register")
+	}
+	// Mutating the source's registers doesn't affect the merged map.
+	source["y"].Set(10, 5, "r1")
+	source["x"].Set(10, 5, "r1")
+	if value["x"].Get() != 5 || value["y"].Get() != 2 {
+		t.Errorf("After mutating source was x=%d y=%d, expected x=5 y=2", value["x"].Get(), value["y"].Get())
+	}
+	if !Merge(&value, source) || value["x"].Get() != 10 || value["y"].Get() != 10 {
+		t.Errorf("After merging mutated source was x=%d y=%d, expected x=10 y=10", value["x"].Get(), value["y"].Get())
+	}
+}
+
+func TestMaxByRegister(t *testing.T) {
+	type event struct {
+		Seq  int
+		Name string
+	}
+	bySeq := func() *MaxByRegister[event, int] {
+		return NewMaxByRegister(func(e event) int { return e.Seq }, func(a, b event) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+	a, b := bySeq(), bySeq()
+	a.Set(event{3, "created"})
+	a.Set(event{1, "stale"})
+	b.Set(event{5, "deleted"})
+	for _, joined := range []MaxByRegister[event, int]{
+		Join(*a, *b).(MaxByRegister[event, int]),
+		Join(*b, *a).(MaxByRegister[event, int]),
+	} {
+		if got := joined.Get(); got != (event{5, "deleted"}) {
+			t.Errorf("joined register holds %v, expected {5 deleted}", got)
+		}
+	}
+
+	// Equal keys are broken by the tiebreak, in either order.
+	x, y := bySeq(), bySeq()
+	x.Set(event{7, "a"})
+	y.Set(event{7, "b"})
+	xy := Join(*x, *y).(MaxByRegister[event, int])
+	yx := Join(*y, *x).(MaxByRegister[event, int])
+	if xy.Get() != (event{7, "b"}) || yx.Get() != (event{7, "b"}) {
+		t.Errorf("tied registers joined to %v and %v, expected {7 b}", xy.Get(), yx.Get())
+	}
+	if Merge(x, *y); x.Merge(*y) {
+		t.Errorf("merging the same register again reported a change")
+	}
+}
+
+func TestObserved(t *testing.T) {
+	type profile struct {
+		Name  Observed[string]
+		Email Observed[string]
+		Tags  Observed[[]string]
+		Score int
+	}
+	var base profile
+	base.Name.Set("alice", "a")
+	base.Tags.Set([]string{"x"}, "a")
+
+	a, b := Clone(base).(profile), Clone(base).(profile)
+	a.Name.Set("Alice", "a")
+	a.Name.Set("Alice L.", "a")
+	a.Email.Set("a@example.com", "a")
+	b.Name.Set("Alicia", "b")
+	b.Email.Set("alice@example.com", "b")
+	b.Tags.Set([]string{"y"}, "b")
+	b.Score = 3
+
+	expected := profile{
+		Name:  Observed[string]{"Alice L.", 3, "a"},
+		Email: Observed[string]{"alice@example.com", 1, "b"},
+		Tags:  Observed[[]string]{[]string{"y"}, 2, "b"},
+		Score: 3,
+	}
+	ab, ba := JoinT(a, b), JoinT(b, a)
+	if !reflect.DeepEqual(ab, expected) || !reflect.DeepEqual(ba, expected) {
+		t.Errorf("Join(a, b) = %+v and Join(b, a) = %+v, expected %+v", ab, ba, expected)
+	}
+	ab.Tags.Value[0] = "z"
+	if b.Tags.Value[0] != "y" {
+		t.Errorf("the joined cell shares storage with b")
+	}
+}
@@ -0,0 +1,76 @@
+package crdt
+
+import (
+	"reflect"
+	"sync"
+)
+
+// MergeFunc merges b into the value pointed to by a, in place, and returns true if it was modified.
+// a is a pointer to a value of the type the MergeFunc was registered for, and b is a value of that type.
+type MergeFunc func(a, b interface{}) bool
+
+// valueMergeFunc is the form in which merge functions are registered internally.
+// It merges b into a, which is addressable, and returns true if a was modified.
+type valueMergeFunc func(a, b reflect.Value) bool
+
+// registry holds the registered merge functions, keyed by type.
+var registry struct {
+	sync.RWMutex
+	funcs map[reflect.Type]valueMergeFunc
+}
+
+// Register arranges for values of type t to be merged by calling fn.
+// This is useful for types that can't implement Merger themselves,
+// such as types from other packages. Registering a type again replaces its merge function.
+// A type implementing Merger is always merged via its Merge method.
+func Register(t reflect.Type, fn MergeFunc) {
+	registerValue(t, func(a, b reflect.Value) bool {
+		return fn(a.Addr().Interface(), b.Interface())
+	})
+}
+
+// registerValue registers fn as the merge function for values of type t.
+// Unlike Register, fn is given b as a reflect.Value, so that it can read b in place when b is addressable.
+func registerValue(t reflect.Type, fn valueMergeFunc) {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.funcs == nil {
+		registry.funcs = make(map[reflect.Type]valueMergeFunc)
+	}
+	registry.funcs[t] = fn
+	// Struct types classified as flat may contain a field of type t.
+	flatTypes.Range(func(key, _ interface{}) bool {
+		flatTypes.Delete(key)
+		return true
+	})
+	delegatingTypes.Range(func(key, _ interface{}) bool {
+		delegatingTypes.Delete(key)
+		return true
+	})
+	// Compiled merges may have inlined a field of type t.
+	compiled.Range(func(key, _ interface{}) bool {
+		compiled.Delete(key)
+		return true
+	})
+	compileGeneration.Add(1)
+}
+
+// RegisterCompare registers a merge function for T that orders values by T's Compare method,
+// keeping the greater of (a, b). T's zero value must compare less than any other value.
+func RegisterCompare[T interface{ Compare(T) int }]() {
+	Register(reflect.TypeOf((*T)(nil)).Elem(), func(a, b interface{}) bool {
+		value, other := a.(*T), b.(T)
+		if other.Compare(*value) > 0 {
+			*value = other
+			return true
+		}
+		return false
+	})
+}
+
+// registered returns the merge function registered for t, or nil if there is none.
+func registered(t reflect.Type) valueMergeFunc {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.funcs[t]
+}
@@ -0,0 +1,28 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type opaque struct {
+	n int
+}
+
+func TestRegister(t *testing.T) {
+	Register(reflect.TypeOf(opaque{}), func(a, b interface{}) bool {
+		value, other := a.(*opaque), b.(opaque)
+		if other.n > value.n {
+			*value = other
+			return true
+		}
+		return false
+	})
+	value := opaque{1}
+	if !Merge(&value, opaque{2}) || value.n != 2 {
+		t.Errorf("After merge was %#v, expected opaque{2}", value)
+	}
+	if Merge(&value, opaque{1}) || value.n != 2 {
+		t.Errorf("After merge was %#v, expected opaque{2}", value)
+	}
+}
@@ -0,0 +1,140 @@
+package crdt
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// A struct field renamed between versions of a type can be tagged `crdt:"was=OldName"`, so that
+// states encoded by older versions, which carry the field under its old name, keep its value when they
+// are decoded to be merged by UnmarshalMergeJSON: a JSON member named OldName is decoded into the field,
+// unless the state also has a member under the field's current name. Member names are matched as
+// encoding/json matches them, ignoring case, and the field's current name is its JSON name.
+
+// renameJSON returns the JSON encoding data of a value of type t with the members named by the was tags
+// of the fields of t, and of the types within it, renamed to the fields' current JSON names.
+// Data that doesn't decode as expected is returned unchanged, to be reported by the decoder.
+func renameJSON(data []byte, t reflect.Type) []byte {
+	if !hasRenames(t, make(map[reflect.Type]bool)) {
+		return data
+	}
+	renamed, err := json.Marshal(renameValue(json.RawMessage(data), t))
+	if err != nil {
+		return data
+	}
+	return renamed
+}
+
+// hasRenames returns true if t, or a type within it, has a field tagged was.
+// seen holds the types already checked, to stop at recursive types.
+func hasRenames(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Struct:
+		tags := fieldTags(t)
+		for i := 0; i < t.NumField(); i++ {
+			if tags[i]["was"] != "" || hasRenames(t.Field(i).Type, seen) {
+				return true
+			}
+		}
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Ptr:
+		return hasRenames(t.Elem(), seen)
+	}
+	return false
+}
+
+// renameValue returns the JSON value raw, of type t, with the renames of renameJSON applied.
+func renameValue(raw json.RawMessage, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		var members map[string]json.RawMessage
+		if json.Unmarshal(raw, &members) != nil || members == nil {
+			return raw
+		}
+		tags := fieldTags(t)
+		result := make(map[string]interface{}, len(members))
+		for name, value := range members {
+			result[name] = value
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := jsonName(field)
+			if name == "" {
+				continue
+			}
+			member := findMember(members, name)
+			key := member
+			if member == "" {
+				old := tags[i]["was"]
+				if old == "" {
+					continue
+				}
+				if member = findMember(members, old); member == "" {
+					continue
+				}
+				delete(result, member)
+				key = name
+			}
+			result[key] = renameValue(members[member], field.Type)
+		}
+		return result
+	case reflect.Map:
+		var entries map[string]json.RawMessage
+		if json.Unmarshal(raw, &entries) != nil || entries == nil {
+			return raw
+		}
+		result := make(map[string]interface{}, len(entries))
+		for key, value := range entries {
+			result[key] = renameValue(value, t.Elem())
+		}
+		return result
+	case reflect.Slice, reflect.Array:
+		var elems []json.RawMessage
+		if json.Unmarshal(raw, &elems) != nil || elems == nil {
+			return raw
+		}
+		result := make([]interface{}, len(elems))
+		for i, elem := range elems {
+			result[i] = renameValue(elem, t.Elem())
+		}
+		return result
+	}
+	return raw
+}
+
+// jsonName returns the name of the JSON member that encoding/json encodes field as,
+// or "" if the field isn't encoded.
+func jsonName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// findMember returns the name of the member of members that encoding/json would decode into
+// a field named name, or "" if there is none.
+func findMember(members map[string]json.RawMessage, name string) string {
+	if _, ok := members[name]; ok {
+		return name
+	}
+	for member := range members {
+		if strings.EqualFold(member, name) {
+			return member
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package crdt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalMergeJSONRenamed(t *testing.T) {
+	type item struct {
+		Total int `json:"total" crdt:"was=Count"`
+	}
+	type state struct {
+		Score int             `crdt:"was=Points"`
+		Tags  []string        `crdt:"set,was=Labels"`
+		Items map[string]item `json:"items"`
+	}
+	old := []byte(`{"points":7,"Labels":["b","a"],"items":{"x":{"count":3},"y":{"total":2}}}`)
+	a := state{Score: 5, Tags: []string{"c"}, Items: map[string]item{"x": {Total: 4}}}
+	changed, err := UnmarshalMergeJSON(old, &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := state{Score: 7, Tags: []string{"a", "b", "c"}, Items: map[string]item{"x": {Total: 4}, "y": {Total: 2}}}
+	if !changed || !reflect.DeepEqual(a, want) {
+		t.Errorf("UnmarshalMergeJSON = %v, %+v; want true, %+v", changed, a, want)
+	}
+
+	// The current name takes precedence over the old one.
+	both := []byte(`{"Points":9,"Score":1}`)
+	var b state
+	if _, err := UnmarshalMergeJSON(both, &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.Score != 1 {
+		t.Errorf("Score = %d, want 1", b.Score)
+	}
+
+	// States encoded with the new name round-trip unchanged.
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c state
+	if _, err := UnmarshalMergeJSON(data, &c); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("round trip = %+v, want %+v", c, want)
+	}
+}
@@ -0,0 +1,173 @@
+package crdt
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ReplicaLister is implemented by CRDTs that record which replicas have contributed to them,
+// such as GCounter, LWWRegister, and TombstoneMap.
+type ReplicaLister interface {
+	// Replicas returns the IDs of the replicas that have contributed to the value, in any order.
+	Replicas() []string
+}
+
+var replicaListerType = reflect.TypeOf((*ReplicaLister)(nil)).Elem()
+
+// Replicas returns the sorted IDs of every replica that has contributed to a, collected from
+// each ReplicaLister within it: a is searched through struct fields, map keys and values,
+// slice elements, pointers, and interfaces, including the fields of the ReplicaListers themselves.
+// This helps find replicas that no longer contribute, so that their entries can be retired.
+func Replicas(a interface{}) []string {
+	ids := make(map[string]bool)
+	if a != nil {
+		collectReplicas(reflect.ValueOf(a), ids, make(map[copiedPointer]bool))
+	}
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// collectReplicas adds the IDs listed by each ReplicaLister within v to ids.
+// seen holds the pointers already searched, to stop at cycles.
+func collectReplicas(v reflect.Value, ids map[string]bool, seen map[copiedPointer]bool) {
+	if reflect.PointerTo(v.Type()).Implements(replicaListerType) {
+		if !v.CanAddr() {
+			v = addressable(v)
+		}
+		for _, id := range v.Addr().Interface().(ReplicaLister).Replicas() {
+			if id != "" {
+				ids[id] = true
+			}
+		}
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				collectReplicas(v.Field(i), ids, seen)
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			collectReplicas(iter.Key(), ids, seen)
+			collectReplicas(iter.Value(), ids, seen)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectReplicas(v.Index(i), ids, seen)
+		}
+	case reflect.Ptr:
+		key := copiedPointer{v.Type(), v.Pointer()}
+		if !v.IsNil() && !seen[key] {
+			seen[key] = true
+			collectReplicas(v.Elem(), ids, seen)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			collectReplicas(v.Elem(), ids, seen)
+		}
+	}
+}
+
+// ReplicaPruner is implemented by CRDTs that keep per-replica entries that can be removed
+// once their replica is retired, such as GCounter.
+type ReplicaPruner interface {
+	// PruneReplica removes replica's entries from the value, and returns true if it had any.
+	PruneReplica(replica string) bool
+}
+
+var replicaPrunerType = reflect.TypeOf((*ReplicaPruner)(nil)).Elem()
+
+// PruneReplica removes the entries of replica from each ReplicaPruner within the value pointed to by a,
+// and from the counts of its fields tagged `crdt:"sum=Counts"`, which are set to their new sums.
+// a is searched as it is by Replicas. It returns true if anything was removed.
+//
+// Pruning is not a merge: it lowers the value, so a state that still has replica's entries
+// will restore them when it is next merged in, and the result will converge only once every
+// replica has pruned them. Only prune a replica that has been permanently retired,
+// once each of its contributions has been counted into a baseline elsewhere if it is still needed,
+// and prune it on every replica, before any of them merges a state that still has its entries.
+func PruneReplica(a interface{}, replica string) bool {
+	v := reflect.ValueOf(a)
+	if v.Kind() != reflect.Ptr {
+		panic("a must be a pointer")
+	}
+	return pruneReplica(v.Elem(), replica, make(map[copiedPointer]bool))
+}
+
+// pruneReplica removes the entries of replica from the addressable value v, and returns true if it had any.
+// seen holds the pointers already searched, to stop at cycles.
+func pruneReplica(v reflect.Value, replica string, seen map[copiedPointer]bool) bool {
+	var pruned bool
+	if reflect.PointerTo(v.Type()).Implements(replicaPrunerType) {
+		pruned = v.Addr().Interface().(ReplicaPruner).PruneReplica(replica)
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		tags := fieldTags(v.Type())
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			if pruneReplica(v.Field(i), replica, seen) {
+				pruned = true
+			}
+			if tags[i].has("sum") && pruneSum(v, i, tags[i], replica) {
+				pruned = true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			if pruneReplica(value, replica, seen) {
+				v.SetMapIndex(iter.Key(), value)
+				pruned = true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if pruneReplica(v.Index(i), replica, seen) {
+				pruned = true
+			}
+		}
+	case reflect.Ptr:
+		key := copiedPointer{v.Type(), v.Pointer()}
+		if !v.IsNil() && !seen[key] {
+			seen[key] = true
+			pruned = pruneReplica(v.Elem(), replica, seen) || pruned
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			value := reflect.New(v.Elem().Type()).Elem()
+			value.Set(v.Elem())
+			if pruneReplica(value, replica, seen) {
+				v.Set(value)
+				pruned = true
+			}
+		}
+	}
+	return pruned
+}
+
+// pruneSum removes replica's entry from the counts of the field of the struct v at index i, which is tagged
+// with the sum options tag, and sets the field to their new sum. It returns true if there was an entry.
+func pruneSum(v reflect.Value, i int, tag tagOptions, replica string) bool {
+	index, err := sumCounts(v.Type(), i, tag)
+	if err != nil {
+		panic("crdt: " + err.Error())
+	}
+	counts, key := v.Field(index), reflect.ValueOf(replica)
+	if !counts.MapIndex(key).IsValid() {
+		return false
+	}
+	counts.SetMapIndex(key, reflect.Value{})
+	v.Field(i).Set(sumOf(counts))
+	return true
+}
@@ -0,0 +1,86 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReplicas(t *testing.T) {
+	type inner struct {
+		Owner LWWRegister[string]
+		Hits  *Counter[uint32]
+	}
+	type state struct {
+		Views   GCounter
+		Members ORSet[string]
+		Title   Observed[string]
+		Pages   map[string]inner
+		Any     interface{}
+		Unset   LWWRegister[int]
+	}
+	var s state
+	s.Views.Increment("a", 1)
+	s.Views.Increment("b", 2)
+	s.Members.Add("c", "x")
+	s.Title.Set("hello", "d")
+	hits := new(Counter[uint32])
+	hits.Increment("e", 1)
+	hits.Increment("a", 1)
+	s.Pages = map[string]inner{"home": {LWWRegister[string]{"f", 1, "f"}, hits}, "about": {Hits: hits}}
+	var any GCounter
+	any.Increment("g", 1)
+	s.Any = any
+
+	expected := []string{"a", "b", "c", "d", "e", "f", "g"}
+	for _, v := range []interface{}{s, &s} {
+		if replicas := Replicas(v); !reflect.DeepEqual(replicas, expected) {
+			t.Errorf("Replicas(%T) = %v, expected %v", v, replicas, expected)
+		}
+	}
+	if replicas := Replicas(state{}); len(replicas) != 0 {
+		t.Errorf("Replicas of the zero state = %v, expected none", replicas)
+	}
+	if replicas := Replicas(nil); len(replicas) != 0 {
+		t.Errorf("Replicas(nil) = %v, expected none", replicas)
+	}
+}
+
+func TestPruneReplica(t *testing.T) {
+	type state struct {
+		Views  GCounter
+		Pages  map[string]*GCounter
+		Hits   []Counter[uint32]
+		Any    interface{}
+		Total  uint64 `crdt:"sum=Counts"`
+		Counts map[string]uint64
+	}
+	var s state
+	s.Views.Increment("old", 1)
+	s.Views.Increment("new", 2)
+	s.Pages = map[string]*GCounter{}
+	IncrementKey(s.Pages, "home", "old", 3)
+	IncrementKey(s.Pages, "home", "new", 4)
+	IncrementKey(s.Pages, "about", "new", 5)
+	s.Hits = make([]Counter[uint32], 2)
+	s.Hits[1].Increment("old", 6)
+	var any GCounter
+	any.Increment("old", 7)
+	s.Any = any
+	AddSum(&s, "Total", "old", 8)
+	AddSum(&s, "Total", "new", 9)
+
+	if !PruneReplica(&s, "old") {
+		t.Errorf("PruneReplica reported no change")
+	}
+	if replicas := Replicas(s); !reflect.DeepEqual(replicas, []string{"new"}) {
+		t.Errorf("after pruning, Replicas = %v, expected [new]", replicas)
+	}
+	pruned := s.Any.(GCounter)
+	if s.Views.Value() != 2 || s.Pages["home"].Value() != 4 || s.Pages["about"].Value() != 5 ||
+		s.Hits[1].Value() != 0 || pruned.Value() != 0 || s.Total != 9 {
+		t.Errorf("after pruning, state is %#v", s)
+	}
+	if PruneReplica(&s, "old") {
+		t.Errorf("pruning a replica again reported a change")
+	}
+}
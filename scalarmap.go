@@ -0,0 +1,65 @@
+package crdt
+
+import "reflect"
+
+// mergeScalarMap merges the map b into the map a, whose values must satisfy isScalar.
+// It is equivalent to mergeMap with no options, but compares values in place
+// rather than copying each one into a new value first. It returns true if the value of a was modified.
+func mergeScalarMap(a, b reflect.Value) bool {
+	if a.IsNil() && !b.IsNil() {
+		a.Set(reflect.MakeMapWithSize(a.Type(), b.Len()))
+	}
+	return mergeScalarEntries(a, b)
+}
+
+// mergeScalarEntries merges the entries of the map b into the non-nil map a, whose values must satisfy isScalar.
+// It returns true if a was modified.
+func mergeScalarEntries(a, b reflect.Value) bool {
+	var changed bool
+	key := reflect.New(b.Type().Key()).Elem()
+	bValue := reflect.New(b.Type().Elem()).Elem()
+	iter := b.MapRange()
+	for iter.Next() {
+		key.SetIterKey(iter)
+		bValue.SetIterValue(iter)
+		aValue := a.MapIndex(key)
+		if !aValue.IsValid() || !isScalarZero(bValue) && (isScalarZero(aValue) || less(aValue, bValue)) {
+			a.SetMapIndex(key, bValue)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// mergeNestedMap merges the map b into the map a, whose values must be maps of values satisfying isScalar.
+// Inner maps already in a are merged in place, and those only in b are copied entry by entry.
+// It returns true if the value of a was modified.
+func mergeNestedMap(a, b reflect.Value) bool {
+	if a.IsNil() && !b.IsNil() {
+		a.Set(reflect.MakeMapWithSize(a.Type(), b.Len()))
+	}
+	var changed bool
+	key := reflect.New(b.Type().Key()).Elem()
+	bInner := reflect.New(b.Type().Elem()).Elem()
+	iter := b.MapRange()
+	for iter.Next() {
+		key.SetIterKey(iter)
+		bInner.SetIterValue(iter)
+		aInner := a.MapIndex(key)
+		switch {
+		case !aInner.IsValid() || aInner.IsNil() && bInner.Len() > 0:
+			inner := reflect.Zero(bInner.Type())
+			if !bInner.IsNil() {
+				inner = reflect.MakeMapWithSize(bInner.Type(), bInner.Len())
+				mergeScalarEntries(inner, bInner)
+			}
+			a.SetMapIndex(key, inner)
+			changed = true
+		default:
+			if mergeScalarEntries(aInner, bInner) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,141 @@
+package crdt
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func randomNestedMap(r *rand.Rand) map[string]map[string]int {
+	if r.Intn(10) == 0 {
+		return nil
+	}
+	m := make(map[string]map[string]int)
+	for i := r.Intn(4); i > 0; i-- {
+		var inner map[string]int
+		if r.Intn(5) > 0 {
+			inner = make(map[string]int)
+			for j := r.Intn(4); j > 0; j-- {
+				inner[string(rune('a'+r.Intn(4)))] = r.Intn(5) - 2
+			}
+		}
+		m[string(rune('a'+r.Intn(4)))] = inner
+	}
+	return m
+}
+
+// TestMergeNestedMap checks that the fast paths for maps of scalars and maps of maps of scalars
+// agree with the generic path, which WithProvenance forces.
+func TestMergeNestedMap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	generic := WithProvenance(func(string, Side) {})
+	for i := 0; i < 1000; i++ {
+		a, b := randomNestedMap(r), randomNestedMap(r)
+		fast, slow := Clone(a).(map[string]map[string]int), Clone(a).(map[string]map[string]int)
+		fastChanged := Merge(&fast, b)
+		slowChanged, _ := MergeWith(&slow, b, generic)
+		if !reflect.DeepEqual(fast, slow) || fastChanged != slowChanged {
+			t.Fatalf("merging %#v into %#v: fast path gave %#v, %v; generic path gave %#v, %v",
+				b, a, fast, fastChanged, slow, slowChanged)
+		}
+		fastInner, slowInner := Clone(a["a"]).(map[string]int), Clone(a["a"]).(map[string]int)
+		fastChanged = Merge(&fastInner, b["a"])
+		slowChanged, _ = MergeWith(&slowInner, b["a"], generic)
+		if !reflect.DeepEqual(fastInner, slowInner) || fastChanged != slowChanged {
+			t.Fatalf("merging %#v into %#v: fast path gave %#v, %v; generic path gave %#v, %v",
+				b["a"], a["a"], fastInner, fastChanged, slowInner, slowChanged)
+		}
+	}
+}
+
+func TestMergeNestedMapNoAliasing(t *testing.T) {
+	a := map[string]map[string]int{}
+	b := map[string]map[string]int{"x": {"y": 1}}
+	Merge(&a, b)
+	a["x"]["y"] = 2
+	if b["x"]["y"] != 1 {
+		t.Errorf("modifying the merge result modified b: %#v", b)
+	}
+}
+
+func nestedMapForBenchmark(n, offset int) map[string]map[string]int {
+	m := make(map[string]map[string]int, n)
+	for i := 0; i < n; i++ {
+		inner := make(map[string]int, n)
+		for j := 0; j < n; j++ {
+			inner[fmt.Sprint(j)] = (i + j + offset) % 7
+		}
+		m[fmt.Sprint(i)] = inner
+	}
+	return m
+}
+
+func BenchmarkMergeNestedMap(b *testing.B) {
+	x, y := nestedMapForBenchmark(100, 0), nestedMapForBenchmark(100, 3)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Merge(&x, y)
+	}
+}
+
+func BenchmarkMergeNestedMapGeneric(b *testing.B) {
+	x, y := nestedMapForBenchmark(100, 0), nestedMapForBenchmark(100, 3)
+	generic := WithProvenance(func(string, Side) {})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		MergeWith(&x, y, generic)
+	}
+}
+
+func TestMergeFloatMapDeterministic(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	otherNaN := math.Float64frombits(0x7ff8000000000001)
+	states := []map[string]float64{
+		{"zero": 0, "signed": negZero, "nan": math.NaN(), "inf": math.Inf(-1), "tiny": math.SmallestNonzeroFloat64},
+		{"zero": negZero, "signed": 0, "nan": otherNaN, "inf": math.Inf(1), "tiny": -math.SmallestNonzeroFloat64},
+		{"nan": -1, "inf": math.MaxFloat64, "tiny": 0, "big": -math.MaxFloat64, "signed": negZero},
+		{"nan": math.NaN(), "big": math.NaN(), "zero": 0},
+	}
+	bits := func(m map[string]float64) map[string]uint64 {
+		b := make(map[string]uint64, len(m))
+		for k, v := range m {
+			b[k] = math.Float64bits(v)
+		}
+		return b
+	}
+	expected := map[string]uint64{
+		"zero":   math.Float64bits(negZero),
+		"signed": math.Float64bits(negZero),
+		"nan":    math.Float64bits(-1),
+		"inf":    math.Float64bits(math.Inf(1)),
+		"tiny":   math.Float64bits(math.SmallestNonzeroFloat64),
+		"big":    math.Float64bits(-math.MaxFloat64),
+	}
+	var permute func(order []int, rest []int)
+	permute = func(order []int, rest []int) {
+		if len(rest) == 0 {
+			var merged map[string]float64
+			for _, i := range order {
+				Merge(&merged, states[i])
+			}
+			if got := bits(merged); !reflect.DeepEqual(got, expected) {
+				t.Errorf("merging in order %v gave %v, expected %v", order, got, expected)
+			}
+			return
+		}
+		for i := range rest {
+			next := append(append([]int(nil), rest[:i]...), rest[i+1:]...)
+			permute(append(order, rest[i]), next)
+		}
+	}
+	permute(nil, []int{0, 1, 2, 3})
+
+	// Between NaNs alone, the one with the greater bits wins, whatever the order.
+	a, b := map[string]float64{"n": math.NaN()}, map[string]float64{"n": otherNaN}
+	ab, ba := bits(Join(a, b).(map[string]float64)), bits(Join(b, a).(map[string]float64))
+	if !reflect.DeepEqual(ab, ba) {
+		t.Errorf("joining NaNs gave %v and %v in different orders", ab, ba)
+	}
+}
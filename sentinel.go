@@ -0,0 +1,51 @@
+package crdt
+
+import (
+	"reflect"
+	"sync"
+)
+
+// sentinels holds the registered deletion sentinels, keyed by value type.
+var sentinels sync.Map // map[reflect.Type]reflect.Value
+
+// RegisterSentinel registers deleted, a value of type t, as meaning "deleted" when it is a map value.
+// This is a lightweight alternative to TombstoneMap for append-heavy, low-churn data:
+// a replica deletes a key by setting its value to deleted, and Live returns the map without the deleted keys.
+//
+// The merge itself keeps deleted entries, since dropping them would let a replica that hasn't seen the deletion
+// bring the key back, and replicas would no longer converge. The sentinel is merged like any other value,
+// so it should be greater than the values it deletes, such as the maximum of a counter;
+// as a result, a re-add can't beat a deletion unless its value is greater than the sentinel.
+func RegisterSentinel(t reflect.Type, deleted interface{}) {
+	value := reflect.ValueOf(deleted)
+	if value.Type() != t {
+		panic("deleted must be a value of type t")
+	}
+	sentinels.Store(t, value)
+}
+
+// isDeleted returns true if v equals the deletion sentinel registered for its type.
+func isDeleted(v reflect.Value) bool {
+	sentinel, ok := sentinels.Load(v.Type())
+	return ok && reflect.DeepEqual(v.Interface(), sentinel.(reflect.Value).Interface())
+}
+
+// Live returns a copy of the map m without the keys whose values equal the sentinel
+// registered for m's value type with RegisterSentinel. m is not modified.
+func Live(m interface{}) interface{} {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map {
+		panic("m must be a map")
+	}
+	if v.IsNil() {
+		return m
+	}
+	live := reflect.MakeMapWithSize(v.Type(), v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		if !isDeleted(iter.Value()) {
+			live.SetMapIndex(iter.Key(), iter.Value())
+		}
+	}
+	return live.Interface()
+}
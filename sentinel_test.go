@@ -0,0 +1,42 @@
+package crdt
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// sentinelCount is a counter whose maximum value means "deleted".
+type sentinelCount int
+
+const deletedCount = sentinelCount(math.MaxInt)
+
+func init() {
+	RegisterSentinel(reflect.TypeOf(deletedCount), deletedCount)
+}
+
+func TestRegisterSentinel(t *testing.T) {
+	a := map[string]sentinelCount{"x": 1, "y": 2}
+	b := map[string]sentinelCount{"x": deletedCount, "y": 3, "z": deletedCount}
+	expected := map[string]sentinelCount{"y": 3}
+	ab, ba := Join(a, b), Join(b, a)
+	if !reflect.DeepEqual(ab, ba) {
+		t.Errorf("Join(a, b) = %#v, Join(b, a) = %#v", ab, ba)
+	}
+	if live := Live(ab); !reflect.DeepEqual(live, expected) {
+		t.Errorf("Live(Join(a, b)) = %#v, expected %#v", live, expected)
+	}
+
+	// A stale replica that still has x can't bring it back.
+	stale := map[string]sentinelCount{"x": 7}
+	if live := Live(Join(ab, stale)); !reflect.DeepEqual(live, expected) {
+		t.Errorf("Live after merging a stale replica = %#v, expected %#v", live, expected)
+	}
+}
+
+func TestLiveUnregistered(t *testing.T) {
+	m := map[string]int{"x": math.MaxInt}
+	if live := Live(m); !reflect.DeepEqual(live, m) {
+		t.Errorf("Live(%#v) = %#v", m, live)
+	}
+}
@@ -0,0 +1,53 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// A struct whose schema gains fields over versions can declare its version in an integer field
+// tagged `crdt:"version"`, and tag each field added in version N `crdt:"since=N"`. Merging a state
+// whose version is below N leaves such a field unchanged, since that state predates the field
+// and its zero value there says nothing about it, so a stale replica can't clobber it during a rolling
+// upgrade. The version field itself merges by max, like any other integer.
+
+// sinceVersion returns the version of the struct b, whose field tags are tags, and whether b has
+// a version field. It returns an error if b has fields tagged since but no integer version field.
+func sinceVersion(b reflect.Value, tags []tagOptions) (version int64, ok bool, err error) {
+	gated := false
+	for i, opts := range tags {
+		if opts.has("since") {
+			if _, err := strconv.ParseInt(opts["since"], 10, 64); err != nil {
+				return 0, false, fmt.Errorf("field %s: since version %q is not an integer", b.Type().Field(i).Name, opts["since"])
+			}
+			gated = true
+		}
+		if !opts.has("version") {
+			continue
+		}
+		switch field := b.Field(i); field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			version, ok = field.Int(), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			version, ok = int64(field.Uint()), true
+		default:
+			return 0, false, fmt.Errorf("version field %s of type %s is not an integer", b.Type().Field(i).Name, field.Type())
+		}
+	}
+	if gated && !ok {
+		return 0, false, fmt.Errorf("%s has fields tagged since but no version field", b.Type())
+	}
+	return version, ok, nil
+}
+
+// sinceSkips returns true if the field whose tag options are opts is newer than version,
+// the version of the struct being merged from, and so must not be merged from it.
+func sinceSkips(opts tagOptions, version int64) bool {
+	since, ok := opts["since"]
+	if !ok {
+		return false
+	}
+	n, _ := strconv.ParseInt(since, 10, 64)
+	return version < n
+}
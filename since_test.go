@@ -0,0 +1,55 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type versionedState struct {
+	Version int `crdt:"version"`
+	Name    string
+	Email   string `crdt:"lww=Updated,since=3"`
+	Updated int64  `crdt:"since=3"`
+}
+
+func TestSince(t *testing.T) {
+	v3 := versionedState{3, "a", "a@example.com", 5}
+	// v2 predates Email, but holds a stray Updated time that must not be merged.
+	v2 := versionedState{2, "b", "", 9}
+	expected := versionedState{3, "b", "a@example.com", 5}
+	value := v3
+	if !Merge(&value, v2) || !reflect.DeepEqual(value, expected) {
+		t.Errorf("merging v2 into v3 gave %#v, expected %#v", value, expected)
+	}
+
+	// A v3 state is merged into an older one in full, upgrading it.
+	value = versionedState{2, "b", "", 0}
+	Merge(&value, v3)
+	if expected := (versionedState{3, "b", "a@example.com", 5}); !reflect.DeepEqual(value, expected) {
+		t.Errorf("merging v3 into v2 gave %#v, expected %#v", value, expected)
+	}
+
+	later := versionedState{4, "", "c@example.com", 6}
+	value = v3
+	if Merge(&value, later); value.Email != "c@example.com" {
+		t.Errorf("merging a later write from v4 gave Email %q, expected c@example.com", value.Email)
+	}
+}
+
+func TestSinceInvalid(t *testing.T) {
+	type noVersion struct {
+		New int `crdt:"since=2"`
+	}
+	type badSince struct {
+		Version uint `crdt:"version"`
+		New     int  `crdt:"since=two"`
+	}
+	for _, v := range []interface{}{noVersion{}, badSince{}} {
+		if err := Validate(reflect.TypeOf(v)); err == nil {
+			t.Errorf("Validate(%T) gave no error", v)
+		}
+		if _, err := MergeWith(reflect.New(reflect.TypeOf(v)).Interface(), v); err == nil {
+			t.Errorf("MergeWith(%T) gave no error", v)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package crdt
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+var (
+	byteType = reflect.TypeOf(byte(0))
+	runeType = reflect.TypeOf(rune(0))
+)
+
+// isText returns true if t is []byte or []rune. Like strings, these are merged as whole values,
+// with the lexicographically greater one winning, since merging them element by element
+// would scramble them. (Since rune is an alias for int32, this includes []int32.)
+func isText(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && (t.Elem() == byteType || t.Elem() == runeType)
+}
+
+// compareText compares two []byte or two []rune values lexicographically.
+func compareText(a, b reflect.Value) int {
+	if a.Type().Elem() == byteType {
+		return bytes.Compare(a.Bytes(), b.Bytes())
+	}
+	for i := 0; i < a.Len() && i < b.Len(); i++ {
+		if x, y := a.Index(i).Int(), b.Index(i).Int(); x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return a.Len() - b.Len()
+}
+
+// mergeSlice merges the slice b into the slice a, according to the `crdt` tag of the field being merged.
+// It returns true if the value of a was modified.
+func (m *merger) mergeSlice(a, b reflect.Value) bool {
+	var changed bool
+	if m.tag.has("set") || m.tag.has("sortedmax") || m.tag.has("log") || isText(a.Type()) {
+		// Slices merged as whole values may be rebuilt from both sides' elements.
+		m.chargeCopy(b)
+	}
+	switch {
+	case m.tag["set"] != "":
+		return m.mergeKeyedSet(a, b, m.tag["set"])
+	case m.tag.has("set"):
+		changed = mergeSetUnion(a, b)
+	case m.tag.has("sortedmax"):
+		changed = mergeSortedMax(a, b)
+	case m.tag.has("log"):
+		changed = m.mergeLog(a, b)
+	case isText(a.Type()):
+		if compareText(a, b) < 0 {
+			a.Set(copySlice(b))
+			changed = true
+		}
+	default:
+		return m.mergeElementwise(a, b)
+	}
+	m.decide(changed, a)
+	return changed
+}
+
+// SliceLenPolicy selects how slices merged element by element are merged when their lengths differ.
+type SliceLenPolicy int
+
+const (
+	// SliceLenPad merges the elements both slices have, and keeps the longer slice's extra tail,
+	// as if the shorter slice were padded with zero values. This is the default.
+	SliceLenPad SliceLenPolicy = iota
+	// SliceLenTruncate merges the elements both slices have, and drops the longer slice's extra tail.
+	// Since this discards information, it isn't a join in the lattice sense, except that an empty slice
+	// is still the bottom value; use it only where slices of different lengths are meant to be cut short.
+	SliceLenTruncate
+	// SliceLenError makes merging two non-empty slices of different lengths an error,
+	// to catch bugs in systems where all replicas' slices should have the same length.
+	SliceLenError
+)
+
+// WithSliceLenPolicy sets how slices merged element by element are merged when their lengths differ.
+func WithSliceLenPolicy(policy SliceLenPolicy) Option {
+	return func(c *config) {
+		c.sliceLen = policy
+	}
+}
+
+// mergeElementwise merges the slice b into the slice a element by element, like a map keyed by index,
+// with the configured SliceLenPolicy. It returns true if the value of a was modified.
+func (m *merger) mergeElementwise(a, b reflect.Value) bool {
+	if b.Len() == 0 {
+		return false
+	}
+	if a.Len() == 0 {
+		m.chargeCopy(b)
+		a.Set(deepCopy(b))
+		m.decide(true, a)
+		return true
+	}
+	var changed bool
+	n := a.Len()
+	if b.Len() != n {
+		switch m.sliceLen {
+		case SliceLenPad:
+			if b.Len() > n {
+				m.chargeCopy(b.Slice(n, b.Len()))
+				grown := reflect.MakeSlice(a.Type(), b.Len(), b.Len())
+				reflect.Copy(grown, a)
+				for i := n; i < b.Len(); i++ {
+					copyInto(grown.Index(i), b.Index(i))
+				}
+				a.Set(grown)
+				changed = true
+			}
+		case SliceLenTruncate:
+			if b.Len() < n {
+				n = b.Len()
+				a.Set(a.Slice(0, n))
+				changed = true
+			}
+		default:
+			panic(mergeErrorf("can't merge slices of different lengths %d and %d", a.Len(), b.Len()))
+		}
+	}
+	for i := 0; i < n && i < b.Len(); i++ {
+		m.path.pushKey(reflect.ValueOf(i))
+		if m.merge(a.Index(i), b.Index(i)) {
+			changed = true
+		}
+		m.path.pop()
+	}
+	return changed
+}
+
+// mergeSetUnion sets the slice a to the sorted, deduplicated union of the elements of a and b,
+// which must have a total ordering. It returns true if the value of a was modified.
+func mergeSetUnion(a, b reflect.Value) bool {
+	if a.Len() == 0 && b.Len() == 0 {
+		return false
+	}
+	union := reflect.MakeSlice(a.Type(), 0, a.Len()+b.Len())
+	union = reflect.AppendSlice(union, a)
+	union = reflect.AppendSlice(union, b)
+	sort.SliceStable(union.Interface(), func(i, j int) bool {
+		return compare(union.Index(i), union.Index(j)) < 0
+	})
+	n := 0
+	for i := 0; i < union.Len(); i++ {
+		if n > 0 && compare(union.Index(n-1), union.Index(i)) == 0 {
+			continue
+		}
+		union.Index(n).Set(union.Index(i))
+		n++
+	}
+	union = union.Slice(0, n)
+	if reflect.DeepEqual(union.Interface(), a.Interface()) {
+		return false
+	}
+	a.Set(union)
+	return true
+}
+
+// MergeSortedMax returns the join of the sorted sequences a and b, such as envelopes of monotonic time series:
+// the sorted pointwise maximum of the two. Each element is the greatest of the elements of a and b
+// at its index and all the indexes before it, so that the longer sequence's tail is kept, but raised
+// where needed to keep the result sorted. The result never shares storage with a or b.
+// It is the merge of slices tagged `crdt:"sortedmax"`. a and b should be sorted in increasing order.
+func MergeSortedMax[T cmp.Ordered](a, b []T) []T {
+	n := max(len(a), len(b))
+	if n == 0 {
+		return nil
+	}
+	result := make([]T, n)
+	for i := range result {
+		if i < len(a) {
+			result[i] = a[i]
+		}
+		if i < len(b) && (i >= len(a) || result[i] < b[i]) {
+			result[i] = b[i]
+		}
+		if i > 0 && result[i] < result[i-1] {
+			result[i] = result[i-1]
+		}
+	}
+	return result
+}
+
+// mergeSortedMax sets the slice a to the sorted pointwise maximum of the slices a and b, as MergeSortedMax does.
+// Their elements must have a total ordering. It returns true if the value of a was modified.
+func mergeSortedMax(a, b reflect.Value) bool {
+	n := max(a.Len(), b.Len())
+	result := reflect.MakeSlice(a.Type(), n, n)
+	changed := n != a.Len()
+	for i := 0; i < n; i++ {
+		elem := result.Index(i)
+		if i < a.Len() {
+			elem.Set(a.Index(i))
+		}
+		if i < b.Len() && (i >= a.Len() || less(elem, b.Index(i))) {
+			elem.Set(b.Index(i))
+		}
+		if i > 0 && less(elem, result.Index(i-1)) {
+			elem.Set(result.Index(i - 1))
+		}
+		if i < a.Len() && compare(elem, a.Index(i)) != 0 {
+			changed = true
+		}
+	}
+	if changed {
+		a.Set(result)
+	}
+	return changed
+}
+
+// VectorAdd adds delta to the element of the vector v at index, growing v with zeros as needed,
+// and returns the updated vector. Vectors like this merge element by element under SliceLenPad:
+// each index takes the greater of the two values, and the longer vector's tail is kept,
+// so delta should be positive for an increment to survive merges with older copies of v.
+//
+// []int32 and []uint8 are the same types as []rune and []byte, which merge as whole text values
+// rather than elementwise, so VectorAdd panics for them; use a named element type, like
+// type count int32, whose vectors merge elementwise.
+func VectorAdd[T Integer](v []T, index int, delta T) []T {
+	if index < 0 {
+		panic("crdt: negative vector index")
+	}
+	if isText(reflect.TypeOf(v)) {
+		panic(fmt.Sprintf("crdt: %T merges as text, not as a vector", v))
+	}
+	if index >= len(v) {
+		v = append(v, make([]T, index+1-len(v))...)
+	}
+	v[index] += delta
+	return v
+}
+
+// setKey returns the index of the field named key of the struct type t, by which elements of
+// a slice tagged `crdt:"set=key"` are identified, or an error if there is no such ordered field.
+func setKey(t reflect.Type, key string) (int, error) {
+	if t.Kind() != reflect.Struct {
+		return 0, fmt.Errorf("set elements of type %s are not structs with a %s field", t, key)
+	}
+	field, ok := t.FieldByName(key)
+	if !ok || len(field.Index) != 1 || field.PkgPath != "" || !isOrdered(field.Type.Kind()) {
+		return 0, fmt.Errorf("set elements of type %s have no ordered field %s", t, key)
+	}
+	return field.Index[0], nil
+}
+
+// mergeKeyedSet sets the slice a, of structs identified by their field key, to the union of the elements
+// of a and b, where elements with the same identity are merged into one, sorted by identity.
+// It returns true if the value of a was modified.
+func (m *merger) mergeKeyedSet(a, b reflect.Value, key string) bool {
+	if a.Len() == 0 && b.Len() == 0 {
+		return false
+	}
+	index, err := setKey(a.Type().Elem(), key)
+	if err != nil {
+		panic(mergeErrorf("%v", err))
+	}
+	tag := m.tag
+	m.tag = nil
+	defer func() { m.tag = tag }()
+	union := reflect.MakeSlice(a.Type(), 0, a.Len()+b.Len())
+	positions := make(map[interface{}]int, a.Len()+b.Len())
+	for _, s := range []reflect.Value{a, b} {
+		for i := 0; i < s.Len(); i++ {
+			elem := s.Index(i)
+			id := elem.Field(index)
+			if pos, ok := positions[id.Interface()]; ok {
+				m.path.pushKey(id)
+				m.merge(union.Index(pos), elem)
+				m.path.pop()
+			} else {
+				positions[id.Interface()] = union.Len()
+				union = reflect.Append(union, deepCopy(elem))
+			}
+		}
+	}
+	sort.SliceStable(union.Interface(), func(i, j int) bool {
+		return less(union.Index(i).Field(index), union.Index(j).Field(index))
+	})
+	changed := union.Len() != a.Len()
+	for i := 0; i < a.Len() && !changed; i++ {
+		changed = !reflect.DeepEqual(a.Index(i).Interface(), union.Index(i).Interface())
+	}
+	if changed {
+		a.Set(union)
+	}
+	return changed
+}
@@ -0,0 +1,242 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeSetUnion(t *testing.T) {
+	type A struct {
+		Tags []string `crdt:"set"`
+	}
+	testJoin := func(a, b, expected A) {
+		for _, pair := range [][2]A{{a, b}, {b, a}} {
+			if result := Join(pair[0], pair[1]); !reflect.DeepEqual(result, expected) {
+				t.Errorf("Join(%v, %v) = %v, expected %v", pair[0], pair[1], result, expected)
+			}
+		}
+	}
+	testJoin(A{[]string{"b", "a"}}, A{[]string{"c", "a"}}, A{[]string{"a", "b", "c"}})
+	testJoin(A{}, A{[]string{"b", "b", "a"}}, A{[]string{"a", "b"}})
+	testJoin(A{}, A{}, A{})
+
+	value := A{[]string{"a", "b"}}
+	if Merge(&value, A{[]string{"b"}}) {
+		t.Errorf("Merge(a, subset) = true, expected false")
+	}
+}
+
+func TestMergeMultimap(t *testing.T) {
+	type A struct {
+		Tags map[string][]int `crdt:"set"`
+	}
+	a := A{map[string][]int{"x": {3, 1}, "y": {5}}}
+	b := A{map[string][]int{"x": {2, 3, 4}, "z": {7, 7}}}
+	expected := A{map[string][]int{"x": {1, 2, 3, 4}, "y": {5}, "z": {7}}}
+	for _, pair := range [][2]A{{a, b}, {b, a}} {
+		if result := Join(pair[0], pair[1]); !reflect.DeepEqual(result, expected) {
+			t.Errorf("Join(%v, %v) = %v, expected %v", pair[0], pair[1], result, expected)
+		}
+	}
+}
+
+func TestMergeText(t *testing.T) {
+	testJoin := func(a, b, expected interface{}) {
+		for _, pair := range [][2]interface{}{{a, b}, {b, a}} {
+			if result := Join(pair[0], pair[1]); !reflect.DeepEqual(result, expected) {
+				t.Errorf("Join(%#v, %#v) = %#v, expected %#v", pair[0], pair[1], result, expected)
+			}
+		}
+	}
+	testJoin([]rune("hello"), []rune("help"), []rune("help"))
+	testJoin([]rune("héllo"), []rune("hello"), []rune("héllo"))
+	testJoin([]rune("ab"), []rune("abc"), []rune("abc"))
+	testJoin([]rune(nil), []rune("a"), []rune("a"))
+	testJoin([]byte("hello"), []byte("help"), []byte("help"))
+	testJoin([]byte(nil), []byte("a"), []byte("a"))
+
+	// The winner is copied rather than shared.
+	source := []rune("b")
+	value := []rune("a")
+	Merge(&value, source)
+	source[0] = 'z'
+	if string(value) != "b" {
+		t.Errorf("mutating source changed merged value to %q", string(value))
+	}
+}
+
+func TestMergeElementwise(t *testing.T) {
+	type state struct {
+		Counts []int
+		Nested [][]int
+	}
+	a := state{Counts: []int{1, 5, 2}, Nested: [][]int{{1}, {2, 3}}}
+	b := state{Counts: []int{3, 4}, Nested: [][]int{{0, 7}}}
+	expected := state{Counts: []int{3, 5, 2}, Nested: [][]int{{1, 7}, {2, 3}}}
+	for _, joined := range []interface{}{Join(a, b), Join(b, a)} {
+		if !reflect.DeepEqual(joined, expected) {
+			t.Errorf("Join = %v, expected %v", joined, expected)
+		}
+	}
+	// Growing a slice doesn't write into the spare capacity of a's backing array.
+	backing := make([]int, 1, 4)
+	grown := backing[:1]
+	Merge(&grown, []int{0, 2, 3})
+	if backing[:2][1] != 0 {
+		t.Errorf("merge wrote into the spare capacity of a's backing array")
+	}
+}
+
+func TestWithSliceLenPolicy(t *testing.T) {
+	for _, test := range []struct {
+		policy   SliceLenPolicy
+		a, b     []int
+		expected []int
+		err      bool
+	}{
+		{SliceLenPad, []int{1, 5}, []int{3, 4, 6}, []int{3, 5, 6}, false},
+		{SliceLenPad, []int{3, 4, 6}, []int{1, 5}, []int{3, 5, 6}, false},
+		{SliceLenTruncate, []int{1, 5}, []int{3, 4, 6}, []int{3, 5}, false},
+		{SliceLenTruncate, []int{3, 4, 6}, []int{1, 5}, []int{3, 5}, false},
+		{SliceLenTruncate, nil, []int{1, 5}, []int{1, 5}, false},
+		{SliceLenError, []int{1, 5}, []int{3, 4, 6}, nil, true},
+		{SliceLenError, []int{1, 5}, []int{3, 4}, []int{3, 5}, false},
+		{SliceLenError, []int{1, 5}, nil, []int{1, 5}, false},
+	} {
+		a := append([]int(nil), test.a...)
+		_, err := MergeWith(&a, test.b, WithSliceLenPolicy(test.policy))
+		if (err != nil) != test.err {
+			t.Errorf("policy %d: merging %v into %v returned error %v", test.policy, test.b, test.a, err)
+		} else if !test.err && !reflect.DeepEqual(a, test.expected) {
+			t.Errorf("policy %d: merging %v into %v gave %v, expected %v", test.policy, test.b, test.a, a, test.expected)
+		}
+	}
+}
+
+func TestVectorAdd(t *testing.T) {
+	var a, b []int
+	a = VectorAdd(a, 1, 2)
+	a = VectorAdd(a, 0, 1)
+	b = VectorAdd(b, 4, 3)
+	b = VectorAdd(b, 1, 1)
+	if expected := []int{1, 2}; !reflect.DeepEqual(a, expected) {
+		t.Errorf("a = %v, expected %v", a, expected)
+	}
+	if expected := []int{0, 1, 0, 0, 3}; !reflect.DeepEqual(b, expected) {
+		t.Errorf("b = %v, expected %v", b, expected)
+	}
+	expected := []int{1, 2, 0, 0, 3}
+	for _, joined := range []interface{}{Join(a, b), Join(b, a)} {
+		if !reflect.DeepEqual(joined, expected) {
+			t.Errorf("Join = %v, expected %v", joined, expected)
+		}
+	}
+	a = VectorAdd(JoinT(a, b), 6, 1)
+	if expected := []int{1, 2, 0, 0, 3, 0, 1}; !reflect.DeepEqual(a, expected) || !reflect.DeepEqual(Join(a, b), expected) {
+		t.Errorf("after growing the join, a = %v and Join(a, b) = %v, expected %v", a, Join(a, b), expected)
+	}
+}
+
+func TestVectorAddText(t *testing.T) {
+	type count int32
+	type small uint8
+	if joined := Join(VectorAdd([]count{}, 0, 5), VectorAdd([]count{}, 1, 3)); !reflect.DeepEqual(joined, []count{5, 3}) {
+		t.Errorf("Join of []count vectors = %v, expected [5 3]", joined)
+	}
+	if joined := Join(VectorAdd([]small{}, 0, 5), VectorAdd([]small{}, 1, 3)); !reflect.DeepEqual(joined, []small{5, 3}) {
+		t.Errorf("Join of []small vectors = %v, expected [5 3]", joined)
+	}
+	for _, add := range []func(){
+		func() { VectorAdd([]int32{}, 0, 5) },
+		func() { VectorAdd([]uint8{}, 0, 5) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("VectorAdd of a text type didn't panic")
+				}
+			}()
+			add()
+		}()
+	}
+}
+
+func TestMergeKeyedSet(t *testing.T) {
+	type event struct {
+		ID    string
+		Count int
+		Tags  []string `crdt:"set"`
+	}
+	type stream struct {
+		Events map[string][]event `crdt:"set=ID"`
+	}
+	a := stream{map[string][]event{
+		"x": {{ID: "e2", Count: 1}, {ID: "e1", Count: 5, Tags: []string{"a"}}},
+		"y": {{ID: "e9"}},
+	}}
+	b := stream{map[string][]event{
+		"x": {{ID: "e3", Count: 1}, {ID: "e1", Count: 2, Tags: []string{"b"}}},
+		"z": {{ID: "e4"}},
+	}}
+	expected := stream{map[string][]event{
+		"x": {{ID: "e1", Count: 5, Tags: []string{"a", "b"}}, {ID: "e2", Count: 1}, {ID: "e3", Count: 1}},
+		"y": {{ID: "e9"}},
+		"z": {{ID: "e4"}},
+	}}
+	for _, joined := range []interface{}{Join(a, b), Join(b, a)} {
+		if !reflect.DeepEqual(joined, expected) {
+			t.Errorf("Join = %+v, expected %+v", joined, expected)
+		}
+	}
+	if a.Events["x"][0].ID != "e2" || len(a.Events["x"][1].Tags) != 1 {
+		t.Errorf("Join modified a: %+v", a)
+	}
+	joined := JoinT(a, b)
+	if Merge(&joined, b) || Merge(&joined, a) {
+		t.Errorf("merging a or b into their join reported a change")
+	}
+	if !Equal(a, stream{map[string][]event{"x": {a.Events["x"][1], a.Events["x"][0]}, "y": a.Events["y"]}}) {
+		t.Errorf("Equal distinguished keyed sets by order")
+	}
+
+	type bad struct {
+		Events []event `crdt:"set=Name"`
+	}
+	if err := Validate(reflect.TypeOf(bad{})); err == nil {
+		t.Errorf("Validate accepted a keyed set with a missing key field")
+	}
+}
+
+func TestMergeSortedMax(t *testing.T) {
+	tests := []struct {
+		a, b, expected []int
+	}{
+		{nil, nil, nil},
+		{[]int{1, 2}, nil, []int{1, 2}},
+		{[]int{1, 4, 6}, []int{2, 3, 7}, []int{2, 4, 7}},
+		{[]int{1, 5}, []int{2, 3, 4, 8}, []int{2, 5, 5, 8}},
+		{[]int{3, 3}, []int{3, 3}, []int{3, 3}},
+	}
+	for _, test := range tests {
+		for _, pair := range [][2][]int{{test.a, test.b}, {test.b, test.a}} {
+			if got := MergeSortedMax(pair[0], pair[1]); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("MergeSortedMax(%v, %v) = %v, expected %v", pair[0], pair[1], got, test.expected)
+			}
+			type envelope struct {
+				Values []int `crdt:"sortedmax"`
+			}
+			value := envelope{append([]int(nil), pair[0]...)}
+			changed := Merge(&value, envelope{pair[1]})
+			if !reflect.DeepEqual(value.Values, test.expected) {
+				t.Errorf("merging %v into %v as sortedmax gave %v, expected %v", pair[1], pair[0], value.Values, test.expected)
+			}
+			if expected := !reflect.DeepEqual(pair[0], test.expected); changed != expected {
+				t.Errorf("merging %v into %v as sortedmax reported changed = %v, expected %v", pair[1], pair[0], changed, expected)
+			}
+		}
+	}
+	got := MergeSortedMax([]string{"a", "c"}, []string{"b"})
+	if expected := []string{"b", "c"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("MergeSortedMax of strings = %v, expected %v", got, expected)
+	}
+}
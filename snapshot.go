@@ -0,0 +1,47 @@
+package crdt
+
+import "sync/atomic"
+
+// Snapshotted holds a CRDT value of type T for lock-free reads by many goroutines.
+// Merges are copy-on-write: each clones the current snapshot, merges into the clone,
+// and atomically swaps it in, so readers always see a complete, consistent value.
+// The zero value holds the zero value of T and is ready to use.
+type Snapshotted[T any] struct {
+	current atomic.Pointer[T]
+}
+
+// NewSnapshotted returns a Snapshotted holding initial.
+func NewSnapshotted[T any](initial T) *Snapshotted[T] {
+	s := new(Snapshotted[T])
+	value := Clone(initial).(T)
+	s.current.Store(&value)
+	return s
+}
+
+// Load returns the current snapshot. It never blocks.
+// The snapshot may share storage with later snapshots and with other readers, so it must not be modified.
+func (s *Snapshotted[T]) Load() T {
+	if current := s.current.Load(); current != nil {
+		return *current
+	}
+	var zero T
+	return zero
+}
+
+// Merge merges b into the value, and returns true if it was modified.
+// Concurrent merges don't block each other; if another merge is stored first, Merge retries against it.
+func (s *Snapshotted[T]) Merge(b T) bool {
+	for {
+		current := s.current.Load()
+		var next T
+		if current != nil {
+			next = Clone(*current).(T)
+		}
+		if !Merge(&next, b) {
+			return false
+		}
+		if s.current.CompareAndSwap(current, &next) {
+			return true
+		}
+	}
+}
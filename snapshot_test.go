@@ -0,0 +1,72 @@
+package crdt
+
+import (
+	"sync"
+	"testing"
+)
+
+type snapshotState struct {
+	A, B   int
+	Counts map[string]int
+}
+
+func TestSnapshotted(t *testing.T) {
+	s := NewSnapshotted(snapshotState{A: 1, B: 1, Counts: map[string]int{"n": 1}})
+	if !s.Merge(snapshotState{A: 2, B: 2, Counts: map[string]int{"n": 2}}) {
+		t.Errorf("Merge of a greater value returned false")
+	}
+	if s.Merge(snapshotState{A: 1, B: 1}) {
+		t.Errorf("Merge of a lesser value returned true")
+	}
+	if got := s.Load(); got.A != 2 || got.Counts["n"] != 2 {
+		t.Errorf("Load() = %#v", got)
+	}
+
+	var zero Snapshotted[snapshotState]
+	if got := zero.Load(); got.A != 0 || got.Counts != nil {
+		t.Errorf("zero Load() = %#v", got)
+	}
+}
+
+func TestSnapshottedConcurrent(t *testing.T) {
+	const writes = 200
+	s := new(Snapshotted[snapshotState])
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				// Every value merged in has A == B == Counts["n"], so every snapshot does too.
+				got := s.Load()
+				if got.A != got.B || got.A != got.Counts["n"] {
+					t.Errorf("Load() returned a torn state: %#v", got)
+					return
+				}
+			}
+		}()
+	}
+	var writers sync.WaitGroup
+	for w := 0; w < 2; w++ {
+		writers.Add(1)
+		go func(w int) {
+			defer writers.Done()
+			for i := 1; i <= writes; i++ {
+				n := i*2 + w
+				s.Merge(snapshotState{A: n, B: n, Counts: map[string]int{"n": n}})
+			}
+		}(w)
+	}
+	writers.Wait()
+	close(done)
+	readers.Wait()
+	if got := s.Load(); got.A != writes*2+1 {
+		t.Errorf("after all merges, Load() = %#v, expected A = %d", got, writes*2+1)
+	}
+}
@@ -0,0 +1,110 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// An integer field tagged `crdt:"sum=Counts"` is a counter that reads as a plain number: its value is the sum
+// of the entries of Counts, a sibling field of type map[string]T, where T is the field's type, that holds each
+// replica's total increments, as a GCounter's Counts does. Counts merges keywise by max, so merging a replica's
+// state again never counts its increments twice, and the field is then set to the sum of the merged Counts,
+// rather than merged itself. Use AddSum to increment such a field and its replica's count together.
+
+// sumCounts returns the index of the counts field of the field of the struct type t at index i,
+// which is tagged with the sum options tag.
+func sumCounts(t reflect.Type, i int, tag tagOptions) (int, error) {
+	field := t.Field(i)
+	switch field.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		return 0, fmt.Errorf("sum field %s of type %s is not an integer", field.Name, field.Type)
+	}
+	name := tag["sum"]
+	counts, ok := t.FieldByName(name)
+	if name == "" || !ok || len(counts.Index) != 1 {
+		return 0, fmt.Errorf("field %s: sum counts %s does not exist", field.Name, name)
+	}
+	if want := reflect.MapOf(reflect.TypeOf(""), field.Type); counts.Type != want {
+		return 0, fmt.Errorf("field %s: sum counts %s is a %s, not a %s", field.Name, name, counts.Type, want)
+	}
+	return counts.Index[0], nil
+}
+
+// sumOf returns the sum of the values of the map counts, as a value of its element type.
+func sumOf(counts reflect.Value) reflect.Value {
+	sum := reflect.New(counts.Type().Elem()).Elem()
+	iter := counts.MapRange()
+	for iter.Next() {
+		if sum.CanInt() {
+			sum.SetInt(sum.Int() + iter.Value().Int())
+		} else {
+			sum.SetUint(sum.Uint() + iter.Value().Uint())
+		}
+	}
+	return sum
+}
+
+// mergeSums sets each field of the struct a tagged sum to the sum of its merged counts,
+// once the rest of a's fields have been merged. It returns true if any of them was modified.
+func (m *merger) mergeSums(a reflect.Value, tags []tagOptions) bool {
+	var changed bool
+	for i, opts := range tags {
+		if !opts.has("sum") {
+			continue
+		}
+		counts, err := sumCounts(a.Type(), i, opts)
+		if err != nil {
+			panic(mergeErrorf("%v", err))
+		}
+		m.path.pushField(a.Type().Field(i).Name)
+		sum := sumOf(a.Field(counts))
+		fieldChanged := !sum.Equal(a.Field(i))
+		if fieldChanged {
+			a.Field(i).Set(sum)
+			changed = true
+		}
+		m.decide(fieldChanged, a.Field(i))
+		m.path.pop()
+	}
+	return changed
+}
+
+// AddSum adds n to replica's entry in the counts of the field of the struct pointed to by s,
+// and sets the field to their new sum. The field must be tagged `crdt:"sum=Counts"`. n must not be negative.
+func AddSum[T Integer](s interface{}, field, replica string, n T) {
+	if n < 0 {
+		panic("crdt: sum fields can't be decremented")
+	}
+	v := reflect.ValueOf(s).Elem()
+	f, ok := v.Type().FieldByName(field)
+	if !ok || len(f.Index) != 1 {
+		panic(fmt.Sprintf("crdt: %s has no field %s", v.Type(), field))
+	}
+	opts := fieldTags(v.Type())[f.Index[0]]
+	if !opts.has("sum") {
+		panic(fmt.Sprintf("crdt: field %s is not tagged sum", field))
+	}
+	i, err := sumCounts(v.Type(), f.Index[0], opts)
+	if err != nil {
+		panic("crdt: " + err.Error())
+	}
+	counts := v.Field(i)
+	if counts.IsNil() {
+		counts.Set(reflect.MakeMap(counts.Type()))
+	}
+	key := reflect.ValueOf(replica)
+	count := reflect.New(f.Type).Elem()
+	if prev := counts.MapIndex(key); prev.IsValid() {
+		count.Set(prev)
+	}
+	delta := reflect.ValueOf(n).Convert(f.Type)
+	if count.CanInt() {
+		count.SetInt(count.Int() + delta.Int())
+	} else {
+		count.SetUint(count.Uint() + delta.Uint())
+	}
+	counts.SetMapIndex(key, count)
+	v.Field(f.Index[0]).Set(sumOf(counts))
+}
@@ -0,0 +1,80 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type sumState struct {
+	Views  uint64 `crdt:"sum=Counts"`
+	Counts map[string]uint64
+	Name   string
+}
+
+func TestSum(t *testing.T) {
+	var a, b sumState
+	AddSum(&a, "Views", "a", 2)
+	AddSum(&a, "Views", "a", 1)
+	AddSum(&b, "Views", "b", 4)
+	b.Name = "b"
+	if a.Views != 3 || b.Views != 4 {
+		t.Fatalf("AddSum gave Views of %d and %d, expected 3 and 4", a.Views, b.Views)
+	}
+	joined := Join(a, b).(sumState)
+	expected := sumState{7, map[string]uint64{"a": 3, "b": 4}, "b"}
+	if !reflect.DeepEqual(joined, expected) {
+		t.Errorf("Join(%#v, %#v) = %#v, expected %#v", a, b, joined, expected)
+	}
+	if !Merge(&a, b) || !reflect.DeepEqual(a, expected) {
+		t.Errorf("Merge gave %#v, expected %#v", a, expected)
+	}
+	if Merge(&a, b) || !reflect.DeepEqual(a, expected) {
+		t.Errorf("merging b again gave %#v, expected %#v unchanged", a, expected)
+	}
+	AddSum(&b, "Views", "b", 1)
+	if !Merge(&a, b) || a.Views != 8 {
+		t.Errorf("merging b's later increment gave Views of %d, expected 8", a.Views)
+	}
+}
+
+func TestSumSigned(t *testing.T) {
+	type state struct {
+		Total  int32 `crdt:"sum=Counts"`
+		Counts map[string]int32
+	}
+	var a, b state
+	AddSum(&a, "Total", "a", int32(5))
+	AddSum(&b, "Total", "b", int32(6))
+	if joined := Join(a, b).(state); joined.Total != 11 {
+		t.Errorf("Join gave Total of %d, expected 11", joined.Total)
+	}
+}
+
+func TestSumInvalid(t *testing.T) {
+	type badCounts struct {
+		Total  int `crdt:"sum=Counts"`
+		Counts map[string]int64
+	}
+	type notInt struct {
+		Total  string `crdt:"sum=Counts"`
+		Counts map[string]string
+	}
+	for _, v := range []interface{}{badCounts{}, notInt{}} {
+		if err := Validate(reflect.TypeOf(v)); err == nil {
+			t.Errorf("Validate(%T) gave no error", v)
+		}
+		if _, err := MergeWith(reflect.New(reflect.TypeOf(v)).Interface(), v); err == nil {
+			t.Errorf("MergeWith(%T) gave no error", v)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("AddSum with a negative n didn't panic")
+		}
+	}()
+	var s struct {
+		Total  int `crdt:"sum=Counts"`
+		Counts map[string]int
+	}
+	AddSum(&s, "Total", "a", -1)
+}
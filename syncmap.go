@@ -0,0 +1,80 @@
+package crdt
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Fields of type *sync.Map are merged keywise: each key in b is stored into a if absent,
+// or else its value is merged with a's using the package's rules for the values' dynamic type.
+// A nil *sync.Map is the bottom value.
+//
+// The merge is safe to run concurrently with other loads, stores, and merges on either map,
+// but it does not see a consistent snapshot of b: keys stored into b while the merge is ranging
+// over it may or may not be merged. Each key is updated atomically with CompareAndSwap,
+// so concurrent merges into the same map don't lose each other's updates,
+// provided the values stored in it are of comparable types. Values of incomparable types
+// (such as maps) are stored unconditionally, so merges into a map holding them must not run concurrently.
+func init() {
+	Register(reflect.TypeOf((*sync.Map)(nil)), mergeSyncMapPtr)
+}
+
+// mergeSyncMapPtr merges the *sync.Map b into the *sync.Map pointed to by a.
+func mergeSyncMapPtr(a, b interface{}) bool {
+	value, other := a.(**sync.Map), b.(*sync.Map)
+	if other == nil {
+		return false
+	}
+	var changed bool
+	if *value == nil {
+		*value = new(sync.Map)
+		changed = true
+	}
+	if mergeSyncMap(*value, other) {
+		changed = true
+	}
+	return changed
+}
+
+// mergeSyncMap merges the entries of b into a.
+func mergeSyncMap(a, b *sync.Map) bool {
+	var changed bool
+	b.Range(func(key, bValue interface{}) bool {
+		fresh := joinInterface(nil, bValue)
+		for {
+			aValue, loaded := a.LoadOrStore(key, fresh)
+			if !loaded {
+				changed = true
+				return true
+			}
+			newValue := joinInterface(aValue, bValue)
+			if reflect.DeepEqual(newValue, aValue) {
+				return true
+			}
+			if !reflect.TypeOf(aValue).Comparable() {
+				a.Store(key, newValue)
+				changed = true
+				return true
+			}
+			if a.CompareAndSwap(key, aValue, newValue) {
+				changed = true
+				return true
+			}
+		}
+	})
+	return changed
+}
+
+// joinInterface returns the least upper bound of the dynamic values a and b, which must be of the same type.
+// A nil a is treated as the bottom value.
+func joinInterface(a, b interface{}) interface{} {
+	bVal := reflect.ValueOf(b)
+	if a == nil {
+		return join(reflect.New(bVal.Type()).Elem(), bVal).Interface()
+	}
+	aVal := reflect.ValueOf(a)
+	if aVal.Type() != bVal.Type() {
+		panic(mergeErrorf("can't merge %s with %s", aVal.Type(), bVal.Type()))
+	}
+	return join(aVal, bVal).Interface()
+}
@@ -0,0 +1,76 @@
+package crdt
+
+import (
+	"sync"
+	"testing"
+)
+
+type syncMapState struct {
+	Counts *sync.Map
+}
+
+func newSyncMapState(entries map[string]int) syncMapState {
+	state := syncMapState{new(sync.Map)}
+	for key, value := range entries {
+		state.Counts.Store(key, value)
+	}
+	return state
+}
+
+func syncMapEntries(m *sync.Map) map[string]int {
+	entries := map[string]int{}
+	m.Range(func(key, value interface{}) bool {
+		entries[key.(string)] = value.(int)
+		return true
+	})
+	return entries
+}
+
+func TestMergeSyncMap(t *testing.T) {
+	var value syncMapState
+	if Merge(&value, syncMapState{}) {
+		t.Errorf("Merge(nil, nil) = true, expected false")
+	}
+	if !Merge(&value, newSyncMapState(map[string]int{"a": 1, "b": 2})) {
+		t.Errorf("Merge(nil, b) = false, expected true")
+	}
+	if !Merge(&value, newSyncMapState(map[string]int{"b": 3, "c": 1})) {
+		t.Errorf("Merge(a, b) = false, expected true")
+	}
+	if Merge(&value, newSyncMapState(map[string]int{"a": 1, "b": 1})) {
+		t.Errorf("Merge(a, dominated) = true, expected false")
+	}
+	entries := syncMapEntries(value.Counts)
+	if len(entries) != 3 || entries["a"] != 1 || entries["b"] != 3 || entries["c"] != 1 {
+		t.Errorf("After merge was %v", entries)
+	}
+}
+
+func TestMergeSyncMapConcurrent(t *testing.T) {
+	value := newSyncMapState(nil)
+	sources := make([]syncMapState, 8)
+	for i := range sources {
+		sources[i] = newSyncMapState(map[string]int{"shared": i, string(rune('a' + i)): i})
+	}
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source syncMapState) {
+			defer wg.Done()
+			// Merge each source more than once, so that merges of the same key race.
+			for i := 0; i < 10; i++ {
+				Merge(&value, source)
+			}
+		}(source)
+	}
+	wg.Wait()
+	entries := syncMapEntries(value.Counts)
+	if entries["shared"] != len(sources)-1 {
+		t.Errorf("After concurrent merges shared = %d, expected %d", entries["shared"], len(sources)-1)
+	}
+	for i := range sources {
+		if key := string(rune('a' + i)); entries[key] != i {
+			t.Errorf("After concurrent merges %s = %d, expected %d", key, entries[key], i)
+		}
+	}
+}
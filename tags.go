@@ -0,0 +1,49 @@
+package crdt
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// tagOptions holds the options in a struct field's `crdt` tag.
+// The tag is a comma-separated list of options, each either a bare name or name=value,
+// e.g. `crdt:"lww=Updated"`. Bare names map to the empty string.
+type tagOptions map[string]string
+
+// parseTag parses the options in a `crdt` tag.
+func parseTag(tag string) tagOptions {
+	if tag == "" {
+		return nil
+	}
+	opts := tagOptions{}
+	for _, opt := range strings.Split(tag, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		if name != "" {
+			opts[name] = value
+		}
+	}
+	return opts
+}
+
+// has returns true if the options include name.
+func (opts tagOptions) has(name string) bool {
+	_, ok := opts[name]
+	return ok
+}
+
+// structTags caches the parsed tag options of each field of the struct types seen by fieldTags.
+var structTags sync.Map // map[reflect.Type][]tagOptions
+
+// fieldTags returns the parsed `crdt` tag options of each field of the struct type t.
+func fieldTags(t reflect.Type) []tagOptions {
+	if tags, ok := structTags.Load(t); ok {
+		return tags.([]tagOptions)
+	}
+	tags := make([]tagOptions, t.NumField())
+	for i := range tags {
+		tags[i] = parseTag(t.Field(i).Tag.Get("crdt"))
+	}
+	structTags.Store(t, tags)
+	return tags
+}
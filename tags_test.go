@@ -0,0 +1,18 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	testParse := func(tag string, expected tagOptions) {
+		if opts := parseTag(tag); !reflect.DeepEqual(opts, expected) {
+			t.Errorf("parseTag(%q) = %v, expected %v", tag, opts, expected)
+		}
+	}
+	testParse("", nil)
+	testParse("lww", tagOptions{"lww": ""})
+	testParse("lww=Updated", tagOptions{"lww": "Updated"})
+	testParse("union, discriminator=Kind", tagOptions{"union": "", "discriminator": "Kind"})
+}
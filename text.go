@@ -0,0 +1,57 @@
+package crdt
+
+import (
+	"bytes"
+	"encoding"
+	"reflect"
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// WithTextScalars makes the merge treat values of any type implementing both encoding.TextMarshaler
+// and encoding.TextUnmarshaler as scalars, ordered by their marshaled text, rather than recursing into them.
+// The value whose text is lexicographically greater wins, and the zero value is the bottom value as usual.
+// The winning value is stored by unmarshaling its text, so a never shares b's storage.
+// Types with a Merger, registered MergeFunc, or Comparable are merged by those instead.
+func WithTextScalars() Option {
+	return func(c *config) {
+		c.textScalars = true
+	}
+}
+
+// isTextScalar returns true if values of t can be marshaled to and unmarshaled from text.
+func isTextScalar(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	return ptr.Implements(textMarshalerType) && ptr.Implements(textUnmarshalerType)
+}
+
+// marshalText returns the text form of v, whose type must satisfy isTextScalar.
+func marshalText(v reflect.Value) []byte {
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	text, err := ptr.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		panic(mergeErrorf("marshaling %s: %w", v.Type(), err))
+	}
+	return text
+}
+
+// mergeText merges b into a by comparing their text forms. It returns true if the value of a was modified.
+func mergeText(a, b reflect.Value) bool {
+	if isZero(b) {
+		return false
+	}
+	text := marshalText(b)
+	if !isZero(a) && bytes.Compare(marshalText(a), text) >= 0 {
+		return false
+	}
+	value := reflect.New(a.Type())
+	if err := value.Interface().(encoding.TextUnmarshaler).UnmarshalText(text); err != nil {
+		panic(mergeErrorf("unmarshaling %s: %w", a.Type(), err))
+	}
+	a.Set(value.Elem())
+	return true
+}
@@ -0,0 +1,86 @@
+package crdt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// textID is an opaque ID type with unexported fields, which can only be merged through its text form.
+type textID struct {
+	hi, lo uint32
+}
+
+func (id textID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%08x-%08x", id.hi, id.lo)), nil
+}
+
+func (id *textID) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%08x-%08x", &id.hi, &id.lo)
+	return err
+}
+
+type textState struct {
+	Owner textID
+	Refs  map[string]textID
+}
+
+func TestWithTextScalars(t *testing.T) {
+	a := textState{Owner: textID{1, 9}, Refs: map[string]textID{"x": {2, 0}, "y": {0, 5}}}
+	b := textState{Owner: textID{2, 0}, Refs: map[string]textID{"x": {1, 7}, "z": {3, 3}}}
+	expected := textState{Owner: textID{2, 0}, Refs: map[string]textID{"x": {2, 0}, "y": {0, 5}, "z": {3, 3}}}
+
+	ab, err := JoinWith(a, b, WithTextScalars())
+	if err != nil {
+		t.Fatalf("JoinWith(a, b) returned error: %v", err)
+	}
+	ba, err := JoinWith(b, a, WithTextScalars())
+	if err != nil {
+		t.Fatalf("JoinWith(b, a) returned error: %v", err)
+	}
+	if !Equal(ab, expected) || !Equal(ba, expected) {
+		t.Errorf("JoinWith(a, b) = %#v, JoinWith(b, a) = %#v, expected %#v", ab, ba, expected)
+	}
+
+	zero := textState{}
+	if changed, err := MergeWith(&zero, zero, WithTextScalars()); changed || err != nil {
+		t.Errorf("MergeWith(zero, zero) = %v, %v, expected false, nil", changed, err)
+	}
+}
+
+// textLevel is an ordered type whose text form orders differently from its numeric value: "9" > "10".
+type textLevel int
+
+func (l textLevel) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(int(l))), nil
+}
+
+func (l *textLevel) UnmarshalText(text []byte) error {
+	n, err := strconv.Atoi(string(text))
+	*l = textLevel(n)
+	return err
+}
+
+func TestWithTextScalarsOrdered(t *testing.T) {
+	type state struct {
+		Level  textLevel
+		Levels map[string]textLevel
+	}
+	a := state{9, map[string]textLevel{"k": 9}}
+	b := state{10, map[string]textLevel{"k": 10}}
+	if _, err := MergeWith(&a, b, WithTextScalars()); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (state{9, map[string]textLevel{"k": 9}}); !Equal(a, expected) {
+		t.Errorf("After merge was %#v, expected %#v", a, expected)
+	}
+}
+
+func TestWithoutTextScalars(t *testing.T) {
+	a := textState{Owner: textID{1, 9}}
+	var mergeErr *MergeError
+	if _, err := MergeWith(&a, textState{Owner: textID{2, 0}}); !errors.As(err, &mergeErr) {
+		t.Errorf("MergeWith without WithTextScalars returned %v, expected a MergeError", err)
+	}
+}
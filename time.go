@@ -0,0 +1,34 @@
+package crdt
+
+import (
+	"reflect"
+	"time"
+)
+
+// time.Time is a struct with unexported fields, but is totally ordered by its Compare method,
+// so it merges to the later time. The zero time is earlier than any time of interest,
+// which makes it the bottom value. This makes a time.Time field, or a map[K]time.Time of per-key
+// update times, last-writer-wins.
+//
+// A time.Time read from time.Now carries a monotonic clock reading, which Compare prefers
+// when both times have one, but which means nothing outside the process that read it.
+// Merges therefore strip monotonic readings before comparing, and store times without them.
+// Likewise, the same instant may be read in different Locations, so merges store times in UTC,
+// as t.UTC() does, which also strips monotonic readings. This way replicas converge on the same value,
+// Location included, whatever the order of merges. Merging into a time with a monotonic reading
+// or a Location other than UTC normalizes it even if the instant doesn't otherwise change.
+func init() {
+	registerValue(reflect.TypeOf(time.Time{}), func(a, b reflect.Value) bool {
+		value, other := a.Addr().Interface().(*time.Time), b.Interface().(time.Time).UTC()
+		stripped := value.UTC()
+		if stripped.Compare(other) < 0 {
+			*value = other
+			return true
+		}
+		if *value != stripped {
+			*value = stripped
+			return true
+		}
+		return false
+	})
+}
@@ -0,0 +1,72 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeTime(t *testing.T) {
+	earlier := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Second)
+	testJoin := func(a, b, expected time.Time) {
+		if result := Join(a, b).(time.Time); !result.Equal(expected) {
+			t.Errorf("Join(%v, %v) = %v, expected %v", a, b, result, expected)
+		}
+	}
+	testJoin(earlier, later, later)
+	testJoin(later, earlier, later)
+	testJoin(time.Time{}, earlier, earlier)
+	testJoin(earlier, time.Time{}, earlier)
+}
+
+func TestMergeTimeMap(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return base.Add(time.Duration(seconds) * time.Second)
+	}
+	a := map[string]time.Time{"x": at(1), "y": at(5), "only-a": at(2)}
+	b := map[string]time.Time{"x": at(3), "y": at(4), "only-b": at(6)}
+	expected := map[string]time.Time{"x": at(3), "y": at(5), "only-a": at(2), "only-b": at(6)}
+	for _, pair := range [][2]map[string]time.Time{{a, b}, {b, a}} {
+		if result := Join(pair[0], pair[1]); !reflect.DeepEqual(result, expected) {
+			t.Errorf("Join(%v, %v) = %v, expected %v", pair[0], pair[1], result, expected)
+		}
+	}
+}
+
+func TestMergeTimeMonotonic(t *testing.T) {
+	now := time.Now()
+	if now == now.Round(0) {
+		t.Skip("time.Now has no monotonic clock reading on this platform")
+	}
+	wall := now.UTC()
+	ab, ba := JoinT(now, wall), JoinT(wall, now)
+	if ab != wall || ba != wall {
+		t.Errorf("Join(now, wall) = %#v and Join(wall, now) = %#v, expected %#v", ab, ba, wall)
+	}
+	later := now.Add(time.Second)
+	if joined := JoinT(wall, later); joined != later.UTC() {
+		t.Errorf("Join(wall, later) = %#v, expected %#v", joined, later.UTC())
+	}
+	a := now
+	if !Merge(&a, time.Time{}) || a != wall {
+		t.Errorf("merging into a time with a monotonic reading didn't strip it: %#v", a)
+	}
+	if Merge(&a, now) {
+		t.Errorf("merging an equal time with a monotonic reading reported a change")
+	}
+}
+
+func TestMergeTimeLocation(t *testing.T) {
+	utc := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	local := utc.In(time.FixedZone("UTC+1", 60*60))
+	ab, ba := JoinT(utc, local), JoinT(local, utc)
+	if ab != utc || ba != utc || !Equal(ab, ba) || Fingerprint(ab) != Fingerprint(ba) {
+		t.Errorf("Join(utc, local) = %#v and Join(local, utc) = %#v, expected %#v", ab, ba, utc)
+	}
+	a := local
+	if !Merge(&a, time.Time{}) || a != utc {
+		t.Errorf("merging into a time in another Location didn't normalize it: %#v", a)
+	}
+}
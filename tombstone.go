@@ -0,0 +1,161 @@
+package crdt
+
+// Policy selects how a TombstoneMap resolves a key that was concurrently added and removed.
+type Policy int
+
+const (
+	// AddWins keeps a key that was added concurrently with its removal.
+	AddWins Policy = iota
+	// RemoveWins drops a key that was added concurrently with its removal.
+	// Once a key is removed, only an add made after observing the removal can bring it back.
+	RemoveWins
+)
+
+// Dot uniquely identifies a single operation: the Seq'th operation made by Replica.
+type Dot struct {
+	Replica string
+	Seq     uint64
+}
+
+// TombstoneMap is a map CRDT whose keys can be removed as well as added.
+// The zero value is an empty AddWins map.
+//
+// Every add and remove of a key is recorded as a dot, and each operation supersedes
+// the opposite operations it has observed on that key. A key whose adds have all been superseded
+// is absent; a key with an unsuperseded add is present, unless the policy is RemoveWins
+// and it also has an unsuperseded remove.
+//
+// Values are merged using the package's rules, and are retained after removal,
+// so a value that is removed and re-added is merged with its former self.
+// Replicas of the same map should use the same policy; if they don't, RemoveWins prevails.
+type TombstoneMap[K comparable, V any] struct {
+	Policy Policy
+	// Values holds the value of every key ever added, present or not.
+	Values map[K]V
+	// Adds holds the dots of each key's adds, mapped to whether they have been superseded by a remove.
+	Adds map[K]map[Dot]bool
+	// Removes holds the dots of each key's removes, mapped to whether they have been superseded by an add.
+	Removes map[K]map[Dot]bool
+	// Seqs holds the sequence number of the latest operation made by each replica.
+	Seqs map[string]uint64
+}
+
+// NewTombstoneMap returns an empty TombstoneMap with the given policy.
+func NewTombstoneMap[K comparable, V any](policy Policy) *TombstoneMap[K, V] {
+	return &TombstoneMap[K, V]{Policy: policy}
+}
+
+// Replicas returns the IDs of the replicas that have added or removed keys.
+func (m *TombstoneMap[K, V]) Replicas() []string {
+	return mapKeys(m.Seqs)
+}
+
+// nextDot returns a dot for a new operation made by replica.
+func (m *TombstoneMap[K, V]) nextDot(replica string) Dot {
+	if m.Seqs == nil {
+		m.Seqs = make(map[string]uint64)
+	}
+	m.Seqs[replica]++
+	return Dot{replica, m.Seqs[replica]}
+}
+
+// record adds dot to ops[key], and supersedes every dot in opposite[key].
+// The dot sets are replaced rather than modified, since merges may share them with other values.
+func record[K comparable](ops, opposite map[K]map[Dot]bool, key K, dot Dot) {
+	dots := make(map[Dot]bool, len(ops[key])+1)
+	for d, superseded := range ops[key] {
+		dots[d] = superseded
+	}
+	dots[dot] = false
+	ops[key] = dots
+	if len(opposite[key]) > 0 {
+		superseded := make(map[Dot]bool, len(opposite[key]))
+		for d := range opposite[key] {
+			superseded[d] = true
+		}
+		opposite[key] = superseded
+	}
+}
+
+// Add adds key to the map on behalf of replica, merging value into its value.
+func (m *TombstoneMap[K, V]) Add(replica string, key K, value V) {
+	if m.Values == nil {
+		m.Values = make(map[K]V)
+	}
+	if m.Adds == nil {
+		m.Adds = make(map[K]map[Dot]bool)
+	}
+	if m.Removes == nil {
+		m.Removes = make(map[K]map[Dot]bool)
+	}
+	m.Values[key] = Join(m.Values[key], value).(V)
+	record(m.Adds, m.Removes, key, m.nextDot(replica))
+}
+
+// Remove removes key from the map on behalf of replica.
+func (m *TombstoneMap[K, V]) Remove(replica string, key K) {
+	if m.Adds == nil {
+		m.Adds = make(map[K]map[Dot]bool)
+	}
+	if m.Removes == nil {
+		m.Removes = make(map[K]map[Dot]bool)
+	}
+	record(m.Removes, m.Adds, key, m.nextDot(replica))
+}
+
+// live returns true if any of dots hasn't been superseded.
+func live(dots map[Dot]bool) bool {
+	for _, superseded := range dots {
+		if !superseded {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains returns true if key is present in the map.
+func (m *TombstoneMap[K, V]) Contains(key K) bool {
+	if !live(m.Adds[key]) {
+		return false
+	}
+	return m.Policy == AddWins || !live(m.Removes[key])
+}
+
+// Get returns the value of key, and whether it is present in the map.
+func (m *TombstoneMap[K, V]) Get(key K) (V, bool) {
+	if !m.Contains(key) {
+		var zero V
+		return zero, false
+	}
+	return m.Values[key], true
+}
+
+// Keys returns the keys present in the map, in no particular order.
+func (m *TombstoneMap[K, V]) Keys() []K {
+	var keys []K
+	for key := range m.Adds {
+		if m.Contains(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Merge merges another TombstoneMap of the same type into this one.
+func (m *TombstoneMap[K, V]) Merge(other interface{}) bool {
+	o := other.(TombstoneMap[K, V])
+	changed := Merge(&m.Policy, o.Policy)
+	if Merge(&m.Values, o.Values) {
+		changed = true
+	}
+	if Merge(&m.Adds, o.Adds) {
+		changed = true
+	}
+	if Merge(&m.Removes, o.Removes) {
+		changed = true
+	}
+	if Merge(&m.Seqs, o.Seqs) {
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,63 @@
+package crdt
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestTombstoneMap(t *testing.T) {
+	for _, policy := range []Policy{AddWins, RemoveWins} {
+		m := NewTombstoneMap[string, int](policy)
+		m.Add("r1", "a", 1)
+		m.Add("r1", "b", 2)
+		if value, ok := m.Get("a"); !ok || value != 1 {
+			t.Errorf("policy %d: Get(a) = (%d, %v), expected (1, true)", policy, value, ok)
+		}
+		m.Remove("r1", "a")
+		if m.Contains("a") {
+			t.Errorf("policy %d: Contains(a) after remove = true, expected false", policy)
+		}
+		keys := m.Keys()
+		sort.Strings(keys)
+		if len(keys) != 1 || keys[0] != "b" {
+			t.Errorf("policy %d: Keys() = %v, expected [b]", policy, keys)
+		}
+		m.Add("r1", "a", 3)
+		if value, ok := m.Get("a"); !ok || value != 3 {
+			t.Errorf("policy %d: Get(a) after re-add = (%d, %v), expected (3, true)", policy, value, ok)
+		}
+	}
+}
+
+func TestTombstoneMapConcurrentAddRemove(t *testing.T) {
+	testPolicy := func(policy Policy, expectedPresent bool) {
+		// Both replicas start out with the key present.
+		var base TombstoneMap[string, int]
+		base.Policy = policy
+		base.Add("r1", "k", 1)
+		var r1, r2 TombstoneMap[string, int]
+		Merge(&r1, base)
+		Merge(&r2, base)
+
+		// Concurrently, r1 removes the key and r2 re-adds it.
+		r1.Remove("r1", "k")
+		r2.Add("r2", "k", 2)
+
+		ab := Join(r1, r2).(TombstoneMap[string, int])
+		ba := Join(r2, r1).(TombstoneMap[string, int])
+		if present := ab.Contains("k"); present != expectedPresent {
+			t.Errorf("policy %d: Join(r1, r2).Contains(k) = %v, expected %v", policy, present, expectedPresent)
+		}
+		if present := ba.Contains("k"); present != expectedPresent {
+			t.Errorf("policy %d: Join(r2, r1).Contains(k) = %v, expected %v", policy, present, expectedPresent)
+		}
+
+		// An add made after observing the removal brings the key back under either policy.
+		ab.Add("r1", "k", 3)
+		if !ab.Contains("k") {
+			t.Errorf("policy %d: Contains(k) after later add = false, expected true", policy)
+		}
+	}
+	testPolicy(AddWins, true)
+	testPolicy(RemoveWins, false)
+}
@@ -0,0 +1,69 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// A field tagged `crdt:"top=Value"` has a terminal value, Value, that dominates all others:
+// once any replica sets the field to Value, every merge keeps it, whatever the order of merges,
+// like a failure that no later success can undo. Other values merge as usual, including by an enum's
+// ranks if the field also has an enum tag. Value is parsed according to the field's kind,
+// e.g. `crdt:"top=failed"` for a string or `crdt:"top=-1"` for an integer, and can't be the zero value.
+// As with other tags, the tag of a map field applies to its values.
+
+// parseTop parses s as a value of the ordered type t.
+func parseTop(t reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	var err error
+	switch {
+	case t.Kind() == reflect.String:
+		v.SetString(s)
+	case t.Kind() == reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		v.SetBool(b)
+	case v.CanInt():
+		var n int64
+		n, err = strconv.ParseInt(s, 0, t.Bits())
+		v.SetInt(n)
+	case v.CanUint():
+		var n uint64
+		n, err = strconv.ParseUint(s, 0, t.Bits())
+		v.SetUint(n)
+	case v.CanFloat():
+		var f float64
+		f, err = strconv.ParseFloat(s, t.Bits())
+		v.SetFloat(f)
+	default:
+		err = fmt.Errorf("values of type %s can't have a top value", t)
+	}
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("top value %q: %w", s, err)
+	}
+	if v.IsZero() {
+		return reflect.Value{}, fmt.Errorf("top value %q is the zero value, which is the bottom value", s)
+	}
+	return v, nil
+}
+
+// mergeTop merges b into a, of an ordered kind, where top is the tag's terminal value.
+// It returns true if the value of a was modified.
+func (m *merger) mergeTop(top string, a, b reflect.Value) bool {
+	topValue, err := parseTop(a.Type(), top)
+	if err != nil {
+		panic(mergeErrorf("%v", err))
+	}
+	switch {
+	case a.Equal(topValue):
+		return false
+	case b.Equal(topValue):
+		a.Set(b)
+		return true
+	}
+	if name, ok := m.tag["enum"]; ok && a.Kind() == reflect.String {
+		return m.mergeEnum(name, a, b)
+	}
+	return m.mergeOrdered(a, b)
+}
@@ -0,0 +1,62 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeTop(t *testing.T) {
+	type stage struct {
+		Status string         `crdt:"top=failed"`
+		Code   int            `crdt:"top=-1"`
+		Steps  map[string]int `crdt:"top=-1"`
+	}
+	states := []stage{
+		{Status: "running", Code: 2, Steps: map[string]int{"a": 1}},
+		{Status: "failed", Code: 5, Steps: map[string]int{"a": -1}},
+		{Status: "succeeded", Code: -1, Steps: map[string]int{"a": 3, "b": 2}},
+		{Status: "zzz", Code: 9, Steps: map[string]int{"b": 4}},
+	}
+	expected := stage{Status: "failed", Code: -1, Steps: map[string]int{"a": -1, "b": 4}}
+	orders := [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}, {1, 3, 0, 2}, {2, 0, 3, 1}}
+	for _, order := range orders {
+		var joined stage
+		for _, i := range order {
+			joined = Join(joined, states[i]).(stage)
+		}
+		if !reflect.DeepEqual(joined, expected) {
+			t.Errorf("joining in order %v gave %+v, expected %+v", order, joined, expected)
+		}
+	}
+	if joined := Join(states[0], states[3]).(stage); joined.Status != "zzz" || joined.Code != 9 {
+		t.Errorf("values other than the top merged to %+v", joined)
+	}
+}
+
+func TestMergeTopEnum(t *testing.T) {
+	RegisterEnum("stage", "queued", "running", "done")
+	type job struct {
+		Stage string `crdt:"enum=stage,top=cancelled"`
+	}
+	if joined := Join(job{"done"}, job{"cancelled"}).(job); joined.Stage != "cancelled" {
+		t.Errorf("Join gave %q, expected cancelled", joined.Stage)
+	}
+	if joined := Join(job{"running"}, job{"queued"}).(job); joined.Stage != "running" {
+		t.Errorf("Join gave %q, expected running", joined.Stage)
+	}
+}
+
+func TestValidateTop(t *testing.T) {
+	type bad struct {
+		Count int `crdt:"top=many"`
+	}
+	if err := Validate(reflect.TypeOf(bad{})); err == nil {
+		t.Errorf("Validate accepted an unparseable top value")
+	}
+	type zero struct {
+		Done bool `crdt:"top=false"`
+	}
+	if err := Validate(reflect.TypeOf(zero{})); err == nil {
+		t.Errorf("Validate accepted a zero top value")
+	}
+}
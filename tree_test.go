@@ -0,0 +1,104 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// treeNode is a filesystem-like tree node.
+type treeNode struct {
+	Size     int
+	Owner    string
+	Children map[string]*treeNode
+}
+
+func TestMergeTree(t *testing.T) {
+	newA := func() *treeNode {
+		return &treeNode{Size: 1, Children: map[string]*treeNode{
+			"usr": {Size: 2, Children: map[string]*treeNode{
+				"bin": {Size: 5, Owner: "root"},
+			}},
+			"home": {Size: 1},
+		}}
+	}
+	newB := func() *treeNode {
+		return &treeNode{Size: 3, Children: map[string]*treeNode{
+			"usr": {Size: 1, Owner: "root", Children: map[string]*treeNode{
+				"bin": {Size: 4, Owner: "admin"},
+				"lib": {Size: 7},
+			}},
+			"etc": {Size: 2},
+		}}
+	}
+	expected := &treeNode{Size: 3, Children: map[string]*treeNode{
+		"usr": {Size: 2, Owner: "root", Children: map[string]*treeNode{
+			"bin": {Size: 5, Owner: "root"},
+			"lib": {Size: 7},
+		}},
+		"home": {Size: 1},
+		"etc":  {Size: 2},
+	}}
+	ab := Join(newA(), newB()).(*treeNode)
+	ba := Join(newB(), newA()).(*treeNode)
+	if !reflect.DeepEqual(ab, expected) || !reflect.DeepEqual(ba, expected) {
+		t.Errorf("Join(a, b) = %+v, Join(b, a) = %+v, expected %+v", ab, ba, expected)
+	}
+
+	a, b := newA(), newB()
+	Merge(&a, b)
+	if !reflect.DeepEqual(a, expected) {
+		t.Errorf("Merge(a, b) = %+v, expected %+v", a, expected)
+	}
+	a.Children["etc"].Size = 9
+	a.Children["usr"].Children["lib"].Size = 9
+	if !reflect.DeepEqual(b, newB()) {
+		t.Errorf("modifying the merged tree modified b: %+v", b)
+	}
+}
+
+func TestNormalizeCyclic(t *testing.T) {
+	newCycle := func() *treeNode {
+		root := &treeNode{Size: 1, Children: map[string]*treeNode{"empty": {}}}
+		root.Children[".."] = root
+		return root
+	}
+	a, b := newCycle(), newCycle()
+	n := Normalize(a).(*treeNode)
+	if n == a || n.Children[".."] != n {
+		t.Errorf("Normalize of a cyclic tree didn't preserve its cycle")
+	}
+	if !Equal(a, b) {
+		t.Errorf("Equal(a, b) = false for equal cyclic trees")
+	}
+	if Fingerprint(a) != Fingerprint(b) {
+		t.Errorf("equal cyclic trees have different fingerprints")
+	}
+	b.Size = 2
+	if Equal(a, b) || Fingerprint(a) == Fingerprint(b) {
+		t.Errorf("different cyclic trees are Equal or have the same fingerprint")
+	}
+	if Compact(&a) || len(a.Children) != 2 || a.Children[".."] != a {
+		t.Errorf("Compact of a cyclic tree gave %+v", a)
+	}
+}
+
+func TestMergeCyclic(t *testing.T) {
+	newCycle := func(size int) *treeNode {
+		root := &treeNode{Size: size, Children: map[string]*treeNode{}}
+		root.Children[".."] = root
+		return root
+	}
+	a, b := newCycle(1), newCycle(2)
+	Merge(&a, b)
+	if a.Size != 2 || a.Children[".."] != a {
+		t.Errorf("merging cyclic trees gave %+v", a)
+	}
+	c := Clone(b).(*treeNode)
+	if c == b || c.Children[".."] != c {
+		t.Errorf("Clone of a cyclic tree didn't preserve its cycle")
+	}
+	joined := Join(newCycle(3), newCycle(1)).(*treeNode)
+	if joined.Size != 3 {
+		t.Errorf("joining cyclic trees gave size %d, expected 3", joined.Size)
+	}
+}
@@ -0,0 +1,35 @@
+package crdt
+
+import "reflect"
+
+// A map[K]bool field tagged `crdt:"2pset"` is a two-phase set: a key mapped to true is present,
+// and a key mapped to false has been removed. Removal wins: merging a key that is true on one side
+// and false on the other leaves it false. The limitation of a two-phase set is that removal is permanent:
+// a removed key can never be re-added, since a re-add can't be told apart from the add it removed.
+// Removed keys must stay in the map as false, so Normalize and Compact keep them.
+
+// mergeTwoPhase merges the two-phase set b into the two-phase set a.
+// It returns true if the value of a was modified.
+func (m *merger) mergeTwoPhase(a, b reflect.Value) bool {
+	if a.Type().Elem().Kind() != reflect.Bool {
+		panic(mergeErrorf("2pset values of type %s are not bools", a.Type().Elem()))
+	}
+	if a.IsNil() && !b.IsNil() {
+		a.Set(reflect.MakeMap(a.Type()))
+	}
+	var changed bool
+	iter := b.MapRange()
+	for iter.Next() {
+		key, bValue := iter.Key(), iter.Value()
+		aValue := a.MapIndex(key)
+		m.path.pushKey(key)
+		keyChanged := !aValue.IsValid() || aValue.Bool() && !bValue.Bool()
+		if keyChanged {
+			a.SetMapIndex(key, bValue)
+			changed = true
+		}
+		m.decide(keyChanged, a.MapIndex(key))
+		m.path.pop()
+	}
+	return changed
+}
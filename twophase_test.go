@@ -0,0 +1,32 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type twoPhaseState struct {
+	Members map[string]bool `crdt:"2pset"`
+}
+
+func TestMergeTwoPhase(t *testing.T) {
+	a := twoPhaseState{map[string]bool{"alice": true, "bob": true}}
+	b := twoPhaseState{map[string]bool{"alice": false, "carol": true}}
+	expected := twoPhaseState{map[string]bool{"alice": false, "bob": true, "carol": true}}
+	ab, ba := Join(a, b), Join(b, a)
+	if !reflect.DeepEqual(ab, expected) || !reflect.DeepEqual(ba, expected) {
+		t.Errorf("Join(a, b) = %v, Join(b, a) = %v, expected %v", ab, ba, expected)
+	}
+
+	// The removal persists against a replica that still has alice, and through Normalize and Compact.
+	merged := ab.(twoPhaseState)
+	if Merge(&merged, a) || merged.Members["alice"] {
+		t.Errorf("merging a stale add changed the set: %v", merged)
+	}
+	if normalized := Normalize(merged).(twoPhaseState); !reflect.DeepEqual(normalized, expected) {
+		t.Errorf("Normalize dropped a removal: %v", normalized)
+	}
+	if Compact(&merged); !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Compact dropped a removal: %v", merged)
+	}
+}
@@ -0,0 +1,46 @@
+package crdt
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// MergeUnsafeValue sets a to the least upper bound of (a, b), like Merge, but also merges
+// unexported struct fields, which Merge refuses to. It is meant for merging a package's own types
+// from within that package, where their unexported fields are legitimately the package's to write.
+// a must be addressable, and a and b must be of the same type. It panics with a *MergeError
+// if some part of the values can't be merged.
+//
+// MergeUnsafeValue uses package unsafe to write fields that reflect would otherwise refuse to,
+// so it bypasses the protection unexported fields give to the invariants of other packages' types,
+// like the internal state of a sync.Mutex. Only use it on values whose every unexported field,
+// at any depth, is safe to overwrite with a merged value.
+func MergeUnsafeValue(a, b reflect.Value) bool {
+	if !a.CanAddr() {
+		panic("a must be addressable")
+	}
+	if a.Type() != b.Type() {
+		panic("a and b must be the same type")
+	}
+	m := newMerger([]Option{func(c *config) {
+		c.unexported = true
+	}})
+	changed, err := m.run(exposeField(a), b)
+	if err != nil {
+		panic(err)
+	}
+	return changed
+}
+
+// exposeField returns a Value for the same memory as the addressable v, without the restrictions
+// reflect places on values read through unexported fields.
+func exposeField(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// addressable returns an addressable copy of v.
+func addressable(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
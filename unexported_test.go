@@ -0,0 +1,39 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type session struct {
+	user     string
+	visits   int
+	pages    map[string]int
+	Location *location
+}
+
+type location struct {
+	city string
+}
+
+func TestMergeUnsafeValue(t *testing.T) {
+	a := session{user: "alice", visits: 2, pages: map[string]int{"home": 1}}
+	b := session{user: "bob", visits: 1, pages: map[string]int{"home": 3, "about": 1}, Location: &location{"Paris"}}
+	if !MergeUnsafeValue(reflect.ValueOf(&a).Elem(), reflect.ValueOf(b)) {
+		t.Errorf("MergeUnsafeValue reported no change")
+	}
+	expected := session{user: "bob", visits: 2, pages: map[string]int{"home": 3, "about": 1}, Location: &location{"Paris"}}
+	if !reflect.DeepEqual(a, expected) {
+		t.Errorf("MergeUnsafeValue gave %+v, expected %+v", a, expected)
+	}
+	if a.Location == b.Location {
+		t.Errorf("MergeUnsafeValue shared b's storage")
+	}
+	if MergeUnsafeValue(reflect.ValueOf(&a).Elem(), reflect.ValueOf(b)) {
+		t.Errorf("merging b again reported a change")
+	}
+
+	if _, err := MergeWith(&a, b); err == nil {
+		t.Errorf("MergeWith merged unexported fields")
+	}
+}
@@ -0,0 +1,75 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A struct-typed field tagged `crdt:"union,discriminator=Kind"` holds a tagged union: a struct whose
+// Kind field says which of its other fields, the variant's payload, is in use. Merging two values
+// of the same variant merges them fieldwise as usual, but merging values of different variants
+// takes the whole of one of them, so that the result never mixes the payloads of two variants.
+// The value whose discriminator is greater wins or, with `crdt:"union,discriminator=Kind,by=Updated"`,
+// the value whose Updated field is greater, falling back to the discriminator if they are equal.
+// With by, values of the same variant are only merged fieldwise if their Updated fields are equal too;
+// otherwise the newer one wins whole, so that the winner is always decided by (Updated, Kind) alone.
+// The zero struct is the bottom value. As with other tags, the tag of a map field applies to its values.
+
+// unionFields returns the indexes of the discriminator field and the by field, or -1 if there is none,
+// of the struct type t tagged with the union options tag.
+func unionFields(t reflect.Type, tag tagOptions) (discriminator, by int, err error) {
+	name := tag["discriminator"]
+	if name == "" {
+		return 0, 0, fmt.Errorf("union %s has no discriminator", t)
+	}
+	field, ok := t.FieldByName(name)
+	if !ok || len(field.Index) != 1 {
+		return 0, 0, fmt.Errorf("union discriminator %s does not exist", name)
+	}
+	discriminator, by = field.Index[0], -1
+	if name := tag["by"]; name != "" {
+		field, ok := t.FieldByName(name)
+		if !ok || len(field.Index) != 1 {
+			return 0, 0, fmt.Errorf("union field %s does not exist", name)
+		}
+		by = field.Index[0]
+	}
+	return discriminator, by, nil
+}
+
+// mergeUnion merges the tagged union b into the tagged union a, which are structs.
+// It returns true if the value of a was modified.
+func (m *merger) mergeUnion(a, b reflect.Value) bool {
+	discriminator, by, err := unionFields(a.Type(), m.tag)
+	if err != nil {
+		panic(mergeErrorf("%v", err))
+	}
+	if isZero(b) {
+		m.decide(false, a)
+		return false
+	}
+	if !isZero(a) && compare(a.Field(discriminator), b.Field(discriminator)) == 0 &&
+		(by < 0 || compare(a.Field(by), b.Field(by)) == 0) {
+		tag := m.tag
+		changed := m.mergeFields(a, b)
+		m.tag = tag
+		return changed
+	}
+	order := -1
+	if !isZero(a) {
+		order = 0
+		if by >= 0 {
+			order = compare(a.Field(by), b.Field(by))
+		}
+		if order == 0 {
+			order = compare(a.Field(discriminator), b.Field(discriminator))
+		}
+	}
+	if order < 0 {
+		a.Set(deepCopy(b))
+		m.decide(true, a)
+		return true
+	}
+	m.decide(false, a)
+	return false
+}
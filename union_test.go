@@ -0,0 +1,107 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type circle struct{ Radius int }
+
+type rect struct{ Width, Height int }
+
+type shape struct {
+	Kind    string
+	Updated int64
+	Circle  *circle
+	Rect    *rect
+}
+
+func TestMergeUnion(t *testing.T) {
+	type drawing struct {
+		Shape  shape            `crdt:"union,discriminator=Kind"`
+		Latest shape            `crdt:"union,discriminator=Kind,by=Updated"`
+		Shapes map[string]shape `crdt:"union,discriminator=Kind"`
+	}
+	circleShape := shape{Kind: "circle", Updated: 2, Circle: &circle{3}}
+	rectShape := shape{Kind: "rect", Updated: 1, Rect: &rect{4, 5}}
+	a := drawing{Shape: circleShape, Latest: circleShape, Shapes: map[string]shape{"x": circleShape, "y": rectShape}}
+	b := drawing{Shape: rectShape, Latest: rectShape, Shapes: map[string]shape{"x": rectShape, "y": circleShape}}
+	ab, ba := Join(a, b).(drawing), Join(b, a).(drawing)
+	if !reflect.DeepEqual(ab, ba) {
+		t.Errorf("Join(a, b) = %+v, but Join(b, a) = %+v", ab, ba)
+	}
+	expected := drawing{Shape: rectShape, Latest: circleShape, Shapes: map[string]shape{"x": rectShape, "y": rectShape}}
+	if !reflect.DeepEqual(ab, expected) {
+		t.Errorf("Join(a, b) = %+v, expected %+v", ab, expected)
+	}
+	if ab.Shape.Circle != nil || ab.Latest.Rect != nil {
+		t.Errorf("Join(a, b) mixed the payloads of two variants: %+v", ab)
+	}
+
+	bigger := shape{Kind: "circle", Updated: 2, Circle: &circle{5}}
+	joined := Join(drawing{Latest: circleShape}, drawing{Latest: bigger}).(drawing)
+	if expected := (shape{Kind: "circle", Updated: 2, Circle: &circle{5}}); !reflect.DeepEqual(joined.Latest, expected) {
+		t.Errorf("Join of the same variant and time gave %+v, expected the fieldwise merge %+v", joined.Latest, expected)
+	}
+	older := shape{Kind: "circle", Updated: 1, Circle: &circle{5}}
+	joined = Join(drawing{Latest: circleShape}, drawing{Latest: older}).(drawing)
+	if !reflect.DeepEqual(joined.Latest, circleShape) {
+		t.Errorf("Join of the same variant at different times gave %+v, expected the newer %+v", joined.Latest, circleShape)
+	}
+}
+
+func TestMergeUnionAssociative(t *testing.T) {
+	type drawing struct {
+		Latest shape `crdt:"union,discriminator=Kind,by=Updated"`
+	}
+	values := []drawing{
+		{shape{Kind: "a", Updated: 1, Circle: &circle{9}}},
+		{shape{Kind: "e", Updated: 2}},
+		{shape{Kind: "i", Updated: 1, Circle: &circle{3}}},
+		{shape{Kind: "a", Updated: 2, Rect: &rect{1, 1}}},
+	}
+	for _, a := range values {
+		for _, b := range values {
+			for _, c := range values {
+				left, right := Join(Join(a, b), c), Join(a, Join(b, c))
+				if !reflect.DeepEqual(left, right) {
+					t.Errorf("(%+v ⊔ %+v) ⊔ %+v = %+v, but %+v ⊔ (%+v ⊔ %+v) = %+v", a, b, c, left, a, b, c, right)
+				}
+			}
+		}
+	}
+}
+
+func TestDeltaForUnion(t *testing.T) {
+	type variant struct {
+		Kind    string
+		Value   int
+		Updated int
+	}
+	type state struct {
+		V variant `crdt:"union,discriminator=Kind,by=Updated"`
+	}
+	local, remote := state{variant{"a", 7, 1}}, state{variant{"a", 5, 1}}
+	d := DeltaFor(local, remote).(state)
+	if d != local {
+		t.Errorf("DeltaFor(%v, %v) = %v, expected the whole union %v", local, remote, d, local)
+	}
+	if got := Join(remote, d); got != local {
+		t.Errorf("Join(remote, DeltaFor(local, remote)) = %v, expected %v", got, local)
+	}
+	if d := DeltaFor(local, local).(state); d != (state{}) {
+		t.Errorf("DeltaFor(local, local) = %v, expected the zero value", d)
+	}
+}
+
+func TestValidateUnion(t *testing.T) {
+	type bad struct {
+		Shape shape `crdt:"union,discriminator=Type"`
+	}
+	if err := Validate(reflect.TypeOf(bad{})); err == nil {
+		t.Errorf("Validate accepted a union with a missing discriminator")
+	}
+	if _, err := JoinWith(bad{}, bad{Shape: shape{Kind: "circle"}}); err == nil {
+		t.Errorf("JoinWith merged a union with a missing discriminator")
+	}
+}
@@ -0,0 +1,15 @@
+package crdt
+
+import (
+	"net/url"
+	"reflect"
+)
+
+// url.Values is merged keywise, with each key's values merged as a set, as if tagged `crdt:"set"`:
+// the result holds the sorted, deduplicated union of both sides' values for each key.
+// Other map[string][]string types, such as HTTP headers, merge the same way when tagged `crdt:"set"`.
+func init() {
+	registerValue(reflect.TypeOf(url.Values(nil)), func(a, b reflect.Value) bool {
+		return (&merger{tag: tagOptions{"set": ""}}).mergeMap(a, b)
+	})
+}
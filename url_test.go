@@ -0,0 +1,32 @@
+package crdt
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMergeURLValues(t *testing.T) {
+	a := url.Values{"q": {"go", "crdt"}, "page": {"1"}}
+	b := url.Values{"q": {"crdt", "merge", "go"}, "lang": {"en"}}
+	expected := url.Values{"q": {"crdt", "go", "merge"}, "page": {"1"}, "lang": {"en"}}
+	ab, ba := Join(a, b), Join(b, a)
+	if !reflect.DeepEqual(ab, expected) || !reflect.DeepEqual(ba, expected) {
+		t.Errorf("Join(a, b) = %v, Join(b, a) = %v, expected %v", ab, ba, expected)
+	}
+	if len(a["q"]) != 2 || a["q"][0] != "go" {
+		t.Errorf("Join modified a: %v", a)
+	}
+}
+
+func TestMergeHeaderSet(t *testing.T) {
+	type request struct {
+		Header map[string][]string `crdt:"set"`
+	}
+	a := request{map[string][]string{"Accept": {"text/html", "application/json"}}}
+	b := request{map[string][]string{"Accept": {"application/json", "text/plain"}, "X-Id": {"1"}}}
+	expected := request{map[string][]string{"Accept": {"application/json", "text/html", "text/plain"}, "X-Id": {"1"}}}
+	if joined := Join(a, b); !reflect.DeepEqual(joined, expected) {
+		t.Errorf("Join(a, b) = %v, expected %v", joined, expected)
+	}
+}
@@ -0,0 +1,232 @@
+package crdt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Validate checks that values of type t can be merged, returning an error listing every location
+// in the type that can't be, or nil if there are none. Each error in the list is a *MergeError
+// whose Path locates the problem in the format used by WithProvenance, with "[*]" standing for
+// any map key and "[]" for any slice element; the errors can be retrieved with errors.As or,
+// as a list, through the Unwrap() []error method of the returned error.
+//
+// Validate follows the rules that Merge does, with no options: types with a Merger, registered MergeFunc,
+// or Comparable are mergeable as they are, and everything else is checked structurally.
+func Validate(t reflect.Type) error {
+	v := validator{seen: make(map[reflect.Type]bool)}
+	v.validate(t, nil, "")
+	return errors.Join(v.errs...)
+}
+
+// CanMerge returns true if values of type t can be merged, that is, if Validate(t) returns nil.
+func CanMerge(t reflect.Type) bool {
+	return Validate(t) == nil
+}
+
+// IsMergeable returns true if v can be merged with other values of its type. It checks v's type
+// as Validate does, and also the dynamic types of the interface values within v, which Validate
+// can't see, so it rejects values that CanMerge(reflect.TypeOf(v)) accepts but that would make
+// a merge fail, like an interface{} field holding a func.
+func IsMergeable(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	val := reflect.ValueOf(v)
+	return CanMerge(val.Type()) && dynamicMergeable(val, nil, make(map[copiedPointer]bool))
+}
+
+// dynamicMergeable returns true if the dynamic types of the interface values within v, whose
+// `crdt` tag options are tag, can be merged. seen holds the pointers already checked, to stop at cycles.
+func dynamicMergeable(v reflect.Value, tag tagOptions, seen map[copiedPointer]bool) bool {
+	t := v.Type()
+	ptr := reflect.PointerTo(t)
+	var handled map[string]bool
+	if ptr.Implements(partialMergerType) && t.Kind() == reflect.Struct {
+		handled = handledFields(reflect.New(t).Interface().(PartialMerger))
+	} else if ptr.Implements(mergerType) || registered(t) != nil || ptr.Implements(comparableType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+		elem := v.Elem()
+		checker := validator{seen: make(map[reflect.Type]bool)}
+		checker.validate(elem.Type(), tag, "")
+		return checker.errs == nil && dynamicMergeable(elem, tag, seen)
+	case reflect.Struct:
+		if isAtomicPointer(t) {
+			ptr := atomicLoad(v)
+			return ptr.IsNil() || dynamicMergeable(ptr.Elem(), tag, seen)
+		}
+		tags := fieldTags(t)
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath == "" && !handled[t.Field(i).Name] && !dynamicMergeable(v.Field(i), tags[i], seen) {
+				return false
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if !dynamicMergeable(iter.Value(), tag, seen) {
+				return false
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if !dynamicMergeable(v.Index(i), tag, seen) {
+				return false
+			}
+		}
+	case reflect.Ptr:
+		key := copiedPointer{t, v.Pointer()}
+		if !v.IsNil() && !seen[key] {
+			seen[key] = true
+			return dynamicMergeable(v.Elem(), tag, seen)
+		}
+	}
+	return true
+}
+
+// validator accumulates the problems found by Validate.
+type validator struct {
+	errs []error
+	// seen holds the struct types being validated, so that recursive types are only checked once.
+	seen map[reflect.Type]bool
+}
+
+func (v *validator) errorf(path string, format string, args ...interface{}) {
+	v.errs = append(v.errs, &MergeError{Path: path, Err: fmt.Errorf(format, args...)})
+}
+
+// validate checks the type t at path, whose `crdt` tag options are tag.
+func (v *validator) validate(t reflect.Type, tag tagOptions, path string) {
+	if tag.has("bitset") && t != bigIntType && t.Kind() != reflect.Map && t.Kind() != reflect.Slice {
+		v.errorf(path, "bitset value of type %s is not a *big.Int", t)
+		return
+	}
+	ptr := reflect.PointerTo(t)
+	var handled map[string]bool
+	if ptr.Implements(partialMergerType) && t.Kind() == reflect.Struct {
+		// The fields the Merge method doesn't handle are merged fieldwise, and checked below.
+		handled = handledFields(reflect.New(t).Interface().(PartialMerger))
+	} else if ptr.Implements(mergerType) || registered(t) != nil || ptr.Implements(comparableType) {
+		return
+	}
+	if isAtomicPointer(t) {
+		v.validate(atomicElem(t), tag, path)
+		return
+	}
+	if isSQLNull(t) {
+		v.validate(t.Field(0).Type, tag, path+".V")
+		return
+	}
+	if top, ok := tag["top"]; ok && isOrdered(t.Kind()) {
+		if _, err := parseTop(t, top); err != nil {
+			v.errorf(path, "%v", err)
+		}
+	}
+	if name, ok := tag["enum"]; ok && t.Kind() == reflect.String {
+		if _, ok := enums.Load(name); !ok {
+			v.errorf(path, "enum %s is not registered", name)
+		}
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if v.seen[t] {
+			return
+		}
+		v.seen[t] = true
+		defer delete(v.seen, t)
+		if tag.has("fixedpoint") {
+			if _, _, err := fixedPointFields(t, tag); err != nil {
+				v.errorf(path, "%v", err)
+			}
+		}
+		if tag.has("union") {
+			if _, _, err := unionFields(t, tag); err != nil {
+				v.errorf(path, "%v", err)
+			}
+		}
+		tags := fieldTags(t)
+		if _, _, err := sinceVersion(reflect.New(t).Elem(), tags); err != nil {
+			v.errorf(path, "%v", err)
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			if handled[field.Name] {
+				continue
+			}
+			if field.PkgPath != "" {
+				v.errorf(path, "field %s (%s) is unexported", field.Name, field.PkgPath)
+				continue
+			}
+			if sibling := tags[i]["lww"]; sibling != "" {
+				if _, ok := t.FieldByName(sibling); !ok {
+					v.errorf(path, "field %s: lww sibling %s does not exist", field.Name, sibling)
+				}
+			}
+			if tags[i].has("sum") {
+				if _, err := sumCounts(t, i, tags[i]); err != nil {
+					v.errorf(path, "%v", err)
+				}
+				continue
+			}
+			v.validate(field.Type, tags[i], fieldPath)
+		}
+	case reflect.Map:
+		if tag.has("2pset") {
+			if t.Elem().Kind() != reflect.Bool {
+				v.errorf(path, "2pset values of type %s are not bools", t.Elem())
+			}
+			return
+		}
+		v.validate(t.Elem(), tag, path+"[*]")
+	case reflect.Slice:
+		switch {
+		case tag["set"] != "":
+			if _, err := setKey(t.Elem(), tag["set"]); err != nil {
+				v.errorf(path+"[]", "%v", err)
+			} else {
+				v.validate(t.Elem(), nil, path+"[]")
+			}
+		case tag.has("set"):
+			if !isOrdered(t.Elem().Kind()) {
+				v.errorf(path+"[]", "set elements of type %s have no total ordering", t.Elem())
+			}
+		case tag.has("sortedmax"):
+			if !isOrdered(t.Elem().Kind()) {
+				v.errorf(path+"[]", "sortedmax elements of type %s have no total ordering", t.Elem())
+			}
+		case tag.has("log"):
+			if _, _, err := logOrigin(t.Elem()); err != nil {
+				v.errorf(path+"[]", "%v", err)
+			}
+		case isText(t):
+		default:
+			v.validate(t.Elem(), tag, path+"[]")
+		}
+	case reflect.Ptr:
+		v.validate(t.Elem(), tag, path)
+	case reflect.Interface:
+		// Interface values are checked by their dynamic types when merged; see IsMergeable.
+	case reflect.Chan:
+		if tag["chan"] != "" {
+			v.errorf(path, "channels are only merged with WithChannels")
+		} else {
+			v.errorf(path, "don't know how to merge type %s", t)
+		}
+	default:
+		if !isOrdered(t.Kind()) {
+			v.errorf(path, "don't know how to merge type %s", t)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package crdt
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type inner struct {
+		OK  int
+		Fn  func()
+		Arr [2]int
+	}
+	type node struct {
+		Value int
+		Next  *node
+	}
+	type schema struct {
+		Name     string
+		Counts   map[string]int
+		Inners   map[string]*inner
+		List     []func()
+		Tags     []string `crdt:"set"`
+		Sets     []inner  `crdt:"set"`
+		Status   string   `crdt:"enum=nosuchenum"`
+		Bytes    []byte
+		Value    string `crdt:"lww=Missing"`
+		List2    *node
+		Merged   decreasingInt
+		unexport int
+	}
+	err := Validate(reflect.TypeOf(schema{}))
+	if err == nil {
+		t.Fatalf("Validate returned nil, expected errors")
+	}
+	var found []string
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var mergeErr *MergeError
+		if !errors.As(err, &mergeErr) {
+			t.Fatalf("Validate returned %T, expected *MergeError", err)
+		}
+		found = append(found, mergeErr.Path)
+	}
+	sort.Strings(found)
+	expected := []string{"", "", "Inners[*].Arr", "Inners[*].Fn", "List[]", "Sets[]", "Status"}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Validate found errors at %q, expected %q\n%v", found, expected, err)
+	}
+	if CanMerge(reflect.TypeOf(schema{})) {
+		t.Errorf("CanMerge(schema) = true, expected false")
+	}
+}
+
+func TestValidateOK(t *testing.T) {
+	for _, value := range []interface{}{
+		0,
+		"",
+		map[string]map[string]int{},
+		deltaState{},
+		ticket{},
+		&MaxRegister[int]{},
+		TombstoneMap[string, int]{},
+	} {
+		if err := Validate(reflect.TypeOf(value)); err != nil {
+			t.Errorf("Validate(%T) = %v, expected nil", value, err)
+		}
+	}
+}
+
+func TestIsMergeable(t *testing.T) {
+	type holder struct {
+		Value  interface{}
+		Values map[string]interface{}
+	}
+	if !CanMerge(reflect.TypeOf(holder{})) {
+		t.Errorf("CanMerge rejected a type with interface fields")
+	}
+	for _, test := range []struct {
+		v        interface{}
+		expected bool
+	}{
+		{holder{}, true},
+		{holder{Value: 1}, true},
+		{holder{Value: map[string]int{"x": 1}}, true},
+		{holder{Value: func() {}}, false},
+		{holder{Value: struct{ F func() }{}}, false},
+		{holder{Values: map[string]interface{}{"a": 1, "b": make(chan int)}}, false},
+		{holder{Value: holder{Value: func() {}}}, false},
+		{func() {}, false},
+		{nil, false},
+	} {
+		if got := IsMergeable(test.v); got != test.expected {
+			t.Errorf("IsMergeable(%#v) = %v, expected %v", test.v, got, test.expected)
+		}
+	}
+}
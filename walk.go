@@ -0,0 +1,185 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// isLeaf returns true if values of type t are merged as a whole, rather than by recursing into them.
+func isLeaf(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(mergerType) || registered(t) != nil ||
+		reflect.PointerTo(t).Implements(comparableType) {
+		return true
+	}
+	return t.Kind() != reflect.Struct && t.Kind() != reflect.Map
+}
+
+// sortKeys sorts map keys into a deterministic order: by the order given by WithKeyOrder, if any;
+// otherwise ordered keys by their ordering, and others by their fingerprints,
+// with ties broken by their formatted representation.
+func (c *config) sortKeys(keys []reflect.Value) {
+	switch {
+	case len(keys) == 0:
+	case c.keyOrder != nil:
+		sort.Slice(keys, func(i, j int) bool {
+			return c.keyOrder(keys[i].Interface(), keys[j].Interface())
+		})
+	case isOrdered(keys[0].Kind()):
+		sort.Slice(keys, func(i, j int) bool {
+			return less(keys[i], keys[j])
+		})
+	default:
+		fingerprints := make(map[int]uint64, len(keys))
+		for i, key := range keys {
+			fingerprints[i] = Fingerprint(key.Interface())
+		}
+		indexes := make([]int, len(keys))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		sort.Slice(indexes, func(i, j int) bool {
+			x, y := indexes[i], indexes[j]
+			if fingerprints[x] != fingerprints[y] {
+				return fingerprints[x] < fingerprints[y]
+			}
+			return fmt.Sprintf("%#v", keys[x].Interface()) < fmt.Sprintf("%#v", keys[y].Interface())
+		})
+		sorted := make([]reflect.Value, len(keys))
+		for i, index := range indexes {
+			sorted[i] = keys[index]
+		}
+		copy(keys, sorted)
+	}
+}
+
+// WithKeyOrder makes Walk, Diff, and Fingerprint visit map keys in the order given by less,
+// which must be a strict total order on the keys of every map in the value,
+// instead of the default order: the keys' natural ordering if they have one, or else their fingerprints.
+// This gives control over the output order for key types with no natural ordering, such as structs.
+func WithKeyOrder(less func(a, b interface{}) bool) Option {
+	return func(c *config) {
+		c.keyOrder = less
+	}
+}
+
+// walker carries the options and current path of a Walk or Diff through its recursion.
+type walker struct {
+	config
+	path path
+}
+
+// leaf returns the value of the leaf v to report at the current path.
+func (w *walker) leaf(v reflect.Value) interface{} {
+	value := v.Interface()
+	if w.redact != nil {
+		value = w.redact(w.path.String(), value)
+	}
+	return value
+}
+
+func (w *walker) walk(v reflect.Value, fn func(path string, value interface{})) {
+	switch {
+	case isLeaf(v.Type()):
+		fn(w.path.String(), w.leaf(v))
+	case v.Kind() == reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.PkgPath == "" {
+				w.path.pushField(field.Name)
+				w.walk(v.Field(i), fn)
+				w.path.pop()
+			}
+		}
+	case v.Kind() == reflect.Map:
+		keys := v.MapKeys()
+		w.sortKeys(keys)
+		for _, key := range keys {
+			w.path.pushKey(key)
+			w.walk(v.MapIndex(key), fn)
+			w.path.pop()
+		}
+	}
+}
+
+// Walk calls fn for every leaf of a, with its path (in the format used by WithProvenance) and value.
+// Leaves are the values that merges treat as a whole: values that aren't structs or maps,
+// and values merged by a Merger, registered MergeFunc, or Comparable.
+// Struct fields are visited in order, and map keys in sorted order (see WithKeyOrder).
+func Walk(a interface{}, fn func(path string, value interface{}), opts ...Option) {
+	w := new(walker)
+	for _, opt := range opts {
+		opt(&w.config)
+	}
+	w.walk(reflect.ValueOf(a), fn)
+}
+
+// Difference is a leaf at which two values differ.
+type Difference struct {
+	// Path is the path of the leaf, in the format used by WithProvenance.
+	Path string
+	// A and B are the values of the leaf in each value. A map entry missing from one value
+	// is treated as the zero value.
+	A, B interface{}
+}
+
+func (w *walker) diff(a, b reflect.Value, diffs []Difference) []Difference {
+	switch {
+	case isLeaf(a.Type()):
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			diffs = append(diffs, Difference{w.path.String(), w.leaf(a), w.leaf(b)})
+		}
+	case a.Kind() == reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if field := a.Type().Field(i); field.PkgPath == "" {
+				w.path.pushField(field.Name)
+				diffs = w.diff(a.Field(i), b.Field(i), diffs)
+				w.path.pop()
+			}
+		}
+	case a.Kind() == reflect.Map:
+		keys := a.MapKeys()
+		for _, key := range b.MapKeys() {
+			if !a.MapIndex(key).IsValid() {
+				keys = append(keys, key)
+			}
+		}
+		w.sortKeys(keys)
+		zero := reflect.Zero(a.Type().Elem())
+		for _, key := range keys {
+			aValue, bValue := a.MapIndex(key), b.MapIndex(key)
+			if !aValue.IsValid() {
+				aValue = zero
+			}
+			if !bValue.IsValid() {
+				bValue = zero
+			}
+			w.path.pushKey(key)
+			diffs = w.diff(aValue, bValue, diffs)
+			w.path.pop()
+		}
+	}
+	return diffs
+}
+
+// Diff returns the leaves (as defined by Walk) at which a and b differ, in the order Walk would visit them.
+// a and b must be values of the same type.
+func Diff(a, b interface{}, opts ...Option) []Difference {
+	aVal := reflect.ValueOf(a)
+	bVal := reflect.ValueOf(b)
+	if aVal.Type() != bVal.Type() {
+		panic("a and b must be the same type")
+	}
+	w := new(walker)
+	for _, opt := range opts {
+		opt(&w.config)
+	}
+	return w.diff(aVal, bVal, nil)
+}
+
+// WithRedact makes Walk, Diff, and merges with WithLogger report fn(path, value) in place of each leaf's value,
+// so that sensitive values can be masked or transformed by path.
+func WithRedact(fn func(path string, value interface{}) interface{}) Option {
+	return func(c *config) {
+		c.redact = fn
+	}
+}
@@ -0,0 +1,125 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type walkUser struct {
+	Name  string
+	Token string
+	Score int
+}
+
+type walkState struct {
+	Users map[string]walkUser
+	Max   MaxRegister[int]
+}
+
+func TestWalk(t *testing.T) {
+	state := walkState{
+		Users: map[string]walkUser{"bob": {"Bob", "t2", 2}, "alice": {"Alice", "t1", 1}},
+		Max:   MaxRegister[int]{3, true},
+	}
+	var leaves []string
+	Walk(state, func(path string, value interface{}) {
+		leaves = append(leaves, fmt.Sprintf("%s=%v", path, value))
+	})
+	expected := []string{
+		"Users[alice].Name=Alice", "Users[alice].Token=t1", "Users[alice].Score=1",
+		"Users[bob].Name=Bob", "Users[bob].Token=t2", "Users[bob].Score=2",
+		"Max={3 true}",
+	}
+	if !reflect.DeepEqual(leaves, expected) {
+		t.Errorf("Walk visited %v, expected %v", leaves, expected)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := walkState{Users: map[string]walkUser{"alice": {"Alice", "t1", 1}}}
+	b := walkState{Users: map[string]walkUser{"alice": {"Alice", "t1", 2}, "bob": {"Bob", "", 0}}}
+	expected := []Difference{
+		{"Users[alice].Score", 1, 2},
+		{"Users[bob].Name", "", "Bob"},
+	}
+	if diffs := Diff(a, b); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Diff(a, b) = %v, expected %v", diffs, expected)
+	}
+	if diffs := Diff(a, a); diffs != nil {
+		t.Errorf("Diff(a, a) = %v, expected none", diffs)
+	}
+}
+
+func TestWithRedact(t *testing.T) {
+	redact := WithRedact(func(path string, value interface{}) interface{} {
+		if strings.HasSuffix(path, ".Token") {
+			return "***"
+		}
+		return value
+	})
+	a := walkState{Users: map[string]walkUser{"alice": {"Alice", "secret1", 1}}}
+	b := walkState{Users: map[string]walkUser{"alice": {"Alicia", "secret2", 1}}}
+	expected := []Difference{
+		{"Users[alice].Name", "Alice", "Alicia"},
+		{"Users[alice].Token", "***", "***"},
+	}
+	if diffs := Diff(a, b, redact); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Diff(a, b) = %v, expected %v", diffs, expected)
+	}
+	leaves := map[string]interface{}{}
+	Walk(a, func(path string, value interface{}) {
+		leaves[path] = value
+	}, redact)
+	if leaves["Users[alice].Token"] != "***" || leaves["Users[alice].Name"] != "Alice" {
+		t.Errorf("Walk reported %v", leaves)
+	}
+}
+
+type walkPoint struct {
+	X, Y int
+}
+
+func TestWithKeyOrder(t *testing.T) {
+	a := map[walkPoint]int{{1, 2}: 1, {2, 1}: 2, {0, 3}: 3}
+	b := map[walkPoint]int{{1, 2}: 4, {2, 1}: 5, {0, 3}: 6}
+	byY := WithKeyOrder(func(a, b interface{}) bool {
+		return a.(walkPoint).Y < b.(walkPoint).Y
+	})
+	var leaves []string
+	Walk(a, func(path string, value interface{}) {
+		leaves = append(leaves, fmt.Sprintf("%s=%v", path, value))
+	}, byY)
+	expected := []string{"[{2 1}]=2", "[{1 2}]=1", "[{0 3}]=3"}
+	if !reflect.DeepEqual(leaves, expected) {
+		t.Errorf("Walk visited %v, expected %v", leaves, expected)
+	}
+	var paths []string
+	for _, d := range Diff(a, b, byY) {
+		paths = append(paths, d.Path)
+	}
+	if expected := []string{"[{2 1}]", "[{1 2}]", "[{0 3}]"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Diff found %v, expected %v", paths, expected)
+	}
+}
+
+func TestWalkStructKeysStable(t *testing.T) {
+	walkOrder := func(m map[walkPoint]int) []string {
+		var leaves []string
+		Walk(m, func(path string, value interface{}) {
+			leaves = append(leaves, path)
+		})
+		return leaves
+	}
+	expected := walkOrder(map[walkPoint]int{{1, 2}: 1, {2, 1}: 2, {0, 3}: 3, {3, 0}: 4})
+	for i := 0; i < 20; i++ {
+		m := make(map[walkPoint]int)
+		for _, p := range []walkPoint{{3, 0}, {0, 3}, {2, 1}, {1, 2}} {
+			m[p] = p.X + 1
+		}
+		if order := walkOrder(m); !reflect.DeepEqual(order, expected) {
+			t.Fatalf("Walk visited %v, then %v", expected, order)
+		}
+	}
+}
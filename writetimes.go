@@ -0,0 +1,93 @@
+package crdt
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// WriteTimes records when each last-writer-wins field of a struct was last written, keyed by field name,
+// so that the struct needn't carry a timestamp field for each of them. Fields tagged `crdt:"lww=Times"`,
+// where Times is a field of type WriteTimes, are decided by their entries in Times, and Times itself
+// merges keywise to the latest time. Use Write to set such a field and record its write time together.
+type WriteTimes map[string]int64
+
+var writeTimesType = reflect.TypeOf(WriteTimes(nil))
+
+// writeTime returns the time recorded for the field name in the WriteTimes map times, or zero if none is.
+func writeTime(times, name reflect.Value) reflect.Value {
+	if t := times.MapIndex(name); t.IsValid() {
+		return t
+	}
+	return reflect.Zero(times.Type().Elem())
+}
+
+// Write sets the field of the struct pointed to by s to value, and records clock.Now() in the struct's
+// WriteTimes as the time the field was written. The field must be tagged `crdt:"lww=Times"`,
+// where Times is a field of type WriteTimes.
+func Write(s interface{}, field string, value interface{}, clock *HybridClock) {
+	v := reflect.ValueOf(s).Elem()
+	f, ok := v.Type().FieldByName(field)
+	if !ok || len(f.Index) != 1 {
+		panic(fmt.Sprintf("crdt: %s has no field %s", v.Type(), field))
+	}
+	times := v.FieldByName(fieldTags(v.Type())[f.Index[0]]["lww"])
+	if !times.IsValid() || times.Type() != writeTimesType {
+		panic(fmt.Sprintf("crdt: field %s is not tagged lww with a WriteTimes sibling", field))
+	}
+	v.Field(f.Index[0]).Set(reflect.ValueOf(value))
+	if times.IsNil() {
+		times.Set(reflect.MakeMap(writeTimesType))
+	}
+	times.SetMapIndex(reflect.ValueOf(field), reflect.ValueOf(clock.Now()))
+}
+
+// HybridClock is a hybrid logical clock, a source of timestamps for last-writer-wins fields
+// that follows physical time but never goes backwards: each timestamp it returns is greater than
+// every timestamp it has returned or observed. Replicas that Observe the write times in the states
+// they merge thus order a write after every write they have seen, even if their physical clocks
+// are skewed, which a plain physical clock can't guarantee. Timestamps are Unix times in nanoseconds,
+// advanced by one where needed to stay ahead. The zero value is a clock reading time.Now.
+// A HybridClock is safe for concurrent use.
+type HybridClock struct {
+	// Physical, if set, returns the physical time in place of time.Now, such as for testing.
+	Physical func() time.Time
+
+	mu   sync.Mutex
+	last int64
+}
+
+// Now returns a new timestamp, greater than any the clock has returned or observed.
+func (c *HybridClock) Now() int64 {
+	now := time.Now
+	if c.Physical != nil {
+		now = c.Physical
+	}
+	physical := now().UnixNano()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if physical > c.last {
+		c.last = physical
+	} else {
+		c.last++
+	}
+	return c.last
+}
+
+// Observe advances the clock past timestamp, typically one received from another replica,
+// so that the clock's later timestamps are greater than it.
+func (c *HybridClock) Observe(timestamp int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if timestamp > c.last {
+		c.last = timestamp
+	}
+}
+
+// ObserveTimes observes every write time in times.
+func (c *HybridClock) ObserveTimes(times WriteTimes) {
+	for _, t := range times {
+		c.Observe(t)
+	}
+}
@@ -0,0 +1,58 @@
+package crdt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type document struct {
+	Title string `crdt:"lww=Times"`
+	Body  string `crdt:"lww=Times"`
+	Times WriteTimes
+}
+
+func TestWriteTimes(t *testing.T) {
+	// Replica b's physical clock runs an hour behind a's.
+	start := time.Unix(1000, 0)
+	nowA := start
+	clockA := &HybridClock{Physical: func() time.Time { return nowA }}
+	clockB := &HybridClock{Physical: func() time.Time { return start.Add(-time.Hour) }}
+
+	var a, b document
+	Write(&a, "Title", "Zebra", clockA)
+	Write(&a, "Body", "first", clockA)
+	clockB.ObserveTimes(a.Times)
+	// b saw a's writes before its own, so its writes win despite its slow clock and smaller values.
+	Write(&b, "Title", "Aardvark", clockB)
+	Write(&b, "Body", "", clockB)
+
+	expected := document{Title: "Aardvark", Times: WriteTimes{"Title": a.Times["Title"] + 2, "Body": a.Times["Body"] + 2}}
+	for _, joined := range []interface{}{Join(a, b), Join(b, a)} {
+		if !reflect.DeepEqual(joined, expected) {
+			t.Errorf("Join = %+v, expected %+v", joined, expected)
+		}
+	}
+
+	// Concurrent writes are decided by the write times alone.
+	nowA = start.Add(time.Second)
+	Write(&a, "Body", "later", clockA)
+	Write(&b, "Title", "earlier", clockB)
+	joined := Join(a, b).(document)
+	if joined.Body != "later" || joined.Title != "earlier" {
+		t.Errorf("Join = %+v, expected Title earlier and Body later", joined)
+	}
+}
+
+func TestHybridClock(t *testing.T) {
+	var c HybridClock
+	first := c.Now()
+	c.Observe(first + int64(time.Hour))
+	if next := c.Now(); next <= first+int64(time.Hour) {
+		t.Errorf("Now() = %d after observing %d", next, first+int64(time.Hour))
+	}
+	stopped := &HybridClock{Physical: func() time.Time { return time.Unix(0, 5) }}
+	if x, y := stopped.Now(), stopped.Now(); x != 5 || y != 6 {
+		t.Errorf("a stopped clock returned %d, %d, expected 5, 6", x, y)
+	}
+}